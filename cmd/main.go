@@ -1,12 +1,34 @@
 package main
 
 import (
+	"BookCollect/internal/apikeys"
+	"BookCollect/internal/blind"
+	"BookCollect/internal/cache"
+	"BookCollect/internal/config"
+	"BookCollect/internal/covergen"
 	"BookCollect/internal/db"
 	"BookCollect/internal/handlers"
+	"BookCollect/internal/imaging"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/mail"
+	"BookCollect/internal/metrics"
 	mw "BookCollect/internal/middleware"
-	"log"
+	"BookCollect/internal/openapi"
+	"BookCollect/internal/scanner"
+	"BookCollect/internal/sessions"
+	"BookCollect/internal/stats"
+	"BookCollect/internal/tracing"
+	"BookCollect/internal/views"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,57 +36,164 @@ import (
 )
 
 func main() {
-	log.Println("Boot: calling db.InitDB()")
-	db.InitDB()
+	os.Exit(run())
+}
+
+// run — весь запуск и остановка сервера; код выхода возвращается, а не через
+// os.Exit, чтобы отложенные вызовы (stopSignals, cancel) успели выполниться
+func run() int {
+	configPath := flag.String("config", "", "файл настроек YAML/TOML (или CONFIG_FILE); переменные окружения важнее")
+	// docker HEALTHCHECK: в distroless-образе нет curl, проверяем сами
+	healthOnly := flag.Bool("healthcheck", false, "опросить /readyz запущенного сервера и выйти")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		// логгер ещё не настроен: формат и уровень сами берутся из конфига
+		fmt.Fprintf(os.Stderr, "config:\n%v\n", err)
+		return 1
+	}
+	if *healthOnly {
+		return healthcheck(cfg.Server.Port)
+	}
+	logging.Init(cfg.Log)
+	for _, line := range cfg.Summary() {
+		slog.Info("config: " + line)
+	}
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		slog.Error("tracing", "err", err)
+		return 1
+	}
+
+	db.InitDB(cfg.DB)
+	sessions.Init(*cfg)
+	scanner.Init(cfg.Scanner, cfg.Uploads.QuarantineDir)
+	imaging.Init(cfg.Covers)
+	covergen.Init(cfg.Covers)
+	mail.Init(cfg.Mail)
+	blind.Init(cfg.Review)
+	stats.Init(cfg.Stats)
+	apikeys.Init(cfg.API)
+	cache.Init(cfg.Cache)
+	metrics.Init(cfg.Metrics)
+	if err := views.Init(cfg.Web); err != nil {
+		slog.Error("templates", "err", err)
+		return 1
+	}
+	handlers.Configure(cfg)
+
+	// Фоновые воркеры останавливаются после того, как сервер дообслужит запросы:
+	// счётчики статистики из последних запросов ещё успеют попасть в БД
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() { defer workers.Done(); mail.RunWorker(workersCtx) }()
+	go func() { defer workers.Done(); stats.Default.Run(workersCtx) }()
+	go func() { defer workers.Done(); apikeys.Default.Run(workersCtx) }()
 
 	r := chi.NewRouter()
 
 	// базовые middleware
 	r.Use(middleware.RequestID)
+	// RealIP верит X-Forwarded-For / X-Real-IP: приложение должно стоять за
+	// прокси, который их перезаписывает (от них зависят статистика и логи)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
+	r.Use(tracing.Middleware)
+	r.Use(logging.Middleware)
+	r.Use(metrics.Middleware)
+	r.Use(logging.Recoverer)
+	r.Use(mw.HandlerTimeout(cfg.Server.HandlerTimeout))
 	r.Use(middleware.RedirectSlashes) // /path/ -> /path
+	r.Use(handlers.CachePolicy)       // Cache-Control по пути
+
+	// проверки для оркестратора: жив / готов принимать запросы
+	r.Get("/healthz", handlers.Healthz)
+	r.Get("/readyz", handlers.Readyz)
+
+	// метрики Prometheus: отдельный листенер (METRICS_ADDR) или здесь по токену
+	if metrics.Addr == "" {
+		r.Get("/metrics", metrics.Protected())
+	}
 
 	// статика
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
-	r.Handle("/images/*", http.StripPrefix("/images/", http.FileServer(http.Dir("web/images"))))
-	// Если понадобится — раскомментируй раздачу загруженных файлов:
-	r.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir("uploads"))))
+	r.Handle("/static/*", http.StripPrefix("/static/", views.Static()))
+	// загруженные файлы — с оригинальным именем в Content-Disposition
+	r.Handle("/uploads/*", handlers.ServeUploads())
 
 	// ---------- Публичные HTML-страницы ----------
 	r.Get("/", handlers.ShowIndexPage)
 	r.Get("/collections", handlers.ShowCollectionsPage)
 	r.Get("/collections/{id}", handlers.ShowCollectionPage)
+	r.Get("/collections/{id}/pdf", handlers.DownloadCollectionPDF) // со счётчиком скачиваний
+	r.Get("/authors", handlers.ShowAuthorsPage)
+	r.Get("/authors/{id}", handlers.ShowAuthorPage)
 
 	// Подача статьи (форма + приём)
 	r.Get("/article", handlers.ShowArticleForm)
 	r.Post("/article", handlers.AddArticle)
 
+	// Отслеживание заявки автором (доступ по секретной ссылке из письма)
+	r.Get("/submission/{token}", handlers.ShowSubmissionPage)
+	r.Post("/submission/{token}/withdraw", handlers.WithdrawSubmission)
+	r.Post("/submission/{token}/versions", handlers.UploadSubmissionVersion)
+
 	// ---------- Аутентификация администратора ----------
 	r.Get("/admin/login", handlers.ShowLoginPage)
 	r.Post("/admin/login", handlers.HandleLogin)
 	r.Post("/admin/logout", handlers.HandleLogout)
 
+	// ---------- Кабинет рецензента ----------
+	r.Get("/reviewer/login", handlers.ShowReviewerLoginPage)
+	r.Post("/reviewer/login", handlers.HandleReviewerLogin)
+	r.Post("/reviewer/logout", handlers.HandleReviewerLogout)
+	// рукопись — по подписанной ссылке со сроком действия, без сессии
+	r.Get("/reviewer/assignments/{id}/download", handlers.DownloadReviewManuscript)
+	r.Group(func(g chi.Router) {
+		g.Use(mw.ReviewerOnlyMW)
+
+		g.Get("/reviewer", handlers.ReviewerDashboard)
+		g.Get("/reviewer/assignments/{id}", handlers.ShowReviewAssignment)
+		g.Post("/reviewer/assignments/{id}", handlers.SubmitReviewReport)
+		g.Post("/reviewer/assignments/{id}/decline", handlers.DeclineReviewAssignment)
+	})
+
 	// ---------- Админ-панель (UI-страницы) ----------
 	r.Group(func(g chi.Router) {
 		g.Use(mw.AdminOnlyMW) // доступ только с валидной сессией
 
 		g.Get("/admin/panel/collections", handlers.AdminCollectionsPage)
 		g.Get("/admin/panel/articles", handlers.AdminArticlesPage)
+		g.Get("/admin/panel/reviewers", handlers.AdminReviewersPage)
+		g.Get("/admin/panel/authors", handlers.AdminAuthorsPage)
+		g.Get("/admin/panel/stats", handlers.AdminStatsPage)
+		g.Get("/admin/panel/apikeys", handlers.AdminAPIKeysPage)
 	})
 
 	// ---------- Публичное JSON API для сборников ----------
-	r.Get("/api/collections", handlers.GetCollections)
-	r.Get("/api/collections/{id}", handlers.GetCollectionByID)
+	// устарело: без ключа и версии, формат повторяет строку БД; замена — /api/v1
+	r.With(handlers.Deprecated).Get("/api/collections", handlers.GetCollections)
+	r.With(handlers.Deprecated).Get("/api/collections/{id}", handlers.GetCollectionByID)
+
+	// ---------- Партнёрское API v1 (по API-ключу, с лимитом) ----------
+	r.Route("/api/v1", func(v1 chi.Router) {
+		v1.Use(handlers.RequireAPIKey)
+		v1.Get("/collections", handlers.V1Collections)
+		v1.Get("/collections/{id}", handlers.V1Collection)
+	})
+
+	// ---------- Описание API (OpenAPI 3.1) ----------
+	r.Get("/api/openapi.json", openapi.Handler)
+	r.Get("/api/docs", openapi.DocsHandler)
 
 	// ---------- Админ API для сборников ----------
 	// create
 	r.Post("/admin/collection", mw.AdminOnly(handlers.CreateCollection))
-	// update — поддерживаем и PUT, и POST с _method=PUT (как делает твой admin.js)
+	// update — PUT с JSON (схема CollectionUpdate)
 	r.Put("/admin/collection/{id}", mw.AdminOnly(handlers.UpdateCollection))
-	r.Post("/admin/collection/{id}", mw.AdminOnly(handlers.UpdateCollection))
+	// замена файлов — PUT с multipart (поле cover / pdf)
+	r.Put("/admin/collection/{id}/cover", mw.AdminOnly(handlers.ReplaceCollectionCover))
+	r.Put("/admin/collection/{id}/pdf", mw.AdminOnly(handlers.ReplaceCollectionPDF))
 	// delete
 	r.Delete("/admin/collection/{id}", mw.AdminOnly(handlers.DeleteCollection))
 
@@ -72,20 +201,108 @@ func main() {
 	r.Get("/admin/articles", mw.AdminOnly(handlers.GetArticles))
 	r.Get("/admin/articles/{id}", mw.AdminOnly(handlers.GetArticleByID))
 	r.Delete("/admin/articles/{id}", mw.AdminOnly(handlers.DeleteArticle))
+	r.Post("/admin/articles/{id}/status", mw.AdminOnly(handlers.SetArticleStatus))
 	r.Get("/admin/articles/{id}/download", mw.AdminOnly(handlers.DownloadArticleFile))
+	r.Get("/admin/articles/{id}/export", mw.AdminOnly(handlers.ExportArticleMetadata))
+	r.Get("/admin/articles/{id}/versions", mw.AdminOnly(handlers.GetArticleVersions))
+	r.Post("/admin/articles/{id}/versions", mw.AdminOnly(handlers.UploadArticleVersion))
+
+	r.Post("/admin/articles/{id}/collection", mw.AdminOnly(handlers.SetArticleCollection))
+
+	// ---------- Админ API реестра авторов ----------
+	r.Get("/admin/authors", mw.AdminOnly(handlers.GetAuthors))
+	r.Post("/admin/authors/merge", mw.AdminOnly(handlers.MergeAuthors))
+	r.Post("/admin/authors/relink", mw.AdminOnly(handlers.RelinkAuthors))
+
+	// ---------- Админ API статистики ----------
+	r.Get("/admin/stats", mw.AdminOnly(handlers.GetStats))
+	r.Get("/admin/stats/counter.csv", mw.AdminOnly(handlers.ExportCounterCSV))
+
+	// ---------- Админ API ключей партнёрского API ----------
+	r.Get("/admin/apikeys", mw.AdminOnly(handlers.GetAPIKeys))
+	r.Post("/admin/apikeys", mw.AdminOnly(handlers.CreateAPIKey))
+	r.Delete("/admin/apikeys/{id}", mw.AdminOnly(handlers.RevokeAPIKey))
+
+	// ---------- Админ API рецензирования ----------
+	r.Get("/admin/reviewers", mw.AdminOnly(handlers.GetReviewers))
+	r.Post("/admin/reviewers", mw.AdminOnly(handlers.CreateReviewer))
+	r.Patch("/admin/reviewers/{id}", mw.AdminOnly(handlers.UpdateReviewer))
+	r.Get("/admin/articles/{id}/reviews", mw.AdminOnly(handlers.GetArticleReviews))
+	r.Post("/admin/articles/{id}/reviews", mw.AdminOnly(handlers.AssignReviewer))
+	r.Delete("/admin/articles/{id}/reviews/{assignmentID}", mw.AdminOnly(handlers.UnassignReviewer))
 
 	// ---------- Старт сервера ----------
-	host := getenv("HOST", "127.0.0.1")
-	addr := host + ":" + getenv("PORT", "8080")
-	log.Printf("listening on %s", addr)
-	if err := http.ListenAndServe(addr, r); err != nil {
-		log.Fatal(err)
+	srv := &http.Server{
+		Addr:    cfg.Server.Addr(),
+		Handler: r,
+		// заголовки — быстро (защита от slowloris); тело — с запасом на рукопись
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	servers := []*http.Server{srv}
+	if metrics.Addr != "" {
+		servers = append(servers, metrics.Server())
+	}
+
+	serveErr := make(chan error, len(servers))
+	for _, s := range servers {
+		go func(s *http.Server) {
+			slog.Info("listening", "addr", s.Addr)
+			if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- err
+			}
+		}(s)
+	}
+
+	// SIGTERM (docker stop, редеплой) или Ctrl+C: новые соединения не принимаем,
+	// текущие запросы (в том числе загрузки) дообслуживаем до SHUTDOWN_TIMEOUT
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	exitCode := 0
+	select {
+	case <-sigCtx.Done():
+		slog.Info("shutdown: draining connections")
+	case err := <-serveErr:
+		slog.Error("server", "err", err)
+		exitCode = 1
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	for _, s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			slog.Error("shutdown", "addr", s.Addr, "err", err)
+			_ = s.Close()
+		}
 	}
+
+	stopWorkers()
+	workers.Wait()
+	if err := db.DB.Close(); err != nil {
+		slog.Error("shutdown: db", "err", err)
+	}
+	// последние спаны — в экспортёр, пока не вышли
+	tctx, tcancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(tctx); err != nil {
+		slog.Error("shutdown: tracing", "err", err)
+	}
+	tcancel()
+	slog.Info("shutdown: done")
+	return exitCode
 }
 
-func getenv(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+// healthcheck опрашивает /readyz запущенного сервера; код выхода 0 — готов
+func healthcheck(port string) int {
+	c := http.Client{Timeout: 5 * time.Second}
+	res, err := c.Get("http://127.0.0.1:" + port + "/readyz")
+	if err != nil {
+		return 1
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 1
 	}
-	return def
+	return 0
 }
@@ -0,0 +1,10 @@
+-- Реестр загруженных файлов: безопасное имя на диске -> оригинальное имя
+
+CREATE TABLE IF NOT EXISTS uploaded_files (
+    id            SERIAL PRIMARY KEY,
+    path          TEXT UNIQUE NOT NULL,   -- публичный путь: /uploads/pdfs/sbornik_2011_vyp2_1a2b3c4d.pdf
+    original_name TEXT NOT NULL,          -- имя, с которым файл загрузили (для Content-Disposition)
+    content_type  TEXT NOT NULL,
+    size          BIGINT NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
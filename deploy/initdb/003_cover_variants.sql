@@ -0,0 +1,3 @@
+-- Адаптивные варианты обложки: [{"width":320,"format":"jpeg","path":"/uploads/covers/..."}, ...]
+
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS cover_variants JSONB;
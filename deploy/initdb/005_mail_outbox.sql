@@ -0,0 +1,18 @@
+-- Очередь исходящих писем (доставляет воркер из internal/mail)
+
+CREATE TABLE IF NOT EXISTS mail_outbox (
+    id              SERIAL PRIMARY KEY,
+    to_addr         TEXT NOT NULL,
+    subject         TEXT NOT NULL,
+    body            TEXT NOT NULL,
+    template        TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL DEFAULT 'pending',  -- pending | sending | sent | failed
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMP
+);
+
+-- те же строки, что забирает воркер: новые и зависшие в отправке
+CREATE INDEX IF NOT EXISTS mail_outbox_pending_idx ON mail_outbox (next_attempt_at) WHERE status IN ('pending', 'sending');
@@ -0,0 +1,13 @@
+-- Отслеживание заявки автором: хеш секретного токена + история статусов
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS tracking_token_hash TEXT UNIQUE;
+
+CREATE TABLE IF NOT EXISTS article_events (
+    id         SERIAL PRIMARY KEY,
+    article_id INT NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    status     TEXT NOT NULL,
+    message    TEXT NOT NULL DEFAULT '',   -- комментарий редакции для автора
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS article_events_article_idx ON article_events (article_id);
@@ -0,0 +1,19 @@
+-- Версии рукописи: articles.file_path — текущая, здесь — вся история
+
+CREATE TABLE IF NOT EXISTS article_versions (
+    id            SERIAL PRIMARY KEY,
+    article_id    INT NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    version       INT NOT NULL,
+    file_path     TEXT NOT NULL,
+    original_name TEXT NOT NULL DEFAULT '',
+    uploaded_by   TEXT NOT NULL DEFAULT 'author',   -- author | editor
+    note          TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (article_id, version)
+);
+
+-- Уже поданные заявки: их единственный файл — версия 1
+INSERT INTO article_versions (article_id, version, file_path, created_at)
+SELECT a.id, 1, a.file_path, a.created_at
+FROM articles a
+WHERE NOT EXISTS (SELECT 1 FROM article_versions v WHERE v.article_id = a.id);
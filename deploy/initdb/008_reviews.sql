@@ -0,0 +1,32 @@
+-- Рецензирование: учётные записи рецензентов, назначения и заключения
+
+CREATE TABLE IF NOT EXISTS reviewers (
+    id            SERIAL PRIMARY KEY,
+    name          TEXT NOT NULL,
+    email         TEXT NOT NULL,
+    login         TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS review_assignments (
+    id          SERIAL PRIMARY KEY,
+    article_id  INT NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    reviewer_id INT NOT NULL REFERENCES reviewers(id) ON DELETE CASCADE,
+    due_date    DATE,
+    status      TEXT NOT NULL DEFAULT 'assigned',   -- assigned | submitted | declined
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (article_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS review_assignments_reviewer_idx ON review_assignments (reviewer_id);
+
+CREATE TABLE IF NOT EXISTS review_reports (
+    id                    SERIAL PRIMARY KEY,
+    assignment_id         INT NOT NULL UNIQUE REFERENCES review_assignments(id) ON DELETE CASCADE,
+    recommendation        TEXT NOT NULL,     -- accept | minor_revision | major_revision | reject
+    comments_for_author   TEXT NOT NULL DEFAULT '',
+    confidential_comments TEXT NOT NULL DEFAULT '',  -- только для редакции
+    submitted_at          TIMESTAMP NOT NULL DEFAULT NOW()
+);
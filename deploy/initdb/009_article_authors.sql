@@ -0,0 +1,24 @@
+-- Соавторы заявки: articles.author остаётся строкой для списков и писем,
+-- структурированные данные — здесь
+
+CREATE TABLE IF NOT EXISTS article_authors (
+    id            SERIAL PRIMARY KEY,
+    article_id    INT NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    position      INT NOT NULL,                    -- порядок в списке авторов, с 1
+    surname       TEXT NOT NULL,
+    given_names   TEXT NOT NULL DEFAULT '',
+    affiliation   TEXT NOT NULL DEFAULT '',
+    orcid         TEXT NOT NULL DEFAULT '',        -- 0000-0000-0000-000X, контрольная цифра проверяется в приложении
+    corresponding BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (article_id, position)
+);
+
+-- Не больше одного контактного автора на заявку
+CREATE UNIQUE INDEX IF NOT EXISTS article_authors_corresponding_idx
+    ON article_authors (article_id) WHERE corresponding;
+
+-- Уже поданные заявки: вся строка author — один контактный автор
+INSERT INTO article_authors (article_id, position, surname, corresponding)
+SELECT a.id, 1, a.author, TRUE
+FROM articles a
+WHERE NOT EXISTS (SELECT 1 FROM article_authors x WHERE x.article_id = a.id);
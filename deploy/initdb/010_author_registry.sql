@@ -0,0 +1,26 @@
+-- Реестр авторов и привязка статей к сборникам
+
+-- Сборник, в котором опубликована принятая статья
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS collection_id INT REFERENCES collections(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS articles_collection_idx ON articles (collection_id);
+
+-- Один человек — одна запись; варианты написания склеиваются по name_key или ORCID.
+-- После ручного объединения дубликат остаётся с merged_into (для редиректа старых ссылок).
+CREATE TABLE IF NOT EXISTS authors (
+    id          SERIAL PRIMARY KEY,
+    surname     TEXT NOT NULL,
+    given_names TEXT NOT NULL DEFAULT '',
+    orcid       TEXT NOT NULL DEFAULT '',
+    name_key    TEXT NOT NULL,                     -- "ivanov i p", см. authorKey
+    merged_into INT REFERENCES authors(id) ON DELETE SET NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS authors_name_key_idx ON authors (name_key) WHERE merged_into IS NULL;
+CREATE INDEX IF NOT EXISTS authors_orcid_idx ON authors (orcid) WHERE orcid <> '' AND merged_into IS NULL;
+
+ALTER TABLE article_authors ADD COLUMN IF NOT EXISTS author_id INT REFERENCES authors(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS article_authors_author_idx ON article_authors (author_id);
+
+-- Существующие строки article_authors связываются из админки:
+-- «Авторы» → «Связать новых» (POST /admin/authors/relink), ключ имени считается в приложении.
@@ -0,0 +1,13 @@
+-- Статистика просмотров страниц сборников и скачиваний PDF (дневные агрегаты).
+-- Счётчики копятся в памяти приложения и периодически прибавляются (internal/stats).
+CREATE TABLE IF NOT EXISTS collection_stats_daily (
+    day              DATE NOT NULL,
+    collection_id    INT  NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    views            INT  NOT NULL DEFAULT 0,
+    unique_views     INT  NOT NULL DEFAULT 0,
+    downloads        INT  NOT NULL DEFAULT 0,
+    unique_downloads INT  NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, collection_id)
+);
+
+CREATE INDEX IF NOT EXISTS collection_stats_daily_collection_idx ON collection_stats_daily (collection_id, day);
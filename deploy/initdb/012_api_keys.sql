@@ -0,0 +1,20 @@
+-- Ключи партнёрского API (/api/v1): выдаются в админке, в БД — только SHA-256 токена.
+-- Использование копится в памяти приложения и периодически прибавляется (internal/apikeys).
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           SERIAL PRIMARY KEY,
+    name         TEXT NOT NULL,                -- кому выдан: библиотека, проект
+    prefix       TEXT NOT NULL,                -- начало токена, чтобы узнать ключ в списке
+    token_hash   TEXT NOT NULL UNIQUE,
+    rate_limit   INT  NOT NULL,                -- запросов в минуту
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP,
+    revoked_at   TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage_daily (
+    day      DATE NOT NULL,
+    key_id   INT  NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    requests INT  NOT NULL DEFAULT 0,
+    limited  INT  NOT NULL DEFAULT 0,          -- отклонено по лимиту (429)
+    PRIMARY KEY (day, key_id)
+);
@@ -0,0 +1,48 @@
+-- Время последнего изменения сборника — для ETag / Last-Modified (HTTP-кэш).
+-- С часовым поясом: значение уходит клиенту в Last-Modified и сравнивается
+-- с If-Modified-Since, поясом сессии оно не должно зависеть.
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Любое изменение строки сборника
+CREATE OR REPLACE FUNCTION collections_touch() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := NOW();
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS collections_touch ON collections;
+CREATE TRIGGER collections_touch BEFORE UPDATE ON collections
+    FOR EACH ROW EXECUTE FUNCTION collections_touch();
+
+-- Страница сборника показывает его статьи с авторами: их изменение — тоже изменение сборника
+CREATE OR REPLACE FUNCTION collections_touch_by_article() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE collections SET updated_at = NOW() WHERE id = NEW.collection_id;
+    ELSIF TG_OP = 'DELETE' THEN
+        UPDATE collections SET updated_at = NOW() WHERE id = OLD.collection_id;
+    ELSE
+        UPDATE collections SET updated_at = NOW() WHERE id IN (OLD.collection_id, NEW.collection_id);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS collections_touch_by_article ON articles;
+CREATE TRIGGER collections_touch_by_article AFTER INSERT OR DELETE OR UPDATE OF collection_id, title, status ON articles
+    FOR EACH ROW EXECUTE FUNCTION collections_touch_by_article();
+
+CREATE OR REPLACE FUNCTION collections_touch_by_author() RETURNS trigger AS $$
+BEGIN
+    UPDATE collections SET updated_at = NOW()
+    WHERE id IN (SELECT collection_id FROM articles
+                 WHERE id IN (CASE WHEN TG_OP <> 'INSERT' THEN OLD.article_id END,
+                              CASE WHEN TG_OP <> 'DELETE' THEN NEW.article_id END));
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS collections_touch_by_author ON article_authors;
+CREATE TRIGGER collections_touch_by_author AFTER INSERT OR DELETE OR UPDATE ON article_authors
+    FOR EACH ROW EXECUTE FUNCTION collections_touch_by_author();
@@ -0,0 +1,5 @@
+-- Индекс очереди писем под запрос воркера (internal/mail): он забирает и
+-- строки, зависшие в 'sending', а старый индекс из 005 был только по 'pending'.
+-- Новое имя — чтобы применённость миграции было видно по схеме.
+DROP INDEX IF EXISTS mail_outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS mail_outbox_due_idx ON mail_outbox (next_attempt_at) WHERE status IN ('pending', 'sending');
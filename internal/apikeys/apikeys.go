@@ -0,0 +1,187 @@
+package apikeys
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/internal/db"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ключи партнёрского API /api/v1. Токен показывается один раз при выдаче,
+// в БД хранится его SHA-256 (как у ссылок отслеживания заявки). Ключ
+// проверяется на каждый запрос, поэтому найденные ключи держатся в памяти
+// CacheTTL; отзыв через админку сбрасывает кэш сразу.
+// Лимит — «ведро токенов» на ключ: RateLimit запросов в минуту, всплеск —
+// тоже до RateLimit. Счётчики ведутся в памяти процесса: с несколькими
+// репликами лимит действует на каждую отдельно.
+
+// TokenPrefix — начало каждого токена: ключ узнаётся в логах и сканерах секретов
+const TokenPrefix = "bck_"
+
+var (
+	// DefaultRateLimit — лимит нового ключа, запросов в минуту (API_RATE_LIMIT)
+	DefaultRateLimit = 60
+	// CacheTTL — сколько ключ живёт в кэше проверки
+	CacheTTL = time.Minute
+)
+
+// ErrInvalid — ключа нет или он отозван
+var ErrInvalid = errors.New("apikeys: invalid key")
+
+// Init настраивает лимит по умолчанию
+func Init(c config.API) {
+	DefaultRateLimit = c.RateLimit
+}
+
+// Key — выданный ключ (без токена)
+type Key struct {
+	ID        int
+	Name      string
+	Prefix    string
+	RateLimit int
+}
+
+// Generate — новый токен и его хэш для БД; prefix — для показа в списке
+func Generate() (token, prefix, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, token[:len(TokenPrefix)+6], Hash(token), nil
+}
+
+// Hash — SHA-256 токена в hex (api_keys.token_hash)
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type cached struct {
+	key     *Key // nil — ключа нет (не ходим в БД на каждый перебор)
+	expires time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]cached{}
+)
+
+// Lookup — действующий ключ по токену из запроса
+func Lookup(ctx context.Context, token string) (*Key, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, ErrInvalid
+	}
+	hash := Hash(token)
+	now := time.Now()
+
+	cacheMu.Lock()
+	c, ok := cache[hash]
+	cacheMu.Unlock()
+	if ok && now.Before(c.expires) {
+		if c.key == nil {
+			return nil, ErrInvalid
+		}
+		return c.key, nil
+	}
+
+	k := &Key{}
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT id, name, prefix, rate_limit FROM api_keys
+		WHERE token_hash = $1 AND revoked_at IS NULL`, hash).
+		Scan(&k.ID, &k.Name, &k.Prefix, &k.RateLimit)
+	if err == sql.ErrNoRows {
+		k = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	if len(cache) > 10000 {
+		clear(cache) // перебор случайных токенов не раздувает память
+	}
+	cache[hash] = cached{key: k, expires: now.Add(CacheTTL)}
+	cacheMu.Unlock()
+
+	if k == nil {
+		return nil, ErrInvalid
+	}
+	return k, nil
+}
+
+// Forget убирает ключ из кэша сразу после отзыва, не дожидаясь CacheTTL
+func Forget(id int) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	for h, c := range cache {
+		if c.key != nil && c.key.ID == id {
+			delete(cache, h)
+		}
+	}
+}
+
+/* ========= ЛИМИТ ЗАПРОСОВ ========= */
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter — «ведро токенов» на каждый ключ
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[int]*bucket
+	now     func() time.Time
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{buckets: map[int]*bucket{}, now: time.Now}
+}
+
+// Limits — общий лимитер приложения
+var Limits = NewLimiter()
+
+// Allow списывает запрос ключа id с лимитом perMinute. remaining — сколько ещё
+// можно сразу; при отказе retryAfter — когда освободится следующий запрос.
+func (l *Limiter) Allow(id, perMinute int) (ok bool, remaining int, retryAfter time.Duration) {
+	now := l.now()
+	rate := float64(perMinute) / 60 // токенов в секунду
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[id]
+	if b == nil {
+		b = &bucket{tokens: float64(perMinute), last: now}
+		l.buckets[id] = b
+	}
+	b.tokens = math.Min(float64(perMinute), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// prune удаляет вёдра, которые успели наполниться: они не отличаются от новых
+func (l *Limiter) prune(idle time.Duration) {
+	cutoff := l.now().Add(-idle)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, b := range l.buckets {
+		if b.last.Before(cutoff) {
+			delete(l.buckets, id)
+		}
+	}
+}
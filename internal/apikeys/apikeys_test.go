@@ -0,0 +1,82 @@
+package apikeys
+
+import (
+	"testing"
+	"time"
+)
+
+// clock — управляемое время для Limiter и Usage
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter() (*Limiter, *clock) {
+	c := &clock{t: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	l := NewLimiter()
+	l.now = c.now
+	return l, c
+}
+
+func TestLimiterBurstThenRefill(t *testing.T) {
+	l, c := newTestLimiter()
+
+	// всплеск — до лимита сразу
+	for want := 2; want >= 0; want-- {
+		ok, remaining, _ := l.Allow(1, 3)
+		if !ok || remaining != want {
+			t.Fatalf("burst: ok = %v, remaining = %d, want %d", ok, remaining, want)
+		}
+	}
+	// 3 в минуту — новый запрос через 20 с
+	ok, _, retry := l.Allow(1, 3)
+	if ok || retry != 20*time.Second {
+		t.Fatalf("empty bucket: ok = %v, retryAfter = %v, want 20s", ok, retry)
+	}
+	c.advance(10 * time.Second)
+	if ok, _, retry := l.Allow(1, 3); ok || retry != 10*time.Second {
+		t.Fatalf("half refilled: ok = %v, retryAfter = %v, want 10s", ok, retry)
+	}
+	c.advance(10 * time.Second)
+	if ok, remaining, _ := l.Allow(1, 3); !ok || remaining != 0 {
+		t.Fatalf("refilled: ok = %v, remaining = %d", ok, remaining)
+	}
+
+	// простой не копит больше лимита
+	c.advance(time.Hour)
+	if ok, remaining, _ := l.Allow(1, 3); !ok || remaining != 2 {
+		t.Fatalf("after idle: ok = %v, remaining = %d, want 2", ok, remaining)
+	}
+
+	// у другого ключа своё ведро
+	if ok, remaining, _ := l.Allow(2, 3); !ok || remaining != 2 {
+		t.Fatalf("other key: ok = %v, remaining = %d", ok, remaining)
+	}
+}
+
+func TestLimiterLoweredLimitCapsTokens(t *testing.T) {
+	l, _ := newTestLimiter()
+	if ok, remaining, _ := l.Allow(1, 60); !ok || remaining != 59 {
+		t.Fatalf("ok = %v, remaining = %d", ok, remaining)
+	}
+	// лимит снизили (ключ перечитан из БД): накопленное урезается до нового
+	if ok, remaining, _ := l.Allow(1, 5); !ok || remaining != 4 {
+		t.Fatalf("lowered: ok = %v, remaining = %d, want 4", ok, remaining)
+	}
+}
+
+func TestLimiterPrune(t *testing.T) {
+	l, c := newTestLimiter()
+	l.Allow(1, 60)
+	c.advance(2 * time.Minute)
+	l.Allow(2, 60)
+	c.advance(30 * time.Second)
+
+	l.prune(time.Minute)
+	if _, ok := l.buckets[1]; ok {
+		t.Error("idle bucket kept")
+	}
+	if _, ok := l.buckets[2]; !ok {
+		t.Error("recent bucket pruned")
+	}
+}
@@ -0,0 +1,136 @@
+package apikeys
+
+import (
+	"BookCollect/internal/db"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Счётчики использования: запросы и отказы по лимиту на ключ и день.
+// Копятся в памяти и раз в FlushInterval прибавляются к api_key_usage_daily
+// (как статистика сборников в internal/stats).
+
+// FlushInterval — как часто сбрасывать накопленное в БД
+var FlushInterval = time.Minute
+
+type usageKey struct {
+	day time.Time
+	id  int
+}
+
+// Counts — счётчики одного ключа за день
+type Counts struct {
+	Requests, Limited int
+	LastUsed          time.Time
+}
+
+// Usage — накопитель между сбросами в БД
+type Usage struct {
+	mu      sync.Mutex
+	pending map[usageKey]*Counts
+	now     func() time.Time
+}
+
+func NewUsage() *Usage {
+	return &Usage{pending: map[usageKey]*Counts{}, now: time.Now}
+}
+
+// Default — общий накопитель приложения
+var Default = NewUsage()
+
+// Record учитывает запрос ключа id; limited — отклонён по лимиту
+func (u *Usage) Record(id int, limited bool) {
+	now := u.now()
+	y, m, d := now.UTC().Date()
+	k := usageKey{day: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), id: id}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	c := u.pending[k]
+	if c == nil {
+		c = &Counts{}
+		u.pending[k] = c
+	}
+	if limited {
+		c.Limited++
+	} else {
+		c.Requests++
+		c.LastUsed = now
+	}
+}
+
+func (u *Usage) take() map[usageKey]*Counts {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	out := u.pending
+	u.pending = map[usageKey]*Counts{}
+	return out
+}
+
+// putBack возвращает несохранённые счётчики (БД недоступна — попробуем в следующий раз)
+func (u *Usage) putBack(m map[usageKey]*Counts) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for k, c := range m {
+		p := u.pending[k]
+		if p == nil {
+			u.pending[k] = c
+			continue
+		}
+		p.Requests += c.Requests
+		p.Limited += c.Limited
+		if c.LastUsed.After(p.LastUsed) {
+			p.LastUsed = c.LastUsed
+		}
+	}
+}
+
+// Flush прибавляет накопленное к дневным счётчикам и обновляет last_used_at
+func (u *Usage) Flush(ctx context.Context) error {
+	batch := u.take()
+	for k, c := range batch {
+		_, err := db.DB.ExecContext(ctx, `
+			WITH usage AS (
+				INSERT INTO api_key_usage_daily (day, key_id, requests, limited)
+				SELECT $1, $2, $3, $4
+				WHERE EXISTS (SELECT 1 FROM api_keys WHERE id = $2)
+				ON CONFLICT (day, key_id) DO UPDATE SET
+					requests = api_key_usage_daily.requests + EXCLUDED.requests,
+					limited  = api_key_usage_daily.limited + EXCLUDED.limited
+			)
+			UPDATE api_keys SET last_used_at = GREATEST(last_used_at, $5)
+			WHERE id = $2 AND $3 > 0`,
+			k.day, k.id, c.Requests, c.Limited, c.LastUsed)
+		if err != nil {
+			u.putBack(batch)
+			return err
+		}
+		delete(batch, k)
+	}
+	return nil
+}
+
+// Run сбрасывает счётчики раз в FlushInterval до отмены ctx (и последний раз
+// при остановке); заодно чистит вёдра лимитера, простаивающие дольше минуты
+func (u *Usage) Run(ctx context.Context) {
+	t := time.NewTicker(FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := u.Flush(fctx); err != nil {
+				slog.Error("apikeys: final usage flush", "err", err)
+			}
+			cancel()
+			return
+		case <-t.C:
+			if err := u.Flush(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("apikeys: usage flush", "err", err)
+			}
+			Limits.prune(time.Minute)
+		}
+	}
+}
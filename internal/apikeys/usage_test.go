@@ -0,0 +1,86 @@
+package apikeys
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/db/dbtest"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestUsage() (*Usage, *clock) {
+	c := &clock{t: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	u := NewUsage()
+	u.now = c.now
+	return u, c
+}
+
+func TestUsageRecordByUTCDay(t *testing.T) {
+	u, c := newTestUsage()
+	// 2 марта 01:00 в Москве — ещё 1 марта по UTC
+	c.t = time.Date(2025, 3, 2, 1, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	u.Record(1, false)
+	u.Record(1, true)
+
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	got := u.pending[usageKey{day: day, id: 1}]
+	if got == nil || got.Requests != 1 || got.Limited != 1 || !got.LastUsed.Equal(c.t) {
+		t.Fatalf("pending = %+v", u.pending)
+	}
+}
+
+func TestUsagePutBackMerges(t *testing.T) {
+	u, c := newTestUsage()
+	u.Record(1, false)
+	u.Record(1, true)
+	u.Record(2, false)
+	batch := u.take()
+
+	// пока шёл неудачный сброс, пришли новые запросы
+	c.advance(time.Second)
+	u.Record(1, false)
+	u.putBack(batch)
+
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	one, two := u.pending[usageKey{day, 1}], u.pending[usageKey{day, 2}]
+	if one == nil || one.Requests != 2 || one.Limited != 1 || !one.LastUsed.Equal(c.t) {
+		t.Errorf("key 1 = %+v, want 2 requests, 1 limited, last used at %v", one, c.t)
+	}
+	if two == nil || two.Requests != 1 {
+		t.Errorf("key 2 = %+v", two)
+	}
+}
+
+func TestUsageFlushKeepsCountsOnError(t *testing.T) {
+	fake := dbtest.New()
+	old := db.DB
+	db.DB = fake.Open()
+	t.Cleanup(func() { db.DB.Close(); db.DB = old })
+
+	u, _ := newTestUsage()
+	u.Record(1, false)
+	u.Record(1, false)
+
+	fake.On("api_key_usage_daily", dbtest.Result{Err: errors.New("connection refused")})
+	if err := u.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded with the database down")
+	}
+	u.Record(1, false)
+	if n := len(u.pending); n != 1 {
+		t.Fatalf("%d pending keys after failed flush, want 1", n)
+	}
+	for _, c := range u.pending {
+		if c.Requests != 3 {
+			t.Fatalf("requests = %d after failed flush, want 3", c.Requests)
+		}
+	}
+
+	fake.On("api_key_usage_daily", dbtest.Result{Affected: 1})
+	if err := u.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.pending) != 0 {
+		t.Errorf("pending after flush = %v", u.pending)
+	}
+}
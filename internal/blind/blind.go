@@ -0,0 +1,44 @@
+package blind
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+
+	"BookCollect/internal/config"
+	"BookCollect/internal/filetype"
+)
+
+// Режим рецензирования. При двойном слепом рецензенту не показываются автор и
+// email заявки, а из отдаваемой рукописи вычищаются метаданные об авторе.
+var Enabled = true
+
+var ErrUnsupported = errors.New("blind: unsupported document type")
+
+// Init задаёт режим: double_blind или single_blind (проверен в config)
+func Init(c config.Review) {
+	Enabled = c.Mode != "single_blind"
+	if Enabled {
+		slog.Info("blind: double-blind review, manuscripts are anonymised")
+	} else {
+		slog.Info("blind: single-blind review, reviewers see authors")
+	}
+}
+
+// Scrub пишет в w копию документа без сведений об авторе:
+//
+//	DOCX — docProps/core.xml (creator, lastModifiedBy), docProps/app.xml (Company, Manager),
+//	       авторы правок и комментариев и rsid в word/*.xml
+//	ODT  — meta.xml (initial-creator, creator), авторы правок и примечаний и rsid
+//	       в content.xml/styles.xml
+//	PDF  — /Author в словарях Info и dc:creator в несжатом XMP; автор, которого
+//	       так не затереть (косвенный объект, сжатые потоки), — ErrPDFMetadata
+func Scrub(kind filetype.Kind, r io.ReaderAt, size int64, w io.Writer) error {
+	switch kind {
+	case filetype.DOCX, filetype.ODT:
+		return scrubZip(kind, r, size, w)
+	case filetype.PDF:
+		return scrubPDF(r, size, w)
+	}
+	return ErrUnsupported
+}
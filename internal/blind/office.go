@@ -0,0 +1,143 @@
+package blind
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"BookCollect/internal/filetype"
+)
+
+// Предел распакованного размера одной XML-части: защита от zip-бомб
+const maxPartSize = 64 << 20
+
+var errPartTooLarge = errors.New("blind: document part too large")
+
+// rule — замена в XML-части документа
+type rule struct {
+	re   *regexp.Regexp
+	repl []byte
+}
+
+// element очищает текст элемента <name ...>...</name> (без вложенных тегов)
+func element(name string) rule {
+	q := regexp.QuoteMeta(name)
+	return rule{regexp.MustCompile(`(<` + q + `(?:\s[^>]*)?>)[^<]*(</` + q + `>)`), []byte(`${1}${2}`)}
+}
+
+// attr очищает значение атрибута name="..." или name='...'
+func attr(name string) rule {
+	return rule{regexp.MustCompile(`(\s` + regexp.QuoteMeta(name) + `=)(?:"[^"]*"|'[^']*')`), []byte(`${1}""`)}
+}
+
+// dropAttrs убирает атрибуты, имя которых начинается с prefix (w:rsidR, w:rsidRPr...):
+// пустое значение у них недопустимо, а сами они необязательны
+func dropAttrs(prefix string) rule {
+	return rule{regexp.MustCompile(`\s` + regexp.QuoteMeta(prefix) + `[\w-]*=(?:"[^"]*"|'[^']*')`), nil}
+}
+
+// dropElement убирает элемент <name>...</name> или <name/> вместе с содержимым
+func dropElement(name string) rule {
+	q := regexp.QuoteMeta(name)
+	return rule{regexp.MustCompile(`(?s)<` + q + `(?:\s[^>]*)?(?:/>|>.*?</` + q + `>)`), nil}
+}
+
+// Идентификаторы сеансов правки (rsid) одинаковы во всех документах, которые
+// правились в одном сеансе, — по ним рукопись связывается с другими файлами автора.
+var (
+	docxCore = []rule{element("dc:creator"), element("cp:lastModifiedBy")}
+	docxApp  = []rule{element("Company"), element("Manager")}
+	docxBody = []rule{
+		attr("w:author"), attr("w:initials"), // правки и комментарии
+		attr("w15:author"), attr("w15:userId"), // word/people.xml — авторы комментариев
+		dropAttrs("w:rsid"), dropElement("w:rsids"),
+	}
+
+	odtMeta = []rule{element("meta:initial-creator"), element("dc:creator")}
+	odtBody = []rule{
+		element("dc:creator"), element("meta:creator-initials"), // правки и примечания
+		dropAttrs("officeooo:rsid"), dropAttrs("officeooo:paragraph-rsid"),
+	}
+)
+
+// partRules — какие правила применяются к части архива (nil — копируем как есть)
+func partRules(kind filetype.Kind, name string) []rule {
+	switch kind {
+	case filetype.DOCX:
+		switch {
+		case name == "docProps/core.xml":
+			return docxCore
+		case name == "docProps/app.xml":
+			return docxApp
+		case strings.HasPrefix(name, "word/") && strings.HasSuffix(name, ".xml"):
+			return docxBody
+		}
+	case filetype.ODT:
+		switch name {
+		case "meta.xml":
+			return odtMeta
+		case "content.xml", "styles.xml":
+			return odtBody
+		}
+	}
+	return nil
+}
+
+// scrubZip пересобирает DOCX/ODT: нетронутые части копируются без перепаковки
+// (так у ODT сохраняется несжатый mimetype первым), изменённые — сжимаются заново.
+func scrubZip(kind filetype.Kind, r io.ReaderAt, size int64, w io.Writer) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	if err := zw.SetComment(zr.Comment); err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		rules := partRules(kind, f.Name)
+		if rules == nil {
+			if err := zw.Copy(f); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := readPart(f)
+		if err != nil {
+			return err
+		}
+		for _, ru := range rules {
+			data = ru.re.ReplaceAll(data, ru.repl)
+		}
+
+		hdr := f.FileHeader
+		out, err := zw.CreateHeader(&hdr)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func readPart(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPartSize {
+		return nil, errPartTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,163 @@
+package blind
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"BookCollect/internal/filetype"
+)
+
+type part struct {
+	name, body string
+	store      bool // без сжатия (mimetype в ODT)
+}
+
+func buildZip(t *testing.T, parts []part) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, p := range parts {
+		method := zip.Deflate
+		if p.store {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: p.name, Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(w, p.body)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// scrubParts прогоняет архив через Scrub и возвращает части результата по порядку
+func scrubParts(t *testing.T, kind filetype.Kind, in []byte) ([]*zip.File, map[string]string) {
+	t.Helper()
+	var out bytes.Buffer
+	if err := Scrub(kind, bytes.NewReader(in), int64(len(in)), &out); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := make(map[string]string)
+	for _, f := range zr.File {
+		data, err := readPart(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content[f.Name] = string(data)
+		if strings.HasSuffix(f.Name, ".xml") {
+			d := xml.NewDecoder(bytes.NewReader(data))
+			for {
+				if _, err := d.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed after scrub: %v", f.Name, err)
+					break
+				}
+			}
+		}
+	}
+	return zr.File, content
+}
+
+func assertScrubbed(t *testing.T, content map[string]string, leaks, keeps []string) {
+	t.Helper()
+	var all strings.Builder
+	for name, body := range content {
+		for _, leak := range leaks {
+			if strings.Contains(body, leak) {
+				t.Errorf("%s still contains %q", name, leak)
+			}
+		}
+		all.WriteString(body)
+	}
+	for _, keep := range keeps {
+		if !strings.Contains(all.String(), keep) {
+			t.Errorf("output lost %q", keep)
+		}
+	}
+}
+
+func TestScrubDOCX(t *testing.T) {
+	const w = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	in := buildZip(t, []part{
+		{name: "[Content_Types].xml", body: `<?xml version="1.0"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`},
+		{name: "docProps/core.xml", body: `<?xml version="1.0"?><cp:coreProperties xmlns:cp="urn:cp" xmlns:dc="urn:dc">` +
+			`<dc:title>Статья</dc:title><dc:creator>Иванов Иван</dc:creator><cp:lastModifiedBy>ivanov-pc</cp:lastModifiedBy>` +
+			`</cp:coreProperties>`},
+		{name: "docProps/app.xml", body: `<?xml version="1.0"?><Properties><Company>НИИ Иванова</Company><Manager>Петров</Manager><Pages>3</Pages></Properties>`},
+		{name: "word/document.xml", body: `<?xml version="1.0"?><w:document ` + w + `><w:body>` +
+			`<w:p w:rsidR="00A1B2C3" w:rsidRDefault="00D4E5F6" w:rsidP='00112233'><w:r w:rsidRPr="00445566"><w:t>Текст статьи</w:t></w:r></w:p>` +
+			`<w:ins w:id="1" w:author="Иванов Иван" w:date="2025-01-01T00:00:00Z"><w:r><w:t>вставка</w:t></w:r></w:ins>` +
+			`<w:sectPr w:rsidR="00A1B2C3"/></w:body></w:document>`},
+		{name: "word/comments.xml", body: `<?xml version="1.0"?><w:comments ` + w + `>` +
+			`<w:comment w:id="0" w:author="Иванов Иван" w:initials="ИИ"><w:p w:rsidR="00778899"><w:r><w:t>комментарий</w:t></w:r></w:p></w:comment>` +
+			`</w:comments>`},
+		{name: "word/people.xml", body: `<?xml version="1.0"?><w15:people xmlns:w15="urn:w15">` +
+			`<w15:person w15:author="Иванов Иван"><w15:presenceInfo w15:providerId="AD" w15:userId="S-1-5-21-ivanov"/></w15:person>` +
+			`</w15:people>`},
+		{name: "word/settings.xml", body: `<?xml version="1.0"?><w:settings ` + w + `><w:zoom w:percent="100"/>` +
+			`<w:rsids><w:rsidRoot w:val="00A1B2C3"/><w:rsid w:val="00A1B2C3"/><w:rsid w:val="00D4E5F6"/></w:rsids>` +
+			`<w:compat/></w:settings>`},
+		{name: "word/media/image1.png", body: "\x89PNG Иванов"}, // бинарные части не трогаем
+	})
+
+	files, content := scrubParts(t, filetype.DOCX, in)
+	if len(files) != 8 {
+		t.Errorf("%d parts, want 8", len(files))
+	}
+	if content["word/media/image1.png"] != "\x89PNG Иванов" {
+		t.Error("binary part changed")
+	}
+	delete(content, "word/media/image1.png")
+	assertScrubbed(t, content,
+		[]string{"Иванов", "ivanov", "Петров", "ИИ", "rsid", "00A1B2C3", "00D4E5F6", "00112233", "00445566", "00778899"},
+		[]string{"<dc:title>Статья</dc:title>", "<dc:creator></dc:creator>", "<cp:lastModifiedBy></cp:lastModifiedBy>",
+			"<Pages>3</Pages>", "Текст статьи", "вставка", "комментарий", `w:date="2025-01-01T00:00:00Z"`,
+			`<w:zoom w:percent="100"/><w:compat/>`, `<w:sectPr/>`})
+}
+
+func TestScrubODT(t *testing.T) {
+	const ns = `xmlns:office="urn:office" xmlns:meta="urn:meta" xmlns:dc="urn:dc" xmlns:text="urn:text" ` +
+		`xmlns:style="urn:style" xmlns:officeooo="urn:officeooo"`
+	in := buildZip(t, []part{
+		{name: "mimetype", body: "application/vnd.oasis.opendocument.text", store: true},
+		{name: "meta.xml", body: `<?xml version="1.0"?><office:document-meta ` + ns + `><office:meta>` +
+			`<meta:initial-creator>Иванов Иван</meta:initial-creator><dc:creator>Иванов И.</dc:creator><dc:title>Статья</dc:title>` +
+			`</office:meta></office:document-meta>`},
+		{name: "content.xml", body: `<?xml version="1.0"?><office:document-content ` + ns + `>` +
+			`<office:automatic-styles><style:style style:name="P1"><style:text-properties officeooo:rsid="0012ab34" officeooo:paragraph-rsid="0056cd78"/></style:style></office:automatic-styles>` +
+			`<office:body><office:text><text:p>Текст статьи` +
+			`<office:annotation><dc:creator>Иванов Иван</dc:creator><meta:creator-initials>ИИ</meta:creator-initials><dc:date>2025-01-01</dc:date><text:p>примечание</text:p></office:annotation>` +
+			`</text:p><text:tracked-changes><text:changed-region><text:insertion><office:change-info><dc:creator>Иванов Иван</dc:creator></office:change-info></text:insertion></text:changed-region></text:tracked-changes>` +
+			`</office:text></office:body></office:document-content>`},
+		{name: "styles.xml", body: `<?xml version="1.0"?><office:document-styles ` + ns + `>` +
+			`<style:style style:name="Standard"><style:text-properties officeooo:rsid="0012ab34"/></style:style></office:document-styles>`},
+	})
+
+	files, content := scrubParts(t, filetype.ODT, in)
+	if files[0].Name != "mimetype" || files[0].Method != zip.Store {
+		t.Errorf("first part = %s (method %d), want stored mimetype", files[0].Name, files[0].Method)
+	}
+	assertScrubbed(t, content,
+		[]string{"Иванов", "ИИ", "rsid", "0012ab34", "0056cd78"},
+		[]string{"application/vnd.oasis.opendocument.text", "<dc:title>Статья</dc:title>",
+			"<meta:initial-creator></meta:initial-creator>", "Текст статьи", "примечание", "<dc:date>2025-01-01</dc:date>"})
+}
+
+func TestScrubRejectsBrokenZip(t *testing.T) {
+	in := []byte("PK\x03\x04 not really a zip")
+	if err := Scrub(filetype.DOCX, bytes.NewReader(in), int64(len(in)), io.Discard); err == nil {
+		t.Error("broken archive accepted")
+	}
+}
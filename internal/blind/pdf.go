@@ -0,0 +1,319 @@
+package blind
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+// PDF не пересобираем: значения затираются пробелами той же длины, поэтому
+// смещения в xref остаются верными и файл открывается как прежде. Просматривается
+// весь файл, так что чистятся и словари Info из инкрементальных обновлений.
+//
+// То, что так не затереть, — /Author, вынесенный в отдельный объект
+// (/Author 12 0 R), автор в сжатом потоке объектов (/ObjStm) и dc:creator в
+// сжатом XMP, — распознаётся, и Scrub возвращает ErrPDFMetadata: рукопись
+// тогда не отдаётся вовсе.
+
+// ErrPDFMetadata — сведения об авторе в PDF есть, но затереть их на месте нельзя
+var ErrPDFMetadata = errors.New("blind: PDF author metadata cannot be removed in place")
+
+// maxInflated — предел распакованного потока при проверке (защита от zip-бомбы)
+const maxInflated = 64 << 20
+
+var (
+	pdfInfoKeys   = [][]byte{[]byte("/Author")}
+	xmpCreator    = []byte("<dc:creator")
+	xmpCreatorEnd = []byte("</dc:creator>")
+)
+
+func scrubPDF(r io.ReaderAt, size int64, w io.Writer) error {
+	buf := make([]byte, size)
+	if _, err := r.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return err
+	}
+	for _, key := range pdfInfoKeys {
+		if err := blankStringValues(buf, key); err != nil {
+			return err
+		}
+	}
+	blankXMPElement(buf, xmpCreator, xmpCreatorEnd)
+	if err := checkCompressed(buf); err != nil {
+		return err
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// blankStringValues затирает строки-значения ключа key: (literal) или <hex>.
+// Ссылка на объект вместо строки — ErrPDFMetadata.
+func blankStringValues(buf, key []byte) error {
+	for from := 0; ; {
+		i := bytes.Index(buf[from:], key)
+		if i < 0 {
+			return nil
+		}
+		p := from + i + len(key)
+		from = p
+		// /AuthorName — другой ключ
+		if p < len(buf) && isRegular(buf[p]) {
+			continue
+		}
+		for p < len(buf) && isSpace(buf[p]) {
+			p++
+		}
+		if p >= len(buf) {
+			return nil
+		}
+		switch {
+		case buf[p] == '(':
+			from = blankLiteral(buf, p)
+		case buf[p] == '<' && (p+1 >= len(buf) || buf[p+1] != '<'):
+			end := bytes.IndexByte(buf[p+1:], '>')
+			if end < 0 {
+				return nil
+			}
+			fill(buf[p+1 : p+1+end])
+			from = p + 1 + end
+		case isIndirectRef(buf[p:]):
+			return ErrPDFMetadata
+		}
+	}
+}
+
+// isIndirectRef — b начинается со ссылки на объект: «12 0 R»
+func isIndirectRef(b []byte) bool {
+	p := 0
+	for range 2 {
+		n := p
+		for p < len(b) && b[p] >= '0' && b[p] <= '9' {
+			p++
+		}
+		if p == n {
+			return false
+		}
+		n = p
+		for p < len(b) && isSpace(b[p]) {
+			p++
+		}
+		if p == n {
+			return false
+		}
+	}
+	return p < len(b) && b[p] == 'R' && (p+1 == len(b) || !isRegular(b[p+1]))
+}
+
+// blankLiteral затирает содержимое строки (...) с учётом вложенных скобок и
+// экранирования; возвращает позицию закрывающей скобки
+func blankLiteral(buf []byte, open int) int {
+	depth := 0
+	for p := open; p < len(buf); p++ {
+		switch buf[p] {
+		case '\\':
+			p++
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				fill(buf[open+1 : p])
+				return p
+			}
+		}
+	}
+	return len(buf)
+}
+
+// blankXMPElement затирает содержимое элементов XMP вместе с вложенными тегами
+func blankXMPElement(buf, open, closeTag []byte) {
+	for from := 0; ; {
+		i := bytes.Index(buf[from:], open)
+		if i < 0 {
+			return
+		}
+		start := from + i
+		gt := bytes.IndexByte(buf[start:], '>')
+		if gt < 0 {
+			return
+		}
+		body := start + gt + 1
+		if buf[body-2] == '/' { // <dc:creator/>
+			from = body
+			continue
+		}
+		end := bytes.Index(buf[body:], closeTag)
+		if end < 0 {
+			return
+		}
+		fill(buf[body : body+end])
+		from = body + end + len(closeTag)
+	}
+}
+
+var (
+	pdfStream    = []byte("stream")
+	pdfEndstream = []byte("endstream")
+	pdfObj       = []byte("obj")
+)
+
+// checkCompressed ищет сведения об авторе в сжатых потоках объектов (/ObjStm)
+// и метаданных (/Metadata): их не затереть без пересборки файла, поэтому
+// найденный автор — ErrPDFMetadata. Поток, который не распаковать (другой
+// фильтр, предиктор, повреждён), тоже ErrPDFMetadata: проверить его нельзя.
+func checkCompressed(buf []byte) error {
+	for from := 0; ; {
+		i := bytes.Index(buf[from:], pdfStream)
+		if i < 0 {
+			return nil
+		}
+		kw := from + i
+		from = kw + len(pdfStream)
+		// «endstream» и «stream» внутри имён — не начало потока
+		if kw > 0 && isRegular(buf[kw-1]) {
+			continue
+		}
+		data := from
+		if data < len(buf) && buf[data] == '\r' {
+			data++
+		}
+		if data >= len(buf) || buf[data] != '\n' {
+			continue
+		}
+		data++
+
+		end := bytes.Index(buf[data:], pdfEndstream)
+		if end < 0 {
+			end = len(buf) - data
+		}
+		from = data + end
+
+		// словарь потока — от «obj» (или «endobj» предыдущего объекта) до stream
+		objAt := bytes.LastIndex(buf[:kw], pdfObj)
+		if objAt < 0 {
+			continue
+		}
+		dict := buf[objAt:kw]
+		objStm := hasName(dict, "/ObjStm")
+		// сам поток метаданных, а не объект со ссылкой /Metadata N 0 R на него
+		xmp := hasEntry(dict, "/Type", "/Metadata") || hasEntry(dict, "/Subtype", "/XML")
+		if !objStm && !xmp {
+			continue
+		}
+		if !bytes.Contains(dict, []byte("/Filter")) {
+			continue // не сжат: уже обработан в общем проходе
+		}
+		content, err := inflate(dict, buf[data:data+end])
+		if err != nil {
+			return ErrPDFMetadata
+		}
+		if objStm && hasAuthor(content) || xmp && hasXMPCreator(content) {
+			return ErrPDFMetadata
+		}
+	}
+}
+
+// inflate распаковывает поток с единственным фильтром FlateDecode без предиктора
+func inflate(dict, data []byte) ([]byte, error) {
+	if !hasName(dict, "/FlateDecode") || bytes.Count(dict, []byte("Decode")) > 1 ||
+		bytes.Contains(dict, []byte("/Predictor")) {
+		return nil, errors.New("unsupported filter")
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxInflated+1))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if len(out) > maxInflated {
+		return nil, errors.New("stream too large")
+	}
+	return out, nil
+}
+
+// hasName — в b есть имя name целиком (не префикс другого имени)
+func hasName(b []byte, name string) bool {
+	for from := 0; ; {
+		i := bytes.Index(b[from:], []byte(name))
+		if i < 0 {
+			return false
+		}
+		p := from + i + len(name)
+		if p >= len(b) || !isRegular(b[p]) {
+			return true
+		}
+		from = p
+	}
+}
+
+// hasEntry — в словаре b есть пара key value, где value — имя: /Type /Metadata
+func hasEntry(b []byte, key, value string) bool {
+	for from := 0; from < len(b); {
+		i := bytes.Index(b[from:], []byte(key))
+		if i < 0 {
+			return false
+		}
+		p := from + i + len(key)
+		from = p
+		if p < len(b) && isRegular(b[p]) {
+			continue // префикс другого имени
+		}
+		for p < len(b) && isSpace(b[p]) {
+			p++
+		}
+		if rest := b[p:]; bytes.HasPrefix(rest, []byte(value)) &&
+			(len(rest) == len(value) || !isRegular(rest[len(value)])) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAuthor — в распакованных объектах есть непустой /Author
+func hasAuthor(b []byte) bool {
+	probe := append([]byte(nil), b...)
+	for _, key := range pdfInfoKeys {
+		if blankStringValues(probe, key) != nil {
+			return true
+		}
+	}
+	// затирание что-то изменило — значение было непустым
+	return !bytes.Equal(probe, b)
+}
+
+// hasXMPCreator — в XMP есть непустой dc:creator
+func hasXMPCreator(b []byte) bool {
+	probe := append([]byte(nil), b...)
+	blankXMPElement(probe, xmpCreator, xmpCreatorEnd)
+	return !bytes.Equal(probe, b)
+}
+
+func fill(b []byte) {
+	for i := range b {
+		b[i] = ' '
+	}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+// isRegular — символ, продолжающий имя PDF (не пробел и не разделитель)
+func isRegular(c byte) bool {
+	if isSpace(c) {
+		return false
+	}
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return false
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package blind
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func deflate(s string) string {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	_, _ = zw.Write([]byte(s))
+	zw.Close()
+	return b.String()
+}
+
+func scrub(t *testing.T, pdf string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := scrubPDF(strings.NewReader(pdf), int64(len(pdf)), &out)
+	return out.String(), err
+}
+
+const xmp = `<x:xmpmeta><rdf:RDF><rdf:Description><dc:creator><rdf:Seq><rdf:li>Иванов И. И.</rdf:li></rdf:Seq></dc:creator></rdf:Description></rdf:RDF></x:xmpmeta>`
+
+func TestScrubPDFBlanksInPlace(t *testing.T) {
+	in := "%PDF-1.4\n1 0 obj\n<< /Title (Статья) /Author (Иванов \\(И.\\)) /AuthorX (keep) >>\nendobj\n" +
+		"2 0 obj\n<< /Author <49766116e6f76> >>\nendobj\n" +
+		"3 0 obj\n<< /Type /Metadata /Subtype /XML /Length 160 >>\nstream\n" + xmp + "\nendstream\nendobj\n%%EOF\n"
+	out, err := scrub(t, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("length changed: %d -> %d (xref offsets would break)", len(in), len(out))
+	}
+	for _, leak := range []string{"Иванов", "49766116e6f76"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("output still contains %q", leak)
+		}
+	}
+	for _, keep := range []string{"(Статья)", "/AuthorX (keep)", "<dc:creator>", "</dc:creator>"} {
+		if !strings.Contains(out, keep) {
+			t.Errorf("output lost %q", keep)
+		}
+	}
+}
+
+func TestScrubPDFRefusesWhatItCannotBlank(t *testing.T) {
+	for _, tc := range []struct {
+		name, pdf string
+	}{
+		{"indirect author", "%PDF-1.4\n1 0 obj\n<< /Author 12 0 R >>\nendobj\n12 0 obj\n(Иванов)\nendobj\n"},
+		{"info in object stream", "%PDF-1.5\n5 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Filter /FlateDecode >>\nstream\n" +
+			deflate("7 0 << /Author (Иванов) >>") + "\nendstream\nendobj\n"},
+		{"compressed xmp", "%PDF-1.5\n3 0 obj\n<< /Type /Metadata /Subtype /XML /Filter /FlateDecode >>\nstream\n" +
+			deflate(xmp) + "\nendstream\nendobj\n"},
+		{"undecodable filter", "%PDF-1.5\n3 0 obj\n<< /Type /Metadata /Filter /LZWDecode >>\nstream\nxxxx\nendstream\nendobj\n"},
+		{"predictor", "%PDF-1.5\n5 0 obj\n<< /Type /ObjStm /Filter /FlateDecode /DecodeParms << /Predictor 12 >> >>\nstream\n" +
+			deflate("7 0 << >>") + "\nendstream\nendobj\n"},
+		{"corrupt stream", "%PDF-1.5\n5 0 obj\n<< /Type /ObjStm /Filter /FlateDecode >>\nstream\nnot zlib\nendstream\nendobj\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := scrub(t, tc.pdf); !errors.Is(err, ErrPDFMetadata) {
+				t.Fatalf("err = %v, want ErrPDFMetadata", err)
+			}
+		})
+	}
+}
+
+func TestScrubPDFAcceptsCleanCompressedStreams(t *testing.T) {
+	in := "%PDF-1.5\n5 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Filter /FlateDecode >>\nstream\n" +
+		deflate("7 0 << /Title (Статья) /Author () >>") + "\nendstream\nendobj\n" +
+		"6 0 obj\n<< /Type /Metadata /Filter /FlateDecode >>\nstream\n" +
+		deflate("<x:xmpmeta><dc:title>T</dc:title><dc:creator/></x:xmpmeta>") + "\nendstream\nendobj\n" +
+		// сжатое содержимое страницы не проверяется
+		"8 0 obj\n<< /Filter /LZWDecode >>\nstream\nxxxx\nendstream\nendobj\n" +
+		// ссылка на метаданные — ещё не метаданные
+		"9 0 obj\n<< /Type /XObject /Subtype /Image /Metadata 6 0 R /Filter /DCTDecode >>\nstream\nxxxx\nendstream\nendobj\n"
+	out, err := scrub(t, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Error("clean file was modified")
+	}
+}
+
+func TestHasEntry(t *testing.T) {
+	for _, tc := range []struct {
+		dict string
+		want bool
+	}{
+		{"<< /Type /Metadata /Subtype /XML >>", true},
+		{"<</Type/Metadata>>", true},
+		{"<< /Type\n/Metadata\n>>", true},
+		{"<< /Metadata 6 0 R /Type /XObject >>", false},
+		{"<< /Type /MetadataX >>", false},
+		{"<< /TypeX /Metadata >>", false},
+		{"<< /Type /Page /Metadata 6 0 R >>", false},
+		{"<< /Type", false},
+	} {
+		if got := hasEntry([]byte(tc.dict), "/Type", "/Metadata"); got != tc.want {
+			t.Errorf("hasEntry(%q) = %v, want %v", tc.dict, got, tc.want)
+		}
+	}
+}
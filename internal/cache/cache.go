@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/internal/metrics"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// Кэш частых выборок перед БД (список сборников и т. п.). Значения — JSON,
+// поэтому хранилище не знает о типах: в памяти процесса (LRU) или в Redis,
+// общем для нескольких экземпляров приложения.
+//
+// Запись сбрасывается явно (Invalidate) хендлерами, меняющими данные, и в
+// любом случае живёт не дольше TTL — так ограничена и несвежесть из-за
+// гонки «прочитали из БД → кто-то изменил и сбросил → мы записали старое»,
+// и, при кэше в памяти, расхождение между экземплярами.
+//
+// Ключи — константы в коде, а не данные запроса: ключ — метка метрики.
+
+// Cache — хранилище байтовых значений со сроком жизни
+type Cache interface {
+	// Get — значение и true; нет записи или истёк срок — nil, false
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+var (
+	// Default — кэш приложения; до Init и при CACHE_BACKEND=none — Nop
+	Default Cache = Nop{}
+	// TTL — срок записи для Fetch
+	TTL = 5 * time.Minute
+)
+
+// Init выбирает хранилище. Redis проверяется при старте, но его
+// недоступность не мешает работе: Fetch тогда читает из БД.
+func Init(c config.Cache) {
+	TTL = c.TTL
+	switch c.Backend {
+	case "memory":
+		Default = NewLRU(c.Size)
+	case "redis":
+		r, err := NewRedis(c.RedisURL)
+		if err != nil {
+			slog.Error("cache: redis", "err", err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := r.Ping(ctx); err != nil {
+			slog.Warn("cache: redis is not reachable yet", "err", err)
+		}
+		Default = r
+	default:
+		Default = Nop{}
+	}
+}
+
+// Fetch — значение key из кэша, а при промахе — из load (с записью в кэш
+// на TTL). Ошибки кэша не ошибки запроса: пишутся в лог и метрику.
+func Fetch[T any](ctx context.Context, key string, load func(context.Context) (T, error)) (T, error) {
+	if data, ok, err := Default.Get(ctx, key); err != nil {
+		metrics.CacheRequests.WithLabelValues(key, "error").Inc()
+		slog.WarnContext(ctx, "cache: get", "key", key, "err", err)
+	} else if ok {
+		var v T
+		if err := json.Unmarshal(data, &v); err == nil {
+			metrics.CacheRequests.WithLabelValues(key, "hit").Inc()
+			return v, nil
+		}
+		// формат значения сменился с выкладкой — считаем промахом
+		metrics.CacheRequests.WithLabelValues(key, "miss").Inc()
+	} else {
+		metrics.CacheRequests.WithLabelValues(key, "miss").Inc()
+	}
+
+	v, err := load(ctx)
+	if err != nil {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		if err := Default.Set(ctx, key, data, TTL); err != nil {
+			slog.WarnContext(ctx, "cache: set", "key", key, "err", err)
+		}
+	}
+	return v, nil
+}
+
+// Invalidate сбрасывает ключи после изменения данных
+func Invalidate(ctx context.Context, keys ...string) {
+	if err := Default.Delete(ctx, keys...); err != nil {
+		slog.ErrorContext(ctx, "cache: invalidate", "keys", keys, "err", err)
+	}
+}
+
+// Nop — кэш выключен: всегда промах
+type Nop struct{}
+
+func (Nop) Get(context.Context, string) ([]byte, bool, error)        { return nil, false, nil }
+func (Nop) Set(context.Context, string, []byte, time.Duration) error { return nil }
+func (Nop) Delete(context.Context, ...string) error                  { return nil }
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), time.Minute)
+	// обращение к a делает вытесняемой b
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("a: miss right after Set")
+	}
+	_ = c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b: want evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, k); !ok {
+			t.Errorf("%s: want present", k)
+		}
+	}
+}
+
+func TestLRUSetExistingKeyDoesNotGrow(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "a", []byte("2"), time.Minute)
+	if c.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", c.Len())
+	}
+	if v, _, _ := c.Get(ctx, "a"); string(v) != "2" {
+		t.Errorf("a = %q, want 2", v)
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewLRU(10)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	now = now.Add(59 * time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("a: miss before TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("a: hit at TTL")
+	}
+	// истёкшая запись удаляется при чтении
+	if c.Len() != 0 {
+		t.Errorf("Len = %d, want 0", c.Len())
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), time.Minute)
+	_ = c.Delete(ctx, "a", "missing")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("a: want deleted")
+	}
+	if _, ok, _ := c.Get(ctx, "b"); !ok {
+		t.Error("b: want present")
+	}
+}
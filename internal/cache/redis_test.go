@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"BookCollect/internal/metrics"
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// fakeRedis — RESP-сервер в процессе: команды, которые шлёт клиент (AUTH,
+// SELECT, PING, GET, SET PX, DEL), с хранилищем в map. Запоминает команды.
+type fakeRedis struct {
+	addr     string
+	password string
+
+	mu   sync.Mutex
+	data map[string]string
+	ttl  map[string]string
+	cmds [][]string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{addr: ln.Addr().String(), password: password, data: map[string]string{}, ttl: map[string]string{}}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authed := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.cmds = append(f.cmds, args)
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authed = args[len(args)-1] == f.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "PING":
+			reply = "+PONG\r\n"
+		case cmd == "SELECT":
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				reply = "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+			} else {
+				reply = "$-1\r\n"
+			}
+		case cmd == "SET":
+			f.data[args[1]] = args[2]
+			if len(args) == 5 {
+				f.ttl[args[1]] = args[4]
+			}
+			reply = "+OK\r\n"
+		case cmd == "DEL":
+			n := 0
+			for _, k := range args[1:] {
+				if _, ok := f.data[k]; ok {
+					delete(f.data, k)
+					n++
+				}
+			}
+			reply = ":" + strconv.Itoa(n) + "\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		if _, err := io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, errors.New("bad command")
+	}
+	args := make([]string, n)
+	for i := range args {
+		l, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(l, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (f *fakeRedis) commands(name string) [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out [][]string
+	for _, c := range f.cmds {
+		if strings.EqualFold(c[0], name) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// counter — текущее значение счётчика метрики
+func counter(c prometheus.Counter) float64 {
+	var m dto.Metric
+	_ = c.Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+func TestRedisGetSetDelete(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	c, err := NewRedis("redis://:secret@" + f.addr + "/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, ok, err := c.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("Get on empty = %v, %v; want miss", ok, err)
+	}
+	// значение с CRLF и не-ASCII не ломает протокол
+	val := "строка\r\nс переводом"
+	if err := c.Set(ctx, "k", []byte(val), 90*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := c.Get(ctx, "k")
+	if err != nil || !ok || string(got) != val {
+		t.Fatalf("Get = %q, %v, %v; want %q", got, ok, err, val)
+	}
+	if err := c.Delete(ctx, "k", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.Get(ctx, "k"); ok {
+		t.Error("Get after Delete: want miss")
+	}
+
+	f.mu.Lock()
+	ttl := f.ttl[redisKeyPrefix+"k"]
+	f.mu.Unlock()
+	if ttl != "90000" {
+		t.Errorf("SET PX = %q, want 90000", ttl)
+	}
+	if sel := f.commands("SELECT"); len(sel) != 1 || sel[0][1] != "2" {
+		t.Errorf("SELECT = %v, want one SELECT 2 (connection reused)", sel)
+	}
+	if auth := f.commands("AUTH"); len(auth) != 1 {
+		t.Errorf("AUTH sent %d times, want 1", len(auth))
+	}
+}
+
+func TestRedisWrongPassword(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	c, _ := NewRedis("redis://:wrong@" + f.addr)
+	err := c.Ping(context.Background())
+	var rerr redisError
+	if !errors.As(err, &rerr) || !strings.HasPrefix(string(rerr), "WRONGPASS") {
+		t.Fatalf("Ping = %v, want WRONGPASS", err)
+	}
+}
+
+func TestNewRedisURL(t *testing.T) {
+	for _, tc := range []struct {
+		url, addr string
+		db        int
+		tls, err  bool
+	}{
+		{url: "redis://cache", addr: "cache:6379"},
+		{url: "redis://user:pw@cache:6380/3", addr: "cache:6380", db: 3},
+		{url: "rediss://cache:6379", addr: "cache:6379", tls: true},
+		{url: "http://cache", err: true},
+		{url: "redis://cache/x", err: true},
+	} {
+		c, err := NewRedis(tc.url)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: want error", tc.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.url, err)
+			continue
+		}
+		if c.addr != tc.addr || c.db != tc.db || c.useTLS != tc.tls {
+			t.Errorf("%s: addr=%s db=%d tls=%v", tc.url, c.addr, c.db, c.useTLS)
+		}
+	}
+}
+
+func TestFetchThroughRedis(t *testing.T) {
+	f := newFakeRedis(t, "")
+	c, _ := NewRedis("redis://" + f.addr)
+	old := Default
+	Default = c
+	t.Cleanup(func() { Default = old })
+
+	ctx := context.Background()
+	hits := counter(metrics.CacheRequests.WithLabelValues("fetch-test", "hit"))
+	loads := 0
+	load := func(context.Context) ([]int, error) { loads++; return []int{1, 2, 3}, nil }
+
+	for range 3 {
+		v, err := Fetch(ctx, "fetch-test", load)
+		if err != nil || len(v) != 3 {
+			t.Fatalf("Fetch = %v, %v", v, err)
+		}
+	}
+	Invalidate(ctx, "fetch-test")
+	_, _ = Fetch(ctx, "fetch-test", load)
+
+	if loads != 2 {
+		t.Errorf("load called %d times, want 2 (miss, 2 hits, invalidate, miss)", loads)
+	}
+	if d := counter(metrics.CacheRequests.WithLabelValues("fetch-test", "hit")) - hits; d != 2 {
+		t.Errorf("hits += %v, want 2", d)
+	}
+}
+
+func TestFetchRedisDown(t *testing.T) {
+	// порт закрыт: кэш недоступен, данные всё равно отдаются
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	c, _ := NewRedis("redis://" + addr)
+	old := Default
+	Default = c
+	t.Cleanup(func() { Default = old })
+
+	errs := counter(metrics.CacheRequests.WithLabelValues("down-test", "error"))
+	v, err := Fetch(context.Background(), "down-test", func(context.Context) (string, error) { return "db", nil })
+	if err != nil || v != "db" {
+		t.Fatalf("Fetch = %q, %v; want value from load", v, err)
+	}
+	if d := counter(metrics.CacheRequests.WithLabelValues("down-test", "error")) - errs; d != 1 {
+		t.Errorf("errors += %v, want 1", d)
+	}
+}
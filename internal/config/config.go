@@ -0,0 +1,544 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Настройки приложения. Источники по возрастанию приоритета:
+//  1. значения по умолчанию (Default);
+//  2. файл YAML или TOML (флаг -config или CONFIG_FILE), необязательный;
+//  3. переменные окружения (тег env) — имена прежние, docker-compose не меняется.
+//
+// Load проверяет итог целиком (Validate) — ошибки видны при старте, а не при
+// первом запросе. Секреты (тег secret) в Summary не выводятся.
+
+type Server struct {
+	Host              string        `yaml:"host" toml:"host" env:"HOST"`
+	Port              string        `yaml:"port" toml:"port" env:"PORT"`
+	PublicURL         string        `yaml:"public_url" toml:"public_url" env:"PUBLIC_URL"` // для ссылок в письмах; обязателен при SMTP_HOST
+	HTTPS             bool          `yaml:"https" toml:"https" env:"APP_HTTPS"`            // за HTTPS-прокси: Secure-куки, https-ссылки
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	HandlerTimeout    time.Duration `yaml:"handler_timeout" toml:"handler_timeout" env:"HTTP_HANDLER_TIMEOUT"` // контекст запроса; загрузки (multipart) не ограничиваются; 0 — выкл.
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+// Addr — адрес основного листенера
+func (s Server) Addr() string { return net.JoinHostPort(s.Host, s.Port) }
+
+// DB — URL (DATABASE_URL или POSTGRES_DSN) важнее отдельных полей
+type DB struct {
+	URL            string        `yaml:"url" toml:"url" env:"DATABASE_URL,POSTGRES_DSN" secret:"true"`
+	Host           string        `yaml:"host" toml:"host" env:"POSTGRES_HOST"`
+	Port           string        `yaml:"port" toml:"port" env:"POSTGRES_PORT"`
+	User           string        `yaml:"user" toml:"user" env:"POSTGRES_USER"`
+	Password       string        `yaml:"password" toml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	Name           string        `yaml:"name" toml:"name" env:"POSTGRES_DB"`
+	SSLMode        string        `yaml:"sslmode" toml:"sslmode" env:"POSTGRES_SSLMODE"`                   // локально disable; в проде обычно require/verify-full
+	ConnectTimeout time.Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"` // 0 — ждать бесконечно
+}
+
+// DSN — строка подключения для lib/pq
+func (d DB) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	parts := []string{
+		"host=" + d.Host,
+		"port=" + d.Port,
+		"user=" + d.User,
+		"dbname=" + d.Name,
+		"sslmode=" + d.SSLMode,
+	}
+	if d.Password != "" {
+		parts = append(parts, "password="+d.Password)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Target — куда подключаемся, без пароля (для логов)
+func (d DB) Target() string {
+	if d.URL != "" {
+		if u, err := url.Parse(d.URL); err == nil && u.Host != "" {
+			return "host=" + u.Host + " db=" + strings.TrimPrefix(u.Path, "/")
+		}
+		return "DATABASE_URL provided"
+	}
+	return fmt.Sprintf("host=%s user=%s db=%s", d.Host, d.User, d.Name)
+}
+
+type Session struct {
+	Secret string `yaml:"secret" toml:"secret" env:"SESSION_SECRET" secret:"true"`
+}
+
+type Uploads struct {
+	MaxSizeMB     int64    `yaml:"max_size_mb" toml:"max_size_mb" env:"UPLOAD_MAX_MB"`        // лимит рукописи
+	AllowedExt    []string `yaml:"allowed_ext" toml:"allowed_ext" env:"UPLOAD_ALLOWED_EXT"`   // .pdf,.docx,.odt
+	QuarantineDir string   `yaml:"quarantine_dir" toml:"quarantine_dir" env:"QUARANTINE_DIR"` // НЕ внутри uploads/
+}
+
+// MaxSize — лимит в байтах
+func (u Uploads) MaxSize() int64 { return u.MaxSizeMB << 20 }
+
+type Scanner struct {
+	ClamdAddr    string        `yaml:"clamd_addr" toml:"clamd_addr" env:"CLAMD_ADDR"` // пусто — проверка отключена
+	ClamdTimeout time.Duration `yaml:"clamd_timeout" toml:"clamd_timeout" env:"CLAMD_TIMEOUT"`
+}
+
+type Mail struct {
+	SMTPHost     string `yaml:"smtp_host" toml:"smtp_host" env:"SMTP_HOST"` // пусто — письма только в лог
+	SMTPPort     string `yaml:"smtp_port" toml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUser     string `yaml:"smtp_user" toml:"smtp_user" env:"SMTP_USER"`
+	SMTPPassword string `yaml:"smtp_password" toml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	SMTPTLS      string `yaml:"smtp_tls" toml:"smtp_tls" env:"SMTP_TLS"` // starttls | tls | none
+	From         string `yaml:"from" toml:"from" env:"MAIL_FROM"`
+}
+
+type Covers struct {
+	Autogen      bool   `yaml:"autogen" toml:"autogen" env:"COVER_AUTOGEN"`
+	PdftoppmPath string `yaml:"pdftoppm_path" toml:"pdftoppm_path" env:"PDFTOPPM_PATH"` // пусто — ищем в PATH
+	CwebpPath    string `yaml:"cwebp_path" toml:"cwebp_path" env:"CWEBP_PATH"`
+}
+
+type Review struct {
+	Mode string `yaml:"mode" toml:"mode" env:"REVIEW_MODE"` // double_blind | single_blind
+}
+
+type Stats struct {
+	DedupWindow time.Duration `yaml:"dedup_window" toml:"dedup_window" env:"STATS_DEDUP_WINDOW"`
+}
+
+type API struct {
+	RateLimit int `yaml:"rate_limit" toml:"rate_limit" env:"API_RATE_LIMIT"` // запросов в минуту для нового ключа
+}
+
+type Cache struct {
+	Backend  string        `yaml:"backend" toml:"backend" env:"CACHE_BACKEND"`               // memory | redis | none
+	Size     int           `yaml:"size" toml:"size" env:"CACHE_SIZE"`                        // записей в памяти (memory)
+	TTL      time.Duration `yaml:"ttl" toml:"ttl" env:"CACHE_TTL"`                           // срок записи, если её не сбросили раньше
+	RedisURL string        `yaml:"redis_url" toml:"redis_url" env:"REDIS_URL" secret:"true"` // redis://:пароль@host:6379/0 (rediss:// — TLS)
+}
+
+type Web struct {
+	Dir string `yaml:"dir" toml:"dir" env:"WEB_DIR"` // пусто — встроенные шаблоны и статика; каталог — с диска, с перечитыванием (разработка)
+}
+
+type Log struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`    // debug | info | warn | error
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"` // json | text
+}
+
+type Metrics struct {
+	Addr  string `yaml:"addr" toml:"addr" env:"METRICS_ADDR"` // отдельный листенер для /metrics
+	Token string `yaml:"token" toml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER,OTEL_TRACES_EXPORTER"` // none | stdout | otlp
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`           // http://collector:4318
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`          // доля корневых трасс, 0…1
+	ServiceName string  `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// Config — все настройки приложения
+type Config struct {
+	Server  Server  `yaml:"server" toml:"server"`
+	DB      DB      `yaml:"db" toml:"db"`
+	Session Session `yaml:"session" toml:"session"`
+	Uploads Uploads `yaml:"uploads" toml:"uploads"`
+	Scanner Scanner `yaml:"scanner" toml:"scanner"`
+	Mail    Mail    `yaml:"mail" toml:"mail"`
+	Covers  Covers  `yaml:"covers" toml:"covers"`
+	Review  Review  `yaml:"review" toml:"review"`
+	Stats   Stats   `yaml:"stats" toml:"stats"`
+	API     API     `yaml:"api" toml:"api"`
+	Web     Web     `yaml:"web" toml:"web"`
+	Cache   Cache   `yaml:"cache" toml:"cache"`
+	Metrics Metrics `yaml:"metrics" toml:"metrics"`
+	Log     Log     `yaml:"log" toml:"log"`
+	Tracing Tracing `yaml:"tracing" toml:"tracing"`
+}
+
+// Default — значения по умолчанию (локальная разработка)
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Host:              "127.0.0.1",
+			Port:              "8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			// тело — с запасом на рукопись до 25 МБ по медленному каналу
+			ReadTimeout:     5 * time.Minute,
+			WriteTimeout:    5 * time.Minute,
+			IdleTimeout:     2 * time.Minute,
+			HandlerTimeout:  30 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		DB: DB{
+			Host:           "127.0.0.1",
+			Port:           "5432",
+			User:           "postgres",
+			Name:           "BookCollect",
+			SSLMode:        "disable",
+			ConnectTimeout: 2 * time.Minute,
+		},
+		Uploads: Uploads{
+			MaxSizeMB:     25,
+			AllowedExt:    []string{".pdf", ".docx", ".odt"},
+			QuarantineDir: "quarantine",
+		},
+		Scanner: Scanner{ClamdTimeout: 30 * time.Second},
+		Mail:    Mail{SMTPPort: "587", SMTPTLS: "starttls"},
+		Covers:  Covers{Autogen: true},
+		Review:  Review{Mode: "double_blind"},
+		Stats:   Stats{DedupWindow: 30 * time.Second},
+		API:     API{RateLimit: 60},
+		Cache:   Cache{Backend: "memory", Size: 1000, TTL: 5 * time.Minute},
+		Log:     Log{Level: "info", Format: "json"},
+		Tracing: Tracing{Exporter: "none", SampleRatio: 1, ServiceName: "bookcollect"},
+	}
+}
+
+// Load собирает настройки: умолчания, файл path (или CONFIG_FILE), окружение
+func Load(path string) (*Config, error) {
+	c := Default()
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(c).Elem()); err != nil {
+		return nil, err
+	}
+	c.normalize()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true) // опечатка в ключе — ошибка, а не молча умолчание
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: %s: unknown keys: %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("config: %s: unsupported format (use .yaml, .yml or .toml)", path)
+	}
+	return nil
+}
+
+// applyEnv переносит заданные переменные окружения в поля с тегом env.
+// В теге может быть несколько имён через запятую — берётся первое заданное.
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Duration(0)) {
+			if err := applyEnv(fv); err != nil {
+				return err
+			}
+			continue
+		}
+		tag := f.Tag.Get("env")
+		if tag == "" {
+			continue
+		}
+		for _, name := range strings.Split(tag, ",") {
+			raw, ok := os.LookupEnv(name)
+			if !ok || raw == "" {
+				continue
+			}
+			if err := setField(fv, raw); err != nil {
+				return fmt.Errorf("config: %s=%q: %w", name, raw, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, raw string) error {
+	switch {
+	case fv.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("not a duration (e.g. 30s, 5m)")
+		}
+		fv.SetInt(int64(d))
+	case fv.Kind() == reflect.Bool:
+		b, ok := parseBool(raw)
+		if !ok {
+			return errors.New("not a boolean (1/0, true/false, on/off)")
+		}
+		fv.SetBool(b)
+	case fv.Kind() == reflect.Int64 || fv.Kind() == reflect.Int:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		fv.SetInt(n)
+	case fv.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		fv.SetFloat(f)
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		var list []string
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		fv.Set(reflect.ValueOf(list))
+	case fv.Kind() == reflect.String:
+		fv.SetString(raw)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "on", "yes":
+		return true, true
+	case "0", "false", "off", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// normalize приводит значения к каноническому виду
+func (c *Config) normalize() {
+	c.Server.PublicURL = strings.TrimRight(c.Server.PublicURL, "/")
+	c.Mail.SMTPTLS = strings.ToLower(c.Mail.SMTPTLS)
+	c.Log.Level = strings.ToLower(c.Log.Level)
+	c.Log.Format = strings.ToLower(c.Log.Format)
+	c.Tracing.Exporter = strings.ToLower(c.Tracing.Exporter)
+	c.Cache.Backend = strings.ToLower(c.Cache.Backend)
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = "none"
+	}
+	for i, ext := range c.Uploads.AllowedExt {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		c.Uploads.AllowedExt[i] = ext
+	}
+	if c.Mail.From == "" && c.Mail.SMTPHost != "" {
+		c.Mail.From = "noreply@" + c.Mail.SMTPHost
+	}
+}
+
+// manuscriptExt — форматы, которые умеют проверять filetype и blind
+var manuscriptExt = map[string]bool{".pdf": true, ".docx": true, ".odt": true}
+
+// Validate проверяет настройки целиком и возвращает все ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	bad := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if p, err := strconv.Atoi(c.Server.Port); err != nil || p < 1 || p > 65535 {
+		bad("server.port (PORT): %q is not a valid port", c.Server.Port)
+	}
+	if c.Server.PublicURL != "" {
+		if u, err := url.Parse(c.Server.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			bad("server.public_url (PUBLIC_URL): %q must be an absolute http(s) URL", c.Server.PublicURL)
+		}
+	}
+	for _, d := range []struct {
+		name string
+		val  time.Duration
+	}{
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.handler_timeout", c.Server.HandlerTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"db.connect_timeout", c.DB.ConnectTimeout},
+		{"scanner.clamd_timeout", c.Scanner.ClamdTimeout},
+		{"stats.dedup_window", c.Stats.DedupWindow},
+	} {
+		if d.val < 0 {
+			bad("%s: must not be negative", d.name)
+		}
+	}
+
+	// Без своего секрета куки подписываются общеизвестным ключом — в проде недопустимо
+	if c.Session.Secret == "" && c.Server.HTTPS {
+		bad("session.secret (SESSION_SECRET): required when APP_HTTPS=1")
+	} else if c.Session.Secret != "" && len(c.Session.Secret) < 16 {
+		bad("session.secret (SESSION_SECRET): at least 16 characters")
+	}
+
+	if c.Uploads.MaxSizeMB < 1 || c.Uploads.MaxSizeMB > 1024 {
+		bad("uploads.max_size_mb (UPLOAD_MAX_MB): %d is out of range 1..1024", c.Uploads.MaxSizeMB)
+	}
+	if len(c.Uploads.AllowedExt) == 0 {
+		bad("uploads.allowed_ext (UPLOAD_ALLOWED_EXT): at least one format")
+	}
+	for _, ext := range c.Uploads.AllowedExt {
+		if !manuscriptExt[ext] {
+			bad("uploads.allowed_ext (UPLOAD_ALLOWED_EXT): %q is not supported (pdf, docx, odt)", ext)
+		}
+	}
+	if c.Uploads.QuarantineDir == "" {
+		bad("uploads.quarantine_dir (QUARANTINE_DIR): must not be empty")
+	} else if q := filepath.Clean(c.Uploads.QuarantineDir); q == "uploads" || strings.HasPrefix(q, "uploads"+string(filepath.Separator)) {
+		bad("uploads.quarantine_dir (QUARANTINE_DIR): must be outside uploads/ (it is served publicly)")
+	}
+
+	switch c.Mail.SMTPTLS {
+	case "", "starttls", "tls", "none":
+	default:
+		bad("mail.smtp_tls (SMTP_TLS): %q, want starttls, tls or none", c.Mail.SMTPTLS)
+	}
+	if c.Mail.SMTPHost != "" {
+		if p, err := strconv.Atoi(c.Mail.SMTPPort); err != nil || p < 1 || p > 65535 {
+			bad("mail.smtp_port (SMTP_PORT): %q is not a valid port", c.Mail.SMTPPort)
+		}
+		// без него ссылки в письмах строились бы из Host запроса, который задаёт клиент
+		if c.Server.PublicURL == "" {
+			bad("server.public_url (PUBLIC_URL): required when mail is enabled (SMTP_HOST)")
+		}
+	}
+
+	switch c.Review.Mode {
+	case "double_blind", "single_blind":
+	default:
+		bad("review.mode (REVIEW_MODE): %q, want double_blind or single_blind", c.Review.Mode)
+	}
+
+	if c.API.RateLimit < 1 || c.API.RateLimit > 100000 {
+		bad("api.rate_limit (API_RATE_LIMIT): %d is out of range 1..100000", c.API.RateLimit)
+	}
+
+	if c.Web.Dir != "" {
+		for _, sub := range []string{"templates", "static"} {
+			if fi, err := os.Stat(filepath.Join(c.Web.Dir, sub)); err != nil || !fi.IsDir() {
+				bad("web.dir (WEB_DIR): %q has no %s/ directory", c.Web.Dir, sub)
+			}
+		}
+	}
+
+	switch c.Cache.Backend {
+	case "none":
+	case "memory":
+		if c.Cache.Size < 1 || c.Cache.Size > 1000000 {
+			bad("cache.size (CACHE_SIZE): %d is out of range 1..1000000", c.Cache.Size)
+		}
+	case "redis":
+		if u, err := url.Parse(c.Cache.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+			bad("cache.redis_url (REDIS_URL): required for CACHE_BACKEND=redis, want redis://[:password@]host:port[/db]")
+		}
+	default:
+		bad("cache.backend (CACHE_BACKEND): %q, want memory, redis or none", c.Cache.Backend)
+	}
+	if c.Cache.TTL <= 0 {
+		bad("cache.ttl (CACHE_TTL): must be positive")
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		bad("log.level (LOG_LEVEL): %q, want debug, info, warn or error", c.Log.Level)
+	}
+	switch c.Log.Format {
+	case "json", "text":
+	default:
+		bad("log.format (LOG_FORMAT): %q, want json or text", c.Log.Format)
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		bad("tracing.exporter (TRACING_EXPORTER): %q, want none, stdout or otlp", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		bad("tracing.sample_ratio (TRACING_SAMPLE_RATIO): %v, want 0…1", c.Tracing.SampleRatio)
+	}
+	if c.Tracing.Endpoint != "" {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			bad("tracing.endpoint (OTEL_EXPORTER_OTLP_ENDPOINT): %q, want http(s)://host:port", c.Tracing.Endpoint)
+		}
+	}
+
+	if c.Metrics.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Addr); err != nil {
+			bad("metrics.addr (METRICS_ADDR): %q, want host:port", c.Metrics.Addr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Summary — настройки построчно для лога при старте; секреты скрыты
+func (c *Config) Summary() []string {
+	var lines []string
+	summarize(reflect.ValueOf(c).Elem(), "", &lines)
+	return lines
+}
+
+func summarize(v reflect.Value, prefix string, lines *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		name := prefix + f.Tag.Get("yaml")
+		if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Duration(0)) {
+			summarize(fv, name+".", lines)
+			continue
+		}
+		val := fmt.Sprint(fv.Interface())
+		if f.Tag.Get("secret") == "true" {
+			val = redact(val)
+		}
+		*lines = append(*lines, name+" = "+val)
+	}
+}
+
+// redact — секрет не показываем, только факт, что он задан
+func redact(s string) string {
+	if s == "" {
+		return "(empty)"
+	}
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.Redacted()
+	}
+	return "***"
+}
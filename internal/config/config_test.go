@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateMailRequiresPublicURL(t *testing.T) {
+	c := Default()
+	c.Mail.SMTPHost = "smtp.example.org"
+	c.Mail.SMTPPort = "587"
+	err := c.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PUBLIC_URL") {
+		t.Fatalf("Validate = %v, want PUBLIC_URL required", err)
+	}
+
+	c.Server.PublicURL = "https://journal.example.org"
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate with PUBLIC_URL = %v", err)
+	}
+
+	// без почты адрес не обязателен
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Validate(Default()) = %v", err)
+	}
+}
+
+// clearEnv — все переменные из тегов env пустые (applyEnv пустые пропускает),
+// чтобы окружение машины не влияло на тест
+func clearEnv(t *testing.T) {
+	t.Helper()
+	var walk func(reflect.Type)
+	walk = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(f.Type)
+				continue
+			}
+			for _, name := range strings.Split(f.Tag.Get("env"), ",") {
+				if name != "" {
+					t.Setenv(name, "")
+				}
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}))
+	t.Setenv("CONFIG_FILE", "")
+}
+
+func writeFile(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	for name, body := range map[string]string{
+		"config.yaml": `
+server:
+  port: "8081"
+  handler_timeout: 45s
+uploads:
+  allowed_ext: [PDF, docx]
+api:
+  rate_limit: 120
+`,
+		"config.toml": `
+[server]
+port = "8081"
+handler_timeout = "45s"
+
+[uploads]
+allowed_ext = ["PDF", "docx"]
+
+[api]
+rate_limit = 120
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			c, err := Load(writeFile(t, name, body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Server.Port != "8081" || c.Server.HandlerTimeout != 45*time.Second || c.API.RateLimit != 120 {
+				t.Errorf("port = %q, handler_timeout = %v, rate_limit = %d", c.Server.Port, c.Server.HandlerTimeout, c.API.RateLimit)
+			}
+			if got := c.Uploads.AllowedExt; !slices.Equal(got, []string{".pdf", ".docx"}) {
+				t.Errorf("allowed_ext = %q", got)
+			}
+			// чего нет в файле — по умолчанию
+			if c.Server.Host != "127.0.0.1" || c.Cache.Size != 1000 {
+				t.Errorf("defaults lost: host = %q, cache.size = %d", c.Server.Host, c.Cache.Size)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "config.yaml", "server:\n  port: \"8081\"\napi:\n  rate_limit: 120\n")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "9090")
+	t.Setenv("HTTP_HANDLER_TIMEOUT", "1m")
+	t.Setenv("UPLOAD_ALLOWED_EXT", "pdf, ,odt")
+	t.Setenv("POSTGRES_DSN", "postgres://dsn") // второе имя поля db.url
+
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != "9090" || c.Server.HandlerTimeout != time.Minute {
+		t.Errorf("port = %q, handler_timeout = %v: env did not override", c.Server.Port, c.Server.HandlerTimeout)
+	}
+	if c.API.RateLimit != 120 {
+		t.Errorf("rate_limit = %d: file value lost", c.API.RateLimit)
+	}
+	if got := c.Uploads.AllowedExt; !slices.Equal(got, []string{".pdf", ".odt"}) {
+		t.Errorf("allowed_ext = %q", got)
+	}
+	if c.DB.URL != "postgres://dsn" {
+		t.Errorf("db.url = %q", c.DB.URL)
+	}
+
+	t.Setenv("DATABASE_URL", "postgres://url") // первое имя важнее
+	if c, err := Load(""); err != nil || c.DB.URL != "postgres://url" {
+		t.Errorf("db.url = %q, %v", c.DB.URL, err)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, body string
+		env              map[string]string
+		want             string // фрагмент ошибки
+	}{
+		{name: "yaml unknown key", file: "c.yaml", body: "server:\n  prot: 80\n", want: "prot"},
+		{name: "yaml unknown section", file: "c.yaml", body: "sever:\n  port: 80\n", want: "sever"},
+		{name: "toml unknown key", file: "c.toml", body: "[server]\nprot = \"80\"\n", want: "unknown keys"},
+		{name: "unsupported format", file: "c.json", body: "{}", want: "unsupported format"},
+		{name: "yaml bad duration", file: "c.yaml", body: "stats:\n  dedup_window: soon\n", want: "c.yaml"},
+		{name: "toml bad int", file: "c.toml", body: "[api]\nrate_limit = \"many\"\n", want: "c.toml"},
+		{name: "env duration without unit", env: map[string]string{"STATS_DEDUP_WINDOW": "30"}, want: "STATS_DEDUP_WINDOW"},
+		{name: "env bad int", env: map[string]string{"API_RATE_LIMIT": "many"}, want: "API_RATE_LIMIT"},
+		{name: "env bad bool", env: map[string]string{"APP_HTTPS": "maybe"}, want: "APP_HTTPS"},
+		{name: "env bad float", env: map[string]string{"TRACING_SAMPLE_RATIO": "half"}, want: "TRACING_SAMPLE_RATIO"},
+		{name: "invalid value", env: map[string]string{"LOG_LEVEL": "loud"}, want: "LOG_LEVEL"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			path := ""
+			if tc.file != "" {
+				path = writeFile(t, tc.file, tc.body)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Load = %v, want error with %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestSummaryRedactsSecrets(t *testing.T) {
+	c := Default()
+	c.Session.Secret = "session-s3cret"
+	c.Mail.SMTPPassword = "smtp-s3cret"
+	c.Metrics.Token = "metrics-s3cret"
+	c.Cache.RedisURL = "redis://:redis-s3cret@cache:6379/0"
+	c.DB.URL = "postgres://app:db-s3cret@db:5432/app"
+
+	lines := c.Summary()
+	all := strings.Join(lines, "\n")
+	if strings.Contains(all, "s3cret") {
+		t.Fatalf("summary leaks a secret:\n%s", all)
+	}
+	for _, want := range []string{
+		"session.secret = ***",
+		"mail.smtp_password = ***",
+		"metrics.token = ***",
+		"cache.redis_url = redis://:xxxxx@cache:6379/0",
+		"db.url = postgres://app:xxxxx@db:5432/app",
+		"db.password = (empty)",
+		"server.port = 8080",
+	} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("summary has no line %q", want)
+		}
+	}
+}
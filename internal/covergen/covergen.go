@@ -0,0 +1,71 @@
+package covergen
+
+import (
+	"context"
+	"errors"
+	"image"
+	"log/slog"
+	"os/exec"
+
+	"BookCollect/internal/config"
+)
+
+// Info — данные сборника, из которых строится обложка
+type Info struct {
+	Title         string
+	ReleaseYear   *int32
+	ReleaseNumber *int32
+}
+
+// PageRenderer растеризует первую страницу PDF. Реализация подключаемая:
+// по умолчанию — pdftoppm из poppler-utils, если он установлен.
+type PageRenderer interface {
+	RenderFirstPage(ctx context.Context, pdfPath string) (image.Image, error)
+}
+
+var (
+	// Enabled — генерировать обложку, если сборник создан без неё
+	Enabled = true
+	// Renderer — растеризатор PDF; nil — сразу типографская обложка
+	Renderer PageRenderer
+)
+
+var ErrNoRenderer = errors.New("covergen: no pdf renderer")
+
+// Init настраивает генерацию:
+//
+//	Autogen      — false отключает генерацию обложек
+//	PdftoppmPath — путь к pdftoppm (иначе ищем в PATH)
+func Init(c config.Covers) {
+	if !c.Autogen {
+		Enabled = false
+		slog.Info("covergen: disabled")
+		return
+	}
+
+	path := c.PdftoppmPath
+	if path == "" {
+		if p, err := exec.LookPath("pdftoppm"); err == nil {
+			path = p
+		}
+	}
+	if path == "" {
+		slog.Warn("covergen: pdftoppm not found, using typographic covers")
+		return
+	}
+	Renderer = &Pdftoppm{Path: path, Width: 1280}
+	slog.Info("covergen: rendering pdf covers", "pdftoppm", path)
+}
+
+// Generate возвращает обложку для сборника: первую страницу PDF, если есть PDF
+// и растеризатор, иначе — типографскую обложку из названия, года и номера.
+func Generate(ctx context.Context, pdfPath string, info Info) image.Image {
+	if pdfPath != "" && Renderer != nil {
+		img, err := Renderer.RenderFirstPage(ctx, pdfPath)
+		if err == nil {
+			return img
+		}
+		slog.WarnContext(ctx, "covergen: render failed, falling back to typographic", "pdf", pdfPath, "err", err)
+	}
+	return Typographic(info)
+}
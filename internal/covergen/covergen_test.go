@@ -0,0 +1,126 @@
+package covergen
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"BookCollect/internal/config"
+)
+
+type fakeRenderer struct {
+	img   image.Image
+	err   error
+	calls int
+}
+
+func (f *fakeRenderer) RenderFirstPage(context.Context, string) (image.Image, error) {
+	f.calls++
+	return f.img, f.err
+}
+
+// withGlobals восстанавливает Enabled и Renderer после теста
+func withGlobals(t *testing.T) {
+	enabled, renderer := Enabled, Renderer
+	t.Cleanup(func() { Enabled, Renderer = enabled, renderer })
+}
+
+func TestGenerateChoosesRenderer(t *testing.T) {
+	withGlobals(t)
+	page := image.NewRGBA(image.Rect(0, 0, 10, 14))
+	isTypographic := func(img image.Image) bool {
+		return img.Bounds() == image.Rect(0, 0, coverW, coverH)
+	}
+
+	r := &fakeRenderer{img: page}
+	Renderer = r
+	if img := Generate(context.Background(), "c.pdf", Info{Title: "T"}); img != page {
+		t.Error("PDF page not used")
+	}
+	if img := Generate(context.Background(), "", Info{Title: "T"}); !isTypographic(img) || r.calls != 1 {
+		t.Errorf("no PDF: typographic = %v, renderer calls = %d", isTypographic(img), r.calls)
+	}
+
+	Renderer = &fakeRenderer{err: errors.New("broken pdf")}
+	if img := Generate(context.Background(), "c.pdf", Info{Title: "T"}); !isTypographic(img) {
+		t.Error("render error: no typographic fallback")
+	}
+
+	Renderer = nil
+	if img := Generate(context.Background(), "c.pdf", Info{Title: "T"}); !isTypographic(img) {
+		t.Error("no renderer: no typographic cover")
+	}
+}
+
+func TestInitRenderer(t *testing.T) {
+	withGlobals(t)
+
+	Enabled, Renderer = true, nil
+	Init(config.Covers{Autogen: false})
+	if Enabled {
+		t.Error("Autogen=false left generation enabled")
+	}
+
+	Enabled, Renderer = true, nil
+	Init(config.Covers{Autogen: true, PdftoppmPath: "/opt/poppler/bin/pdftoppm"})
+	if p, ok := Renderer.(*Pdftoppm); !ok || p.Path != "/opt/poppler/bin/pdftoppm" || p.Width != 1280 {
+		t.Errorf("Renderer = %#v, want pdftoppm from config", Renderer)
+	}
+
+	Enabled, Renderer = true, nil
+	t.Setenv("PATH", t.TempDir())
+	Init(config.Covers{Autogen: true})
+	if Renderer != nil || !Enabled {
+		t.Errorf("no pdftoppm: Renderer = %#v, Enabled = %v", Renderer, Enabled)
+	}
+}
+
+// fakePdftoppm — скрипт вместо pdftoppm: пишет аргументы в args и кладёт
+// заранее готовый PNG туда, куда попросили
+func fakePdftoppm(t *testing.T, body string) (path, args string) {
+	t.Helper()
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.png")
+	f, err := os.Create(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 8, 11))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	args = filepath.Join(dir, "args")
+	path = filepath.Join(dir, "pdftoppm")
+	script := "#!/bin/sh\necho \"$@\" > '" + args + "'\nPAGE='" + page + "'\n" + body
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path, args
+}
+
+func TestPdftoppm(t *testing.T) {
+	path, argsFile := fakePdftoppm(t, `for a; do out=$a; done; cp "$PAGE" "$out.png"`)
+	img, err := (&Pdftoppm{Path: path, Width: 640}).RenderFirstPage(context.Background(), "in.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 11 {
+		t.Errorf("bounds = %v", b)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.HasPrefix(string(args), "-png -singlefile -f 1 -l 1 -scale-to-x 640 -scale-to-y -1 in.pdf ") {
+		t.Errorf("pdftoppm args = %q", args)
+	}
+
+	path, _ = fakePdftoppm(t, "echo 'Syntax Error: broken xref' >&2; exit 1")
+	_, err = (&Pdftoppm{Path: path, Width: 640}).RenderFirstPage(context.Background(), "in.pdf")
+	if err == nil || !strings.Contains(err.Error(), "broken xref") {
+		t.Errorf("err = %v, want pdftoppm stderr", err)
+	}
+}
@@ -0,0 +1,51 @@
+package covergen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Pdftoppm — растеризация через poppler-utils:
+//
+//	pdftoppm -png -singlefile -f 1 -l 1 -scale-to-x W -scale-to-y -1 in.pdf out
+type Pdftoppm struct {
+	Path  string
+	Width int
+}
+
+func (p *Pdftoppm) RenderFirstPage(ctx context.Context, pdfPath string) (image.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tmp, err := os.MkdirTemp("", "covergen-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	out := filepath.Join(tmp, "page")
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path,
+		"-png", "-singlefile", "-f", "1", "-l", "1",
+		"-scale-to-x", strconv.Itoa(p.Width), "-scale-to-y", "-1",
+		pdfPath, out)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftoppm: %w: %s", err, stderr.String())
+	}
+
+	f, err := os.Open(out + ".png")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
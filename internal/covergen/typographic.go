@@ -0,0 +1,164 @@
+package covergen
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"BookCollect/internal/models"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Размер типографской обложки (пропорции A4)
+const (
+	coverW = 1240
+	coverH = 1754
+	margin = 110
+)
+
+// Палитра фонов: выбирается по хешу названия, чтобы обложки разных выпусков различались
+var palette = []color.RGBA{
+	{0x1e, 0x3a, 0x5f, 0xff},
+	{0x3b, 0x2f, 0x5c, 0xff},
+	{0x1f, 0x4e, 0x4a, 0xff},
+	{0x5c, 0x2a, 0x2a, 0xff},
+	{0x2d, 0x3e, 0x50, 0xff},
+}
+
+var accent = color.RGBA{0xe8, 0xc5, 0x6a, 0xff}
+
+// Название: длинное набирается мельче, а что не влезло и в maxTitleLines
+// строк самого мелкого кегля — обрезается многоточием. Так оно не наезжает
+// на номер выпуска внизу.
+var titleSizes = []float64{84, 68, 56}
+
+const maxTitleLines = 7
+
+// Typographic рисует обложку без внешних зависимостей: шрифты Go (с кириллицей)
+// встроены в x/image.
+func Typographic(info Info) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, coverW, coverH))
+	draw.Draw(img, img.Bounds(), &image.Uniform{pickColor(info.Title)}, image.Point{}, draw.Src)
+
+	// Полосы-акценты сверху и снизу
+	draw.Draw(img, image.Rect(margin, margin, coverW-margin, margin+8), &image.Uniform{accent}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(margin, coverH-margin-8, coverW-margin, coverH-margin), &image.Uniform{accent}, image.Point{}, draw.Src)
+
+	series := face(goregular.TTF, 36)
+	title, lines, lineH := layoutTitle(info.Title, coverW-2*margin)
+	meta := face(goregular.TTF, 48)
+	defer series.Close()
+	defer title.Close()
+	defer meta.Close()
+
+	y := margin + 80
+	for _, line := range wrap(series, strings.ToUpper(models.JournalTitle), coverW-2*margin) {
+		drawText(img, series, accent, margin, y, line)
+		y += 50
+	}
+
+	y = coverH / 3
+	for _, line := range lines {
+		drawText(img, title, color.White, margin, y, line)
+		y += lineH
+	}
+
+	var parts []string
+	if info.ReleaseNumber != nil {
+		parts = append(parts, fmt.Sprintf("Выпуск № %d", *info.ReleaseNumber))
+	}
+	if info.ReleaseYear != nil {
+		parts = append(parts, fmt.Sprintf("%d", *info.ReleaseYear))
+	}
+	if len(parts) > 0 {
+		drawText(img, meta, accent, margin, coverH-margin-60, strings.Join(parts, " · "))
+	}
+	return img
+}
+
+func pickColor(s string) color.RGBA {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+func face(ttf []byte, size float64) font.Face {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err) // встроенный шрифт — не может не разобраться
+	}
+	fc, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	return fc
+}
+
+func drawText(dst draw.Image, f font.Face, c color.Color, x, y int, s string) {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: f, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// layoutTitle подбирает кегль названия (titleSizes) и разбивает его на строки
+// не длиннее width и не больше maxTitleLines; lineH — шаг строк
+func layoutTitle(s string, width int) (f font.Face, lines []string, lineH int) {
+	for i, size := range titleSizes {
+		f = face(gobold.TTF, size)
+		lines = wrap(f, s, width)
+		if len(lines) <= maxTitleLines || i == len(titleSizes)-1 {
+			lineH = int(size * 1.24)
+			break
+		}
+		f.Close()
+	}
+	if len(lines) > maxTitleLines {
+		lines = lines[:maxTitleLines]
+		lines[maxTitleLines-1] = ellipsize(f, lines[maxTitleLines-1]+"…", width)
+	}
+	// слово длиннее строки
+	for i, line := range lines {
+		if font.MeasureString(f, line).Ceil() > width {
+			lines[i] = ellipsize(f, line, width)
+		}
+	}
+	return f, lines, lineH
+}
+
+// ellipsize укорачивает строку с многоточием на конце до width пикселей
+func ellipsize(f font.Face, s string, width int) string {
+	r := []rune(strings.TrimSuffix(s, "…"))
+	for len(r) > 0 && font.MeasureString(f, string(r)+"…").Ceil() > width {
+		r = r[:len(r)-1]
+	}
+	return strings.TrimRight(string(r), " ,.;:—-") + "…"
+}
+
+// wrap разбивает текст по словам так, чтобы строка помещалась в width пикселей
+func wrap(f font.Face, s string, width int) []string {
+	var lines []string
+	var cur string
+	for _, word := range strings.Fields(s) {
+		next := word
+		if cur != "" {
+			next = cur + " " + word
+		}
+		if cur != "" && font.MeasureString(f, next).Ceil() > width {
+			lines = append(lines, cur)
+			cur = word
+			continue
+		}
+		cur = next
+	}
+	if cur != "" {
+		lines = append(lines, cur)
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package covergen
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+func TestWrapFitsWidth(t *testing.T) {
+	f := face(gobold.TTF, 84)
+	defer f.Close()
+	const width = coverW - 2*margin
+
+	text := "Судебно-медицинская экспертиза вреда здоровью при сочетанной травме"
+	lines := wrap(f, text, width)
+	if len(lines) < 2 {
+		t.Fatalf("lines = %q, want the title wrapped", lines)
+	}
+	if got := strings.Join(lines, " "); got != text {
+		t.Errorf("wrap lost words: %q", got)
+	}
+	for _, line := range lines {
+		if w := font.MeasureString(f, line).Ceil(); w > width {
+			t.Errorf("line %q is %d px, wider than %d", line, w, width)
+		}
+	}
+	if lines := wrap(f, "   ", width); len(lines) != 0 {
+		t.Errorf("blank title: %q", lines)
+	}
+}
+
+func TestLayoutTitleFitsCover(t *testing.T) {
+	const width = coverW - 2*margin
+	// низ последней строки названия — выше строки с номером выпуска
+	metaTop := coverH - margin - 60 - 48
+
+	for _, tc := range []struct {
+		name, title string
+		size        float64 // ожидаемый кегль
+		ellipsis    bool
+	}{
+		{"short", "Выпуск 12", 84, false},
+		{"long", strings.Repeat("вопросы права и медицины ", 5), 68, false},
+		{"very long", strings.Repeat("Судебно-медицинская экспертиза ", 40), 56, true},
+		{"long word", "Электрокардиографическиисследованныйпациентпослетравмы", 84, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, lines, lineH := layoutTitle(tc.title, width)
+			defer f.Close()
+
+			if len(lines) == 0 || len(lines) > maxTitleLines {
+				t.Fatalf("%d lines, want 1…%d", len(lines), maxTitleLines)
+			}
+			if want := int(tc.size * 1.24); lineH != want {
+				t.Errorf("line height = %d, want %d (size %v)", lineH, want, tc.size)
+			}
+			if bottom := coverH/3 + (len(lines)-1)*lineH + f.Metrics().Descent.Ceil(); bottom > metaTop {
+				t.Errorf("title ends at %d px, overlaps the issue line at %d", bottom, metaTop)
+			}
+			for _, line := range lines {
+				if w := font.MeasureString(f, line).Ceil(); w > width {
+					t.Errorf("line %q is %d px, wider than %d", line, w, width)
+				}
+			}
+			if got := strings.HasSuffix(lines[len(lines)-1], "…"); got != tc.ellipsis {
+				t.Errorf("last line %q: ellipsis = %v, want %v", lines[len(lines)-1], got, tc.ellipsis)
+			}
+		})
+	}
+}
+
+func TestTypographicSize(t *testing.T) {
+	year, number := int32(2025), int32(3)
+	img := Typographic(Info{Title: strings.Repeat("Очень длинное название ", 50), ReleaseYear: &year, ReleaseNumber: &number})
+	if b := img.Bounds(); b.Dx() != coverW || b.Dy() != coverH {
+		t.Errorf("bounds = %v", b)
+	}
+}
@@ -0,0 +1,136 @@
+// Package dbtest — SQL-драйвер в памяти для тестов: ответы задаются заранее
+// по фрагменту текста запроса, Postgres не нужен.
+//
+//	fake := dbtest.New()
+//	fake.On("FROM collections", dbtest.Result{Columns: cols, Rows: rows})
+//	db.DB = fake.Open()
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Result — ответ на запрос: строки (Query) или число затронутых (Exec)
+type Result struct {
+	Columns  []string
+	Rows     [][]driver.Value
+	Affected int64
+	Err      error
+}
+
+type rule struct {
+	match string
+	res   Result
+}
+
+// Fake — «база»: правила ответов и журнал выполненных запросов
+type Fake struct {
+	mu      sync.Mutex
+	rules   []rule
+	queries []string
+}
+
+func New() *Fake { return &Fake{} }
+
+// On — ответ на запросы, содержащие match; правило, заданное позже, важнее
+func (f *Fake) On(match string, res Result) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, rule{match: match, res: res})
+}
+
+// Queries — тексты выполненных запросов по порядку
+func (f *Fake) Queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+// Open — *sql.DB поверх Fake
+func (f *Fake) Open() *sql.DB { return sql.OpenDB(f.Connector()) }
+
+// Connector — для обёрток над драйвером (трассировка и т. п.)
+func (f *Fake) Connector() driver.Connector { return connector{f} }
+
+func (f *Fake) answer(query string) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, query)
+	for i := len(f.rules) - 1; i >= 0; i-- {
+		if strings.Contains(query, f.rules[i].match) {
+			return f.rules[i].res, f.rules[i].res.Err
+		}
+	}
+	return Result{}, fmt.Errorf("dbtest: unexpected query: %s", strings.Join(strings.Fields(query), " "))
+}
+
+type connector struct{ f *Fake }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &Conn{f: c.f}, nil }
+func (c connector) Driver() driver.Driver                        { return drv{c.f} }
+
+type drv struct{ f *Fake }
+
+func (d drv) Open(string) (driver.Conn, error) { return &Conn{f: d.f}, nil }
+
+// Conn — соединение Fake; реализует те же интерфейсы, что и соединение lib/pq
+type Conn struct{ f *Fake }
+
+var errNoPrepare = errors.New("dbtest: prepared statements are not supported")
+
+func (c *Conn) Prepare(string) (driver.Stmt, error) { return nil, errNoPrepare }
+func (c *Conn) PrepareContext(context.Context, string) (driver.Stmt, error) {
+	return nil, errNoPrepare
+}
+func (c *Conn) Close() error                                                 { return nil }
+func (c *Conn) Begin() (driver.Tx, error)                                    { return tx{}, nil }
+func (c *Conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return tx{}, nil }
+func (c *Conn) Ping(context.Context) error                                   { return nil }
+func (c *Conn) ResetSession(context.Context) error                           { return nil }
+func (c *Conn) IsValid() bool                                                { return true }
+
+func (c *Conn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	res, err := c.f.answer(query)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{cols: res.Columns, data: res.Rows}, nil
+}
+
+func (c *Conn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	res, err := c.f.answer(query)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(res.Affected), nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type rows struct {
+	cols []string
+	data [][]driver.Value
+	i    int
+}
+
+func (r *rows) Columns() []string { return r.cols }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.i >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.i])
+	r.i++
+	return nil
+}
@@ -3,90 +3,60 @@ package db
 import (
 	"context"
 	"database/sql"
-	"log"
+	"log/slog"
 	"os"
-	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"BookCollect/internal/config"
 )
 
 var DB *sql.DB
 
-func InitDB() {
-	// 1) Берём конфиг из окружения
-	// Приоритет: DATABASE_URL > POSTGRES_DSN > сборка из отдельных переменных
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = os.Getenv("POSTGRES_DSN")
-	}
-	if dsn == "" {
-		host := getenv("POSTGRES_HOST", "127.0.0.1")
-		port := getenv("POSTGRES_PORT", "5432")
-		user := getenv("POSTGRES_USER", "postgres")
-		pass := os.Getenv("POSTGRES_PASSWORD")
-		name := getenv("POSTGRES_DB", "BookCollect")
-		sslm := getenv("POSTGRES_SSLMODE", "disable") // локально disable; в проде обычно require/verify-full
-
-		// lib/pq key=value формат:
-		// ВАЖНО: не печатай пароль в логах
-		parts := []string{
-			"host=" + host,
-			"port=" + port,
-			"user=" + user,
-			"dbname=" + name,
-			"sslmode=" + sslm,
-		}
-		if pass != "" {
-			parts = append(parts, "password="+pass)
-		}
-		dsn = strings.Join(parts, " ")
-	}
-
-	// 2) Подключаемся
-	var err error
-	DB, err = sql.Open("postgres", dsn)
+func InitDB(c config.DB) {
+	// 1) Подключаемся (DSN собирает config: URL важнее отдельных полей);
+	// соединения обёрнуты для трассировки SQL, см. trace.go
+	connector, err := newTracedConnector(c.DSN())
 	if err != nil {
-		log.Fatalf("db: open failed: %v", err)
+		slog.Error("db: open failed", "err", err)
+		os.Exit(1)
 	}
+	DB = sql.OpenDB(connector)
 
-	// 3) Пул коннектов
+	// 2) Пул коннектов
 	DB.SetMaxOpenConns(10)
 	DB.SetMaxIdleConns(5)
 	DB.SetConnMaxLifetime(30 * time.Minute)
 	DB.SetConnMaxIdleTime(5 * time.Minute)
 
-	// 4) Ping с таймаутом (не вешаем процесс)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := DB.PingContext(ctx); err != nil {
-		log.Fatalf("db: ping failed: %v", err)
+	// 3) Ждём БД: в docker-compose Postgres может подняться позже приложения
+	if err := waitForDB(c.ConnectTimeout); err != nil {
+		slog.Error("db: ping failed", "target", c.Target(), "err", err)
+		os.Exit(1)
 	}
 
-	// 5) Логируем безопасно (без пароля/полного DSN)
-	logSafeDSN()
+	// 4) Логируем безопасно: только «куда», без пароля/полного DSN
+	slog.Info("db: connected", "target", c.Target())
 }
 
-func getenv(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
-	}
-	return def
-}
-
-func logSafeDSN() {
-	// Печатаем только «куда», без секретов
-	host := getenv("POSTGRES_HOST", "")
-	user := getenv("POSTGRES_USER", "")
-	dbn := getenv("POSTGRES_DB", "")
-	if host == "" && user == "" && dbn == "" {
-		// возможно пришёл DATABASE_URL; выдернем хост/базу по-минимуму
-		u := os.Getenv("DATABASE_URL")
-		if u != "" {
-			// не разбираем полноценно, просто не палим пароль
-			log.Println("db: connected (DATABASE_URL provided)")
-			return
+// waitForDB пингует БД с экспоненциальной задержкой (1s, 2s, 4s … до 30s),
+// пока не истечёт limit (0 — ждать бесконечно)
+func waitForDB(limit time.Duration) error {
+	deadline := time.Now().Add(limit)
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := DB.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if limit > 0 && time.Now().Add(delay).After(deadline) {
+			return err
+		}
+		slog.Warn("db: ping failed, retrying", "attempt", attempt, "retry_in", delay, "err", err)
+		time.Sleep(delay)
+		if delay *= 2; delay > 30*time.Second {
+			delay = 30 * time.Second
 		}
 	}
-	log.Printf("db: connected (host=%s user=%s db=%s)", host, user, dbn)
 }
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// Схема создаётся скриптами deploy/initdb/NNN_*.sql (их выполняет образ Postgres
+// при первом запуске или администратор вручную). Отдельной таблицы версий нет,
+// поэтому применённость миграции проверяется по объекту, который она создаёт.
+// Новая миграция — новая строка в конце списка.
+
+// Migration — скрипт и его признак: таблица, столбец таблицы или индекс
+type Migration struct {
+	Name   string
+	Table  string
+	Column string // пусто — достаточно наличия таблицы
+	Index  string // не пусто — признак: индекс с этим именем на таблице Table
+}
+
+var Migrations = []Migration{
+	{"001_schema.sql", "administrators", "", ""},
+	{"002_uploaded_files.sql", "uploaded_files", "", ""},
+	{"003_cover_variants.sql", "collections", "cover_variants", ""},
+	{"004_article_status.sql", "articles", "status", ""},
+	{"005_mail_outbox.sql", "mail_outbox", "", ""},
+	{"006_submission_tracking.sql", "article_events", "", ""},
+	{"007_article_versions.sql", "article_versions", "", ""},
+	{"008_reviews.sql", "review_reports", "", ""},
+	{"009_article_authors.sql", "article_authors", "", ""},
+	{"010_author_registry.sql", "article_authors", "author_id", ""},
+	{"011_collection_stats.sql", "collection_stats_daily", "", ""},
+	{"012_api_keys.sql", "api_key_usage_daily", "", ""},
+	{"013_collection_updated_at.sql", "collections", "updated_at", ""},
+	{"014_mail_outbox_due_idx.sql", "mail_outbox", "", "mail_outbox_due_idx"},
+}
+
+// PendingMigrations — скрипты, признаков которых нет в текущей схеме
+func PendingMigrations(ctx context.Context) ([]string, error) {
+	tables := make([]string, len(Migrations))
+	columns := make([]string, len(Migrations))
+	indexes := make([]string, len(Migrations))
+	for i, m := range Migrations {
+		tables[i], columns[i], indexes[i] = m.Table, m.Column, m.Index
+	}
+
+	rows, err := DB.QueryContext(ctx, `
+		SELECT m.i FROM unnest($1::text[], $2::text[], $3::text[]) WITH ORDINALITY AS m(t, c, x, i)
+		WHERE CASE WHEN m.x = '' THEN NOT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = m.t AND (m.c = '' OR column_name = m.c))
+		ELSE NOT EXISTS (
+			SELECT 1 FROM pg_indexes
+			WHERE schemaname = current_schema() AND tablename = m.t AND indexname = m.x)
+		END
+		ORDER BY m.i`, pq.Array(tables), pq.Array(columns), pq.Array(indexes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []string
+	for rows.Next() {
+		var i int
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		pending = append(pending, Migrations[i-1].Name)
+	}
+	return pending, rows.Err()
+}
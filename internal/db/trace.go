@@ -0,0 +1,131 @@
+package db
+
+import (
+	"BookCollect/internal/tracing"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/* ========= ТРАССИРОВКА SQL ========= */
+
+// tracedConnector оборачивает соединения lib/pq (в тестах — dbtest): каждый запрос — дочерний спан
+// запроса HTTP с текстом SQL (без аргументов: в них персональные данные).
+// Вне трассы (воркеры, миграции, выключенный экспорт) спаны не создаются.
+// Спан запроса заканчивается, когда pq получил ответ, а не после чтения всех строк.
+type tracedConnector struct {
+	driver.Connector
+}
+
+// pqConn — то, что database/sql использует у соединения pq
+type pqConn interface {
+	driver.Conn
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+func newTracedConnector(dsn string) (driver.Connector, error) {
+	c, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return tracedConnector{c}, nil
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pc, ok := conn.(pqConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("db: unexpected driver conn %T", conn)
+	}
+	return tracedConn{pc}, nil
+}
+
+type tracedConn struct {
+	pqConn
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := c.pqConn.QueryContext(ctx, query, args)
+	endQuery(span, err)
+	return rows, err
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	res, err := c.pqConn.ExecContext(ctx, query, args)
+	endQuery(span, err)
+	return res, err
+}
+
+func (c tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.pqConn.BeginTx(ctx, opts)
+	if err != nil || !trace.SpanFromContext(ctx).IsRecording() {
+		return tx, err
+	}
+	return tracedTx{Tx: tx, ctx: ctx}, nil
+}
+
+// tracedTx — спаны на COMMIT / ROLLBACK (BEGIN pq отправляет вместе с первым запросом)
+type tracedTx struct {
+	driver.Tx
+	ctx context.Context
+}
+
+func (tx tracedTx) Commit() error {
+	_, span := startQuery(tx.ctx, "COMMIT")
+	err := tx.Tx.Commit()
+	endQuery(span, err)
+	return err
+}
+
+func (tx tracedTx) Rollback() error {
+	_, span := startQuery(tx.ctx, "ROLLBACK")
+	err := tx.Tx.Rollback()
+	endQuery(span, err)
+	return err
+}
+
+// startQuery открывает спан SQL, если идёт трасса; иначе span == nil
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, nil
+	}
+	op := operationName(query)
+	ctx, span := tracing.Start(ctx, op,
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(op),
+		semconv.DBQueryText(strings.TrimSpace(query)),
+	)
+	return ctx, span
+}
+
+func endQuery(span trace.Span, err error) {
+	if span != nil {
+		tracing.End(span, err)
+	}
+}
+
+// operationName — первое слово запроса: SELECT, INSERT, WITH …
+func operationName(query string) string {
+	f := strings.Fields(query)
+	if len(f) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(f[0])
+}
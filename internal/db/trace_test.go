@@ -0,0 +1,121 @@
+package db
+
+import (
+	"BookCollect/internal/db/dbtest"
+	"BookCollect/internal/tracing"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Провайдер глобальный и задаётся один раз: tracing.tracer делегирует
+// первому установленному провайдеру.
+var spans = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+}
+
+func attr(s sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestTraceHTTPAndSQLSpans(t *testing.T) {
+	fake := dbtest.New()
+	fake.On("SELECT title", dbtest.Result{Columns: []string{"title"}, Rows: [][]driver.Value{{"Сборник"}}})
+	fake.On("UPDATE collections", dbtest.Result{Affected: 1})
+	fake.On("DELETE", dbtest.Result{Err: errors.New("permission denied")})
+	conn := sql.OpenDB(tracedConnector{fake.Connector()})
+	defer conn.Close()
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/collections/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var title string
+		_ = conn.QueryRowContext(ctx, "SELECT title FROM collections WHERE id = $1", 1).Scan(&title)
+		tx, _ := conn.BeginTx(ctx, nil)
+		_, _ = tx.ExecContext(ctx, "  update collections SET title = $1", title)
+		_ = tx.Commit()
+		_, _ = conn.ExecContext(ctx, "DELETE FROM collections")
+	})
+
+	before := len(spans.Ended())
+	req := httptest.NewRequest(http.MethodGet, "/collections/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	got := spans.Ended()[before:]
+
+	var names []string
+	for _, s := range got {
+		names = append(names, s.Name())
+	}
+	// дочерние заканчиваются раньше серверного
+	want := []string{"SELECT", "UPDATE", "COMMIT", "DELETE", "GET /collections/{id}"}
+	if len(names) != len(want) {
+		t.Fatalf("spans = %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("spans = %q, want %q", names, want)
+		}
+	}
+
+	server := got[len(got)-1]
+	if id := server.SpanContext().TraceID().String(); id != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the one from traceparent", id)
+	}
+	if p := server.Parent().SpanID().String(); p != "00f067aa0ba902b7" {
+		t.Errorf("server span parent = %s, want the remote span", p)
+	}
+	if route := attr(server, semconv.HTTPRouteKey); route != "/collections/{id}" {
+		t.Errorf("http.route = %q", route)
+	}
+	for _, s := range got[:len(got)-1] {
+		if s.Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("%s: parent = %s, want server span %s", s.Name(), s.Parent().SpanID(), server.SpanContext().SpanID())
+		}
+		if attr(s, semconv.DBSystemNameKey) != "postgresql" {
+			t.Errorf("%s: db.system.name = %q", s.Name(), attr(s, semconv.DBSystemNameKey))
+		}
+	}
+	if q := attr(got[1], semconv.DBQueryTextKey); q != "update collections SET title = $1" {
+		t.Errorf("db.query.text = %q (trimmed, without arguments)", q)
+	}
+	if del := got[3]; del.Status().Code != codes.Error || del.Status().Description != "permission denied" {
+		t.Errorf("DELETE status = %+v, want error", del.Status())
+	}
+}
+
+func TestTraceNoSpansOutsideRequest(t *testing.T) {
+	fake := dbtest.New()
+	fake.On("SELECT", dbtest.Result{Columns: []string{"n"}, Rows: [][]driver.Value{{int64(1)}}})
+	conn := sql.OpenDB(tracedConnector{fake.Connector()})
+	defer conn.Close()
+
+	before := len(spans.Ended())
+	var n int
+	if err := conn.QueryRowContext(context.Background(), "SELECT 1").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if got := spans.Ended()[before:]; len(got) != 0 {
+		t.Errorf("%d spans recorded for a query outside a trace", len(got))
+	}
+}
@@ -0,0 +1,255 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Kind — реальный тип содержимого файла, определённый по сигнатуре (magic bytes),
+// а не по расширению из имени файла.
+type Kind string
+
+const (
+	Unknown Kind = ""
+	PDF     Kind = "pdf"
+	DOCX    Kind = "docx"
+	ODT     Kind = "odt"
+	JPEG    Kind = "jpeg"
+	PNG     Kind = "png"
+	WebP    Kind = "webp"
+)
+
+var (
+	ErrUnknown      = errors.New("filetype: unknown content")
+	ErrMismatch     = errors.New("filetype: content does not match extension")
+	ErrMalformedPDF = errors.New("filetype: malformed pdf")
+	ErrEncryptedPDF = errors.New("filetype: encrypted pdf")
+	ErrMalformedZip = errors.New("filetype: malformed office document")
+)
+
+// Ext — каноническое расширение для типа (с точкой)
+func (k Kind) Ext() string {
+	switch k {
+	case JPEG:
+		return ".jpg"
+	case Unknown:
+		return ""
+	}
+	return "." + string(k)
+}
+
+// ContentType — MIME-тип для отдачи файла клиенту
+func (k Kind) ContentType() string {
+	switch k {
+	case PDF:
+		return "application/pdf"
+	case DOCX:
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case ODT:
+		return "application/vnd.oasis.opendocument.text"
+	case JPEG:
+		return "image/jpeg"
+	case PNG:
+		return "image/png"
+	case WebP:
+		return "image/webp"
+	}
+	return "application/octet-stream"
+}
+
+// KindByExt — какой тип ожидается для расширения из имени файла
+func KindByExt(ext string) Kind {
+	switch strings.ToLower(ext) {
+	case ".pdf":
+		return PDF
+	case ".docx":
+		return DOCX
+	case ".odt":
+		return ODT
+	case ".jpg", ".jpeg":
+		return JPEG
+	case ".png":
+		return PNG
+	case ".webp":
+		return WebP
+	}
+	return Unknown
+}
+
+// Сигнатуры
+var (
+	pdfMagic  = []byte("%PDF-")
+	zipMagic  = []byte("PK\x03\x04")
+	jpegMagic = []byte{0xFF, 0xD8, 0xFF}
+	pngMagic  = []byte("\x89PNG\r\n\x1a\n")
+)
+
+const (
+	// PDF допускает мусор перед заголовком, но в пределах первого килобайта
+	pdfHeadWindow = 1024
+	// %%EOF и startxref ищем в хвосте файла
+	pdfTailWindow = 2048
+	// /Encrypt ищем по всему файлу кусками такого размера
+	pdfScanChunk = 1 << 20
+
+	docxMainType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
+	odtMimeType  = "application/vnd.oasis.opendocument.text"
+)
+
+// Detect определяет тип содержимого. Для PDF и офисных документов выполняет
+// структурную проверку (trailer, шифрование, обязательные записи ZIP-контейнера).
+func Detect(r io.ReaderAt, size int64) (Kind, error) {
+	head := make([]byte, min(size, pdfHeadWindow))
+	if _, err := r.ReadAt(head, 0); err != nil && err != io.EOF {
+		return Unknown, err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, jpegMagic):
+		return JPEG, nil
+	case bytes.HasPrefix(head, pngMagic):
+		return PNG, nil
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return WebP, nil
+	case bytes.Contains(head, pdfMagic):
+		return PDF, checkPDF(r, size)
+	case bytes.HasPrefix(head, zipMagic):
+		return detectZip(r, size)
+	}
+	return Unknown, ErrUnknown
+}
+
+// Expect проверяет, что содержимое соответствует ожидаемому типу
+func Expect(r io.ReaderAt, size int64, want Kind) error {
+	got, err := Detect(r, size)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// IsImage — тип допустим как обложка
+func (k Kind) IsImage() bool {
+	return k == JPEG || k == PNG || k == WebP
+}
+
+func checkPDF(r io.ReaderAt, size int64) error {
+	n := min(size, pdfTailWindow)
+	tail := make([]byte, n)
+	if _, err := r.ReadAt(tail, size-n); err != nil && err != io.EOF {
+		return err
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return ErrMalformedPDF
+	}
+	// Классический trailer или cross-reference stream (PDF 1.5+)
+	if !bytes.Contains(tail, []byte("startxref")) {
+		return ErrMalformedPDF
+	}
+	return scanEncrypt(r, size)
+}
+
+var pdfEncrypt = []byte("/Encrypt")
+
+// scanEncrypt ищет ключ /Encrypt по всему файлу: он стоит в trailer или в
+// словаре xref-потока, а их при инкрементальных обновлениях и в
+// линеаризованных PDF бывает несколько и не обязательно в конце. Эти словари
+// не сжимаются, так что хватает поиска по сырым байтам. Размер файла уже
+// ограничен лимитом загрузки.
+func scanEncrypt(r io.ReaderAt, size int64) error {
+	// перекрытие — чтобы не пропустить ключ со значением на стыке кусков
+	const overlap = 64
+	buf := make([]byte, pdfScanChunk+overlap)
+	for off := int64(0); off < size; off += pdfScanChunk {
+		n, err := r.ReadAt(buf[:min(int64(len(buf)), size-off)], off)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if hasEncryptKey(buf[:n]) {
+			return ErrEncryptedPDF
+		}
+	}
+	return nil
+}
+
+// hasEncryptKey — в b есть «/Encrypt» со значением-словарём или ссылкой
+// (а не, скажем, слово в несжатом тексте страницы или /EncryptMetadata)
+func hasEncryptKey(b []byte) bool {
+	for from := 0; ; {
+		i := bytes.Index(b[from:], pdfEncrypt)
+		if i < 0 {
+			return false
+		}
+		p := from + i + len(pdfEncrypt)
+		from = p
+		for p < len(b) && isPDFSpace(b[p]) {
+			p++
+		}
+		if p < len(b) && (b[p] == '<' || b[p] >= '0' && b[p] <= '9') {
+			return true
+		}
+	}
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func detectZip(r io.ReaderAt, size int64) (Kind, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return Unknown, ErrMalformedZip
+	}
+
+	// ODT: первая запись — несжатый mimetype с фиксированным содержимым
+	if len(zr.File) > 0 && zr.File[0].Name == "mimetype" {
+		if string(readEntry(zr.File[0], 128)) == odtMimeType && zr.File[0].Method == zip.Store {
+			if findEntry(zr, "content.xml") == nil {
+				return Unknown, ErrMalformedZip
+			}
+			return ODT, nil
+		}
+		return Unknown, ErrUnknown
+	}
+
+	// DOCX: [Content_Types].xml объявляет основной part документа Word
+	if ct := findEntry(zr, "[Content_Types].xml"); ct != nil {
+		if !bytes.Contains(readEntry(ct, 64<<10), []byte(docxMainType)) {
+			return Unknown, ErrUnknown
+		}
+		if findEntry(zr, "word/document.xml") == nil {
+			return Unknown, ErrMalformedZip
+		}
+		return DOCX, nil
+	}
+	return Unknown, ErrUnknown
+}
+
+func findEntry(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func readEntry(f *zip.File, limit int64) []byte {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	b, _ := io.ReadAll(io.LimitReader(rc, limit))
+	return b
+}
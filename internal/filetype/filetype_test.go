@@ -0,0 +1,71 @@
+package filetype
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func pdf(body string) string {
+	return "%PDF-1.7\n" + body + "\nstartxref\n123\n%%EOF\n"
+}
+
+func detect(s string) (Kind, error) {
+	return Detect(strings.NewReader(s), int64(len(s)))
+}
+
+func TestDetectPDFEncryption(t *testing.T) {
+	padding := strings.Repeat("0 0 0 RG\n", 1000) // > pdfTailWindow
+	// ключ на стыке кусков pdfScanChunk
+	straddle := strings.Repeat(" ", pdfScanChunk-len("%PDF-1.7\n")-4) + "/Encrypt 5 0 R"
+
+	for _, tc := range []struct {
+		name string
+		body string
+		want error
+	}{
+		{"plain", "1 0 obj << /Type /Catalog >> endobj", nil},
+		{"trailer at the end", "trailer << /Root 1 0 R /Encrypt 9 0 R >>", ErrEncryptedPDF},
+		{"linearized: first-page trailer", "trailer << /Encrypt<< /Filter /Standard >> >>\n" + padding, ErrEncryptedPDF},
+		{"xref stream far from the end", "7 0 obj << /Type /XRef /Encrypt\n12 0 R >> stream\nendstream\n" + padding, ErrEncryptedPDF},
+		{"across chunks", straddle + "\n" + padding, ErrEncryptedPDF},
+		{"word in page text", "BT (/Encrypt) Tj ET\n" + padding, nil},
+		{"other key", "<< /EncryptMetadata false >>", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, err := detect(pdf(tc.body))
+			if kind != PDF || !errors.Is(err, tc.want) {
+				t.Fatalf("Detect = %s, %v; want pdf, %v", kind, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectMalformedPDF(t *testing.T) {
+	for _, s := range []string{
+		"%PDF-1.4\n1 0 obj << >> endobj\n",        // оборван: нет %%EOF
+		"%PDF-1.4\n1 0 obj << >> endobj\n%%EOF\n", // нет startxref
+	} {
+		if _, err := detect(s); !errors.Is(err, ErrMalformedPDF) {
+			t.Errorf("Detect(%q) = %v, want ErrMalformedPDF", s, err)
+		}
+	}
+}
+
+func TestDetectImages(t *testing.T) {
+	for _, tc := range []struct {
+		head string
+		want Kind
+	}{
+		{"\xFF\xD8\xFF\xE0....", JPEG},
+		{"\x89PNG\r\n\x1a\n....", PNG},
+		{"RIFF\x00\x00\x00\x00WEBPVP8 ", WebP},
+	} {
+		if got, err := detect(tc.head); got != tc.want || err != nil {
+			t.Errorf("Detect(%q) = %s, %v; want %s", tc.head, got, err, tc.want)
+		}
+	}
+	if _, err := detect("hello"); !errors.Is(err, ErrUnknown) {
+		t.Errorf("Detect(text) = %v, want ErrUnknown", err)
+	}
+}
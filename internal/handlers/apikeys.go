@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"BookCollect/internal/apikeys"
+	"BookCollect/internal/db"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// APIKey — ключ в списке админки (токен не хранится и не показывается)
+type APIKey struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	RateLimit     int        `json:"rate_limit"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RequestsToday int        `json:"requests_today"`
+	Requests30d   int        `json:"requests_30d"`
+	Limited30d    int        `json:"limited_30d"`
+}
+
+// ADMIN: ключи API с использованием за сегодня и 30 дней (счётчики в БД
+// отстают от реальности не больше чем на apikeys.FlushInterval)
+func GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT k.id, k.name, k.prefix, k.rate_limit, k.created_at, k.last_used_at, k.revoked_at,
+		       COALESCE(SUM(u.requests) FILTER (WHERE u.day = CURRENT_DATE), 0),
+		       COALESCE(SUM(u.requests), 0),
+		       COALESCE(SUM(u.limited), 0)
+		FROM api_keys k
+		LEFT JOIN api_key_usage_daily u ON u.key_id = k.id AND u.day > CURRENT_DATE - 30
+		GROUP BY k.id
+		ORDER BY k.revoked_at IS NOT NULL, k.id DESC`)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	list := make([]APIKey, 0, 16)
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.RateLimit, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt,
+			&k.RequestsToday, &k.Requests30d, &k.Limited30d); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		list = append(list, k)
+	}
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// ADMIN: выдать ключ (JSON: name, rate_limit — необязательно). Токен — только в этом ответе.
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name      string `json:"name"`
+		RateLimit int    `json:"rate_limit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+	in.Name = strings.TrimSpace(in.Name)
+	if in.RateLimit == 0 {
+		in.RateLimit = apikeys.DefaultRateLimit
+	}
+	var v validation
+	if in.Name == "" {
+		v.add("name", FieldRequired, "Укажите название")
+	}
+	if in.RateLimit < 1 || in.RateLimit > 100000 {
+		v.add("rate_limit", FieldInvalid, "Лимит — от 1 до 100000 запросов в минуту")
+	}
+	if err := v.result(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	token, prefix, hash, err := apikeys.Generate()
+	if err != nil {
+		serverError(w, r, "Ошибка сервера", err)
+		return
+	}
+	var id int
+	if err := db.DB.QueryRowContext(r.Context(), `
+		INSERT INTO api_keys (name, prefix, token_hash, rate_limit)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, in.Name, prefix, hash, in.RateLimit).Scan(&id); err != nil {
+		serverError(w, r, "Ошибка вставки", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":         id,
+		"name":       in.Name,
+		"prefix":     prefix,
+		"rate_limit": in.RateLimit,
+		"token":      token,
+	})
+}
+
+// ADMIN: отозвать ключ. Запись и счётчики остаются для истории.
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+	res, err := db.DB.ExecContext(r.Context(), `
+		UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		serverError(w, r, "Ошибка обновления", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		writeError(w, r, errNotFound("Ключ не найден или уже отозван"))
+		return
+	}
+	apikeys.Forget(id)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+}
+
+// ADMIN UI: ключи партнёрского API
+func AdminAPIKeysPage(w http.ResponseWriter, r *http.Request) {
+	render(w, r,
+		"admin/apikeys.html",
+		map[string]any{
+			"Title":     "Админ · Ключи API",
+			"Year":      time.Now().Year(),
+			"RateLimit": apikeys.DefaultRateLimit,
+		},
+	)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"BookCollect/internal/apikeys"
+	"BookCollect/internal/db"
+	"BookCollect/internal/metrics"
+	"BookCollect/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+/* ========= ПАРТНЁРСКОЕ API /api/v1 ========= */
+
+// Доступ — по API-ключу из админки (Authorization: Bearer bck_… или
+// X-API-Key), с лимитом запросов на ключ. Ответы — models.V1*, контракт —
+// internal/openapi/openapi.json.
+
+const (
+	v1DefaultLimit = 50
+	v1MaxLimit     = 200
+)
+
+// RequireAPIKey — проверка ключа, лимит и учёт запроса
+func RequireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); token == "" && auth != "" {
+			if scheme, t, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+				token = strings.TrimSpace(t)
+			}
+		}
+		if token == "" {
+			metrics.APIRequests.WithLabelValues("unauthorized").Inc()
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			writeError(w, r, newError(http.StatusUnauthorized, CodeUnauthorized, "Нужен API-ключ: заголовок Authorization: Bearer <ключ>"))
+			return
+		}
+
+		key, err := apikeys.Lookup(r.Context(), token)
+		if errors.Is(err, apikeys.ErrInvalid) {
+			metrics.APIRequests.WithLabelValues("unauthorized").Inc()
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			writeError(w, r, newError(http.StatusUnauthorized, CodeUnauthorized, "Недействительный API-ключ"))
+			return
+		} else if err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+
+		ok, remaining, retry := apikeys.Limits.Allow(key.ID, key.RateLimit)
+		apikeys.Default.Record(key.ID, !ok)
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(key.RateLimit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			metrics.APIRequests.WithLabelValues("limited").Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			writeError(w, r, newError(http.StatusTooManyRequests, CodeRateLimited, "Превышен лимит: %d запросов в минуту", key.RateLimit))
+			return
+		}
+		metrics.APIRequests.WithLabelValues("ok").Inc()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Deprecated — заголовки устаревшего /api/... (RFC 9745, RFC 8288) со ссылкой
+// на тот же ресурс в /api/v1
+func Deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "</api/v1"+strings.TrimPrefix(r.URL.Path, "/api")+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// queryInt — целый параметр запроса от lo до hi; пусто — def, иначе ошибка поля в v
+func queryInt(r *http.Request, v *validation, name string, def, lo, hi int) int {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		v.add(name, FieldInvalid, "%s — целое число от %d до %d", name, lo, hi)
+	}
+	return n
+}
+
+// V1: список сборников, новые — первыми (?limit=, ?offset=)
+func V1Collections(w http.ResponseWriter, r *http.Request) {
+	var v validation
+	limit := queryInt(r, &v, "limit", v1DefaultLimit, 1, v1MaxLimit)
+	offset := queryInt(r, &v, "offset", 0, 0, math.MaxInt32)
+	if e := v.result(); e != nil {
+		writeError(w, r, e)
+		return
+	}
+
+	total, modified, err := collectionsVersion(r.Context())
+	if err != nil {
+		serverError(w, r, "Ошибка запроса", err)
+		return
+	}
+	base := baseURL(r)
+	// без Last-Modified — см. GetCollections
+	if notModified(w, r, etag("api/v1/collections", total, modified, limit, offset, base), time.Time{}) {
+		return
+	}
+
+	out := models.V1CollectionList{
+		Data: make([]models.V1Collection, 0, limit),
+		Page: models.V1Page{Total: total, Limit: limit, Offset: offset},
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path, cover_variants
+		FROM collections
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		serverError(w, r, "Ошибка запроса", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.Collection
+		if err := rows.Scan(
+			&c.ID, &c.ReleaseNumber, &c.ReleaseYear, &c.Title, &c.Description,
+			&c.CoverImage, &c.PublicationLink, &c.PDFPath, &c.CoverVariants,
+		); err != nil {
+			serverError(w, r, "Ошибка чтения строк", err)
+			return
+		}
+		out.Data = append(out.Data, models.CollectionToV1(c, base))
+	}
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка чтения строк", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// V1: сборник
+func V1Collection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	var c models.Collection
+	var modified time.Time
+	err = db.DB.QueryRowContext(r.Context(), `
+		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path, cover_variants, updated_at
+		FROM collections
+		WHERE id = $1`, id).Scan(
+		&c.ID, &c.ReleaseNumber, &c.ReleaseYear, &c.Title, &c.Description,
+		&c.CoverImage, &c.PublicationLink, &c.PDFPath, &c.CoverVariants, &modified,
+	)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Сборник не найден"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка запроса", err)
+		return
+	}
+
+	base := baseURL(r)
+	if notModified(w, r, etag("api/v1/collection", id, modified, base), modified) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(models.CollectionToV1(c, base))
+}
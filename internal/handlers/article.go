@@ -2,11 +2,20 @@ package handlers
 
 import (
 	"BookCollect/internal/db"
+	"BookCollect/internal/filetype"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/metrics"
 	"BookCollect/internal/models"
+	"BookCollect/internal/tracing"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -44,100 +53,184 @@ func sanitizeFileName(input string) string {
 }
 
 // PUBLIC: подать заявку
-var (
-	maxUploadSize int64 = 25 << 20 // 25 MB
-	allowedExt          = map[string]bool{".pdf": true, ".docx": true, ".odt": true}
-	emailRe             = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
-)
+var emailRe = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
 
 // AddArticle обрабатывает публичную подачу заявки со вложением
 func AddArticle(w http.ResponseWriter, r *http.Request) {
 	// Ограничиваем тело запроса и парсим multipart
-	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
-	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
-		jsonError(w, http.StatusRequestEntityTooLarge, "Слишком большой файл (лимит 25 МБ)")
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.Uploads.MaxSize())
+	if err := r.ParseMultipartForm(cfg.Uploads.MaxSize()); err != nil {
+		writeError(w, r, errTooLarge())
 		return
 	}
 
-	author := strings.TrimSpace(r.FormValue("author"))
 	title := strings.TrimSpace(r.FormValue("title"))
 	email := strings.TrimSpace(r.FormValue("email"))
 
-	if author == "" || title == "" || email == "" {
-		jsonError(w, http.StatusBadRequest, "Заполните все поля: Автор, Название, Email")
+	var v validation
+	if title == "" {
+		v.add("title", FieldRequired, "Укажите название")
+	}
+	switch {
+	case email == "":
+		v.add("email", FieldRequired, "Укажите email")
+	case !emailRe.MatchString(email):
+		v.add("email", FieldInvalid, "Некорректный email")
+	}
+	if err := v.result(); err != nil {
+		writeError(w, r, err)
 		return
 	}
-	if !emailRe.MatchString(email) {
-		jsonError(w, http.StatusBadRequest, "Некорректный email")
+	authors, verr := parseAuthors(r)
+	if verr != nil {
+		writeError(w, r, verr)
 		return
 	}
+	// articles.author — строка для писем и списков; полные данные — в article_authors
+	author := models.AuthorsLine(authors)
 
-	file, handler, err := r.FormFile("file")
-	if err != nil {
-		jsonError(w, http.StatusBadRequest, "Приложите файл рукописи")
+	file, handler, ok := acceptManuscript(w, r)
+	if !ok {
 		return
 	}
 	defer file.Close()
 
-	ext := strings.ToLower(filepath.Ext(handler.Filename))
-	if !allowedExt[ext] {
-		jsonError(w, http.StatusBadRequest, "Недопустимый тип файла. Разрешено: PDF, DOCX, ODT")
+	dstPath, err := saveManuscript(r.Context(), file, handler, title)
+	if err != nil {
+		serverError(w, r, "Не удалось сохранить файл", err)
 		return
 	}
 
-	// Дополнительная проверка размера, если доступен
-	if handler.Size > 0 && handler.Size > maxUploadSize {
-		jsonError(w, http.StatusRequestEntityTooLarge, "Слишком большой файл (лимит 25 МБ)")
+	token, tokenHash, err := newTrackingToken()
+	if err != nil {
+		_ = os.Remove(localPath(dstPath))
+		serverError(w, r, "Не удалось создать ссылку для отслеживания", err)
 		return
 	}
 
-	// Готовим директорию и имя файла
-	if err := os.MkdirAll("uploads/articles", 0o755); err != nil {
-		jsonError(w, http.StatusInternalServerError, "Не удалось подготовить хранилище файлов")
-		return
-	}
-	base := safeBaseName(strings.TrimSpace(title))
-	if base == "" {
-		base = "article"
+	fail := func(msg string, err error) {
+		// При ошибке БД — удалим сохранённый файл, чтобы не копить мусор
+		_ = os.Remove(localPath(dstPath))
+		serverError(w, r, msg, err)
 	}
-	fileName := fmt.Sprintf("%s_%d%s", base, time.Now().Unix(), ext)
-	dstPath := filepath.Join("uploads", "articles", fileName)
 
-	// Пишем файл на диск
-	dst, err := os.Create(dstPath)
+	tx, err := db.DB.BeginTx(r.Context(), nil)
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, "Не удалось сохранить файл")
-		return
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		jsonError(w, http.StatusInternalServerError, "Ошибка записи файла")
+		fail("Ошибка БД при сохранении заявки", err)
 		return
 	}
+	defer tx.Rollback()
 
-	// Пишем запись в БД
 	var id int
-	err = db.DB.QueryRow(
-		`INSERT INTO articles (author, title, email, file_path) VALUES ($1,$2,$3,$4) RETURNING id`,
-		author, title, email, "/"+filepath.ToSlash(dstPath),
+	lang := requestLang(r)
+	err = tx.QueryRowContext(r.Context(),
+		`INSERT INTO articles (author, title, email, file_path, lang, tracking_token_hash) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id`,
+		author, title, email, dstPath, lang, tokenHash,
 	).Scan(&id)
 	if err != nil {
-		// При ошибке БД — удалим сохранённый файл, чтобы не копить мусор
-		_ = os.Remove(dstPath)
-		jsonError(w, http.StatusInternalServerError, "Ошибка БД при сохранении заявки")
+		fail("Ошибка БД при сохранении заявки", err)
+		return
+	}
+	if err := insertArticleAuthors(r.Context(), tx, id, authors); err != nil {
+		fail("Ошибка БД при сохранении авторов", err)
+		return
+	}
+	if err := addArticleVersion(r.Context(), tx, id, 1, dstPath, handler.Filename, "author", ""); err != nil {
+		fail("Ошибка БД при сохранении заявки", err)
+		return
+	}
+	if err := addArticleEvent(r.Context(), tx, id, models.StatusSubmitted, ""); err != nil {
+		fail("Ошибка БД при сохранении заявки", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		fail("Ошибка БД при сохранении заявки", err)
 		return
 	}
 
+	trackURL := trackingURL(r, token)
+	notifySubmission(r.Context(), id, contactName(authors), title, email, lang, trackURL)
+
 	// Успех
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	_ = json.NewEncoder(w).Encode(map[string]any{
-		"ok":  true,
-		"id":  id,
-		"msg": "Заявка отправлена",
+		"ok":           true,
+		"id":           id,
+		"msg":          "Заявка отправлена",
+		"tracking_url": trackURL,
 	})
 }
 
+// acceptManuscript достаёт файл рукописи из формы и проверяет его
+// (расширение, сигнатура, антивирус, размер). При ошибке сам пишет ответ.
+func acceptManuscript(w http.ResponseWriter, r *http.Request) (multipart.File, *multipart.FileHeader, bool) {
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, r, errValidation("file", FieldRequired, "Приложите файл рукописи"))
+		return nil, nil, false
+	}
+
+	ext := strings.ToLower(filepath.Ext(handler.Filename))
+	if !uploadAllowed(ext) {
+		file.Close()
+		writeError(w, r, errValidation("file", FieldInvalid, "Недопустимый тип файла. Разрешено: %s", uploadFormats()))
+		return nil, nil, false
+	}
+	// Расширению не доверяем — проверяем сигнатуру и структуру файла
+	_, e := checkUpload(file, handler, filetype.KindByExt(ext))
+	if e == nil {
+		e = scanUpload(r.Context(), file, handler)
+	}
+	if e != nil {
+		file.Close()
+		writeError(w, r, e.withField("file", FieldInvalid, e.Msg, e.Args...))
+		return nil, nil, false
+	}
+
+	// Дополнительная проверка размера, если доступен
+	if handler.Size > 0 && handler.Size > cfg.Uploads.MaxSize() {
+		file.Close()
+		writeError(w, r, errTooLarge())
+		return nil, nil, false
+	}
+	return file, handler, true
+}
+
+// saveManuscript пишет рукопись в uploads/articles и возвращает публичный путь (/uploads/articles/...)
+func saveManuscript(ctx context.Context, file multipart.File, handler *multipart.FileHeader, title string) (_ string, err error) {
+	_, span := tracing.Start(ctx, "storage.store", semconv.FileDirectory("articles"))
+	defer func() { tracing.End(span, err) }()
+
+	if err := os.MkdirAll("uploads/articles", 0o755); err != nil {
+		return "", err
+	}
+	ext := strings.ToLower(filepath.Ext(handler.Filename))
+	base := safeBaseName(strings.TrimSpace(title))
+	if base == "" {
+		base = "article"
+	}
+	fileName := fmt.Sprintf("%s_%d_%s%s", base, time.Now().Unix(), randomSuffix(), ext)
+	dstPath := filepath.Join("uploads", "articles", fileName)
+
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	size, err := io.Copy(dst, io.NewSectionReader(file, 0, handler.Size))
+	if err != nil {
+		dst.Close()
+		_ = os.Remove(dstPath)
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dstPath)
+		return "", err
+	}
+	span.SetAttributes(semconv.FilePath(dstPath), semconv.FileSize(int(size)))
+	metrics.UploadBytes.WithLabelValues("articles").Observe(float64(size))
+	return "/" + filepath.ToSlash(dstPath), nil
+}
+
 // safeBaseName — грубая нормализация имени файла (латиница/цифры/дефис/подчёркивание)
 func safeBaseName(s string) string {
 	// Убираем расширение, если прилетело целиком
@@ -150,19 +243,11 @@ func safeBaseName(s string) string {
 	return strings.Trim(re.ReplaceAllString(s, ""), "_-")
 }
 
-func jsonError(w http.ResponseWriter, code int, msg string) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"error": msg,
-	})
-}
-
 // ADMIN: список заявок (JSON)
 func GetArticle(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.DB.Query(`SELECT id, author, title, email, file_path FROM articles ORDER BY id DESC`)
+	rows, err := db.DB.QueryContext(r.Context(), `SELECT id, author, title, email, file_path FROM articles ORDER BY id DESC`)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Ошибка сервера", err)
 		return
 	}
 	defer rows.Close()
@@ -171,7 +256,7 @@ func GetArticle(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var a models.Article
 		if err := rows.Scan(&a.ID, &a.Author, &a.Title, &a.Email, &a.FilePath); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			serverError(w, r, "Ошибка сервера", err)
 			return
 		}
 		articles = append(articles, a)
@@ -186,16 +271,20 @@ func GetArticleByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var a models.Article
-	if err := db.DB.QueryRow(`
+	if err := db.DB.QueryRowContext(r.Context(), `
 		SELECT id, author, title, email, file_path 
 		FROM articles WHERE id = $1`, id).
 		Scan(&a.ID, &a.Author, &a.Title, &a.Email, &a.FilePath); err != nil {
-		http.Error(w, "Не найдено: "+err.Error(), http.StatusNotFound)
+		writeError(w, r, errNotFound("Не найдено"))
+		return
+	}
+	if a.Authors, err = loadArticleAuthors(r.Context(), id); err != nil {
+		serverError(w, r, "Ошибка БД", err)
 		return
 	}
 
@@ -208,19 +297,32 @@ func DownloadArticleFile(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
+	// ?version=N — конкретная версия рукописи, без параметра — текущая
 	var filePath string
-	if err := db.DB.QueryRow(`SELECT file_path FROM articles WHERE id = $1`, id).Scan(&filePath); err != nil {
-		http.Error(w, "Не найдено: "+err.Error(), http.StatusNotFound)
+	if v := r.URL.Query().Get("version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version < 1 {
+			writeError(w, r, errValidation("version", FieldInvalid, "Некорректная версия"))
+			return
+		}
+		if err := db.DB.QueryRowContext(r.Context(), `
+			SELECT file_path FROM article_versions WHERE article_id = $1 AND version = $2`, id, version).
+			Scan(&filePath); err != nil {
+			writeError(w, r, errNotFound("Версия не найдена"))
+			return
+		}
+	} else if err := db.DB.QueryRowContext(r.Context(), `SELECT file_path FROM articles WHERE id = $1`, id).Scan(&filePath); err != nil {
+		writeError(w, r, errNotFound("Не найдено"))
 		return
 	}
 
-	f, err := os.Open(filePath)
+	f, err := os.Open(localPath(filePath))
 	if err != nil {
-		http.Error(w, "Файл недоступен: "+err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Файл недоступен", err)
 		return
 	}
 	defer f.Close()
@@ -235,42 +337,56 @@ func DeleteArticle(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
 	var filePath string
-	if err := db.DB.QueryRow(`SELECT file_path FROM articles WHERE id = $1`, id).Scan(&filePath); err != nil {
-		http.Error(w, "Статья не найдена", http.StatusNotFound)
+	if err := db.DB.QueryRowContext(r.Context(), `SELECT file_path FROM articles WHERE id = $1`, id).Scan(&filePath); err != nil {
+		writeError(w, r, errNotFound("Статья не найдена"))
 		return
 	}
 
-	if _, err := db.DB.Exec(`DELETE FROM articles WHERE id = $1`, id); err != nil {
-		http.Error(w, "Ошибка при удалении", http.StatusInternalServerError)
-		return
+	// Файлы всех версий (article_versions удалится каскадом вместе с заявкой)
+	files := []string{filePath}
+	if rows, err := db.DB.QueryContext(r.Context(), `SELECT file_path FROM article_versions WHERE article_id = $1`, id); err == nil {
+		for rows.Next() {
+			var p string
+			if rows.Scan(&p) == nil && p != filePath {
+				files = append(files, p)
+			}
+		}
+		rows.Close()
 	}
 
-	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
-		http.Error(w, "Файл не удалён", http.StatusInternalServerError)
+	if _, err := db.DB.ExecContext(r.Context(), `DELETE FROM articles WHERE id = $1`, id); err != nil {
+		serverError(w, r, "Ошибка при удалении", err)
 		return
 	}
 
+	for _, p := range files {
+		if err := os.Remove(localPath(p)); err != nil && !os.IsNotExist(err) {
+			serverError(w, r, "Файл не удалён", err)
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintln(w, "Статья и файл удалены")
 }
 
 func GetArticles(w http.ResponseWriter, r *http.Request) {
 	if db.DB == nil {
-		http.Error(w, `{"error":"db not initialized"}`, http.StatusInternalServerError)
+		serverError(w, r, "БД не инициализирована", errNoDB)
 		return
 	}
 
-	rows, err := db.DB.Query(`
-		SELECT id, author, title, email, file_path, created_at
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT id, author, title, email, file_path, status, collection_id, created_at
 		FROM articles
 		ORDER BY id DESC`)
 	if err != nil {
-		http.Error(w, `{"error":"db query failed"}`, http.StatusInternalServerError)
+		serverError(w, r, "Ошибка БД", err)
 		return
 	}
 	defer rows.Close()
@@ -278,14 +394,14 @@ func GetArticles(w http.ResponseWriter, r *http.Request) {
 	list := make([]models.ArticleRow, 0, 64)
 	for rows.Next() {
 		var a models.ArticleRow
-		if err := rows.Scan(&a.ID, &a.Author, &a.Title, &a.Email, &a.FilePath, &a.CreatedAt); err != nil {
-			http.Error(w, `{"error":"db scan failed"}`, http.StatusInternalServerError)
+		if err := rows.Scan(&a.ID, &a.Author, &a.Title, &a.Email, &a.FilePath, &a.Status, &a.CollectionID, &a.CreatedAt); err != nil {
+			serverError(w, r, "Ошибка чтения БД", err)
 			return
 		}
 		list = append(list, a)
 	}
 	if err := rows.Err(); err != nil {
-		http.Error(w, `{"error":"db rows error"}`, http.StatusInternalServerError)
+		serverError(w, r, "Ошибка чтения БД", err)
 		return
 	}
 
@@ -293,3 +409,87 @@ func GetArticles(w http.ResponseWriter, r *http.Request) {
 	// Отдаём просто массив — твой admin.js это понимает
 	_ = json.NewEncoder(w).Encode(list)
 }
+
+// ADMIN: сменить статус заявки (JSON: {"status": "...", "message": "..."}) и уведомить автора
+func SetArticleStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	var in struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+	if !models.ValidArticleStatus(in.Status) {
+		writeError(w, r, errValidation("status", FieldInvalid, "Неизвестный статус"))
+		return
+	}
+	in.Message = strings.TrimSpace(in.Message)
+
+	var author, title, email, lang string
+	err = db.DB.QueryRowContext(r.Context(), `
+		UPDATE articles SET status = $2, status_changed_at = NOW()
+		WHERE id = $1
+		RETURNING `+contactNameSQL+`, title, email, lang`, id, in.Status).
+		Scan(&author, &title, &email, &lang)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Заявка не найдена"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД при смене статуса", err)
+		return
+	}
+
+	if err := addArticleEvent(r.Context(), db.DB, id, in.Status, in.Message); err != nil {
+		logging.From(r).Error("article history", "article_id", id, "err", err)
+	}
+	notifyStatus(r.Context(), id, author, title, email, lang, in.Status, in.Message)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":     true,
+		"id":     id,
+		"status": in.Status,
+	})
+}
+
+// ADMIN: включить статью в сборник (JSON: collection_id, null — убрать из сборника)
+func SetArticleCollection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+	var in struct {
+		CollectionID *int `json:"collection_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+
+	res, err := db.DB.ExecContext(r.Context(), `
+		UPDATE articles SET collection_id = $2 WHERE id = $1`, id, in.CollectionID)
+	if err != nil {
+		// нарушение внешнего ключа — такого сборника нет
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			writeError(w, r, errValidation("collection_id", FieldInvalid, "Сборник не найден"))
+			return
+		}
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		writeError(w, r, errNotFound("Заявка не найдена"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+}
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"BookCollect/internal/db"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/metrics"
 	"BookCollect/internal/sessions"
 	"database/sql"
-	"html/template"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -23,15 +23,7 @@ func ShowLoginPage(w http.ResponseWriter, r *http.Request) {
 		data["Error"] = errMsg
 	}
 
-	tmpl, err := template.ParseFiles(
-		"web/templates/base.html",
-		"web/templates/admin/login.html",
-	)
-	if err != nil {
-		http.Error(w, "Ошибка шаблона", http.StatusInternalServerError)
-		return
-	}
-	_ = tmpl.ExecuteTemplate(w, "base", data)
+	render(w, r, "admin/login.html", data)
 }
 
 // HandleLogin обрабатывает POST-запрос входа администратора
@@ -42,7 +34,7 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if db.DB == nil {
-		http.Error(w, "БД не инициализирована (db.DB == nil)", http.StatusInternalServerError)
+		serverError(w, r, "БД не инициализирована", errNoDB)
 		return
 	}
 
@@ -55,9 +47,10 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 
 	var id int
 	var passwordHash string
-	err := db.DB.QueryRow(`SELECT id, password_hash FROM administrators WHERE login = $1`, login).
+	err := db.DB.QueryRowContext(r.Context(), `SELECT id, password_hash FROM administrators WHERE login = $1`, login).
 		Scan(&id, &passwordHash)
 	if err == sql.ErrNoRows {
+		metrics.LoginFailures.WithLabelValues("admin").Inc()
 		http.Redirect(w, r, "/admin/login?error=Неверный логин или пароль", http.StatusFound)
 		return
 	} else if err != nil {
@@ -66,12 +59,13 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) != nil {
+		metrics.LoginFailures.WithLabelValues("admin").Inc()
 		http.Redirect(w, r, "/admin/login?error=Неверный логин или пароль", http.StatusFound)
 		return
 	}
 
 	if err := sessions.SetAdminID(w, r, id); err != nil {
-		log.Println("session save error: %v", err)
+		logging.From(r).Error("session save", "err", err)
 		http.Redirect(w, r, "/admin/login?error=Ошибка сессии", http.StatusFound)
 		return
 	}
@@ -81,7 +75,7 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 // HandleLogout удаляет сессию и возвращает на логин
 func HandleLogout(w http.ResponseWriter, r *http.Request) {
 	if err := sessions.ClearAdminID(w, r); err != nil {
-		http.Error(w, "Ошибка выхода", http.StatusInternalServerError)
+		serverError(w, r, "Ошибка выхода", err)
 		return
 	}
 	http.Redirect(w, r, "/admin/login", http.StatusFound)
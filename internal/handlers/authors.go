@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/* ========= СОАВТОРЫ ЗАЯВКИ ========= */
+
+// parseAuthors читает список авторов из формы подачи: поле authors — JSON-массив
+// [{surname, given_names, affiliation, orcid, corresponding}] в порядке следования.
+// Старые клиенты присылают одно поле author — оно становится единственным автором.
+// Ошибки полей — с путём вида authors[2].orcid (номер с единицы, как Position).
+func parseAuthors(r *http.Request) ([]models.ArticleAuthor, *Error) {
+	var list []models.ArticleAuthor
+	if raw := r.FormValue("authors"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &list); err != nil {
+			return nil, errValidation("authors", FieldInvalid, "Некорректный список авторов")
+		}
+	} else if a := strings.TrimSpace(r.FormValue("author")); a != "" {
+		list = []models.ArticleAuthor{{Surname: a}}
+	}
+
+	if len(list) == 0 {
+		return nil, errValidation("authors", FieldRequired, "Укажите хотя бы одного автора")
+	}
+	if len(list) > models.MaxArticleAuthors {
+		return nil, errValidation("authors", FieldTooMany, "Не больше %d авторов", models.MaxArticleAuthors)
+	}
+
+	var v validation
+
+	corresponding := -1
+	for i := range list {
+		a := &list[i]
+		a.Position = i + 1
+		a.Surname = strings.TrimSpace(a.Surname)
+		a.GivenNames = strings.TrimSpace(a.GivenNames)
+		a.Affiliation = strings.TrimSpace(a.Affiliation)
+		field := fmt.Sprintf("authors[%d].", a.Position)
+		if a.Surname == "" {
+			v.add(field+"surname", FieldRequired, "Автор %d: укажите фамилию", a.Position)
+		}
+		if a.ORCID != "" {
+			a.ORCID = models.NormalizeORCID(a.ORCID)
+			if !models.ValidORCID(a.ORCID) {
+				v.add(field+"orcid", FieldInvalid, "Автор %d: некорректный ORCID (формат 0000-0000-0000-000X)", a.Position)
+			}
+		}
+		if a.Corresponding {
+			if corresponding >= 0 {
+				v.add(field+"corresponding", FieldInvalid, "Контактным может быть только один автор")
+			}
+			corresponding = i
+		}
+	}
+	if err := v.result(); err != nil {
+		return nil, err
+	}
+	// Не отмечен никто — контактным считается первый автор
+	if corresponding < 0 {
+		list[0].Corresponding = true
+	}
+	return list, nil
+}
+
+// contactName — полное имя контактного автора (для обращения в письмах)
+func contactName(list []models.ArticleAuthor) string {
+	for _, a := range list {
+		if a.Corresponding {
+			return a.FullName()
+		}
+	}
+	return models.AuthorsLine(list)
+}
+
+// insertArticleAuthors сохраняет авторов заявки (в транзакции подачи) и
+// связывает каждого с записью реестра авторов
+func insertArticleAuthors(ctx context.Context, q queryer, articleID int, list []models.ArticleAuthor) error {
+	for _, a := range list {
+		authorID, err := resolveAuthor(ctx, q, a)
+		if err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO article_authors (article_id, position, surname, given_names, affiliation, orcid, corresponding, author_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			articleID, a.Position, a.Surname, a.GivenNames, a.Affiliation, a.ORCID, a.Corresponding, authorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadArticleAuthors — авторы заявки по порядку
+func loadArticleAuthors(ctx context.Context, articleID int) ([]models.ArticleAuthor, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT position, surname, given_names, affiliation, orcid, corresponding
+		FROM article_authors
+		WHERE article_id = $1
+		ORDER BY position`, articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.ArticleAuthor
+	for rows.Next() {
+		var a models.ArticleAuthor
+		if err := rows.Scan(&a.Position, &a.Surname, &a.GivenNames, &a.Affiliation, &a.ORCID, &a.Corresponding); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
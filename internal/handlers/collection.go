@@ -1,48 +1,43 @@
 package handlers
 
 import (
+	"BookCollect/internal/cache"
+	"BookCollect/internal/covergen"
 	"BookCollect/internal/db"
+	"BookCollect/internal/filetype"
+	"BookCollect/internal/logging"
 	"BookCollect/internal/models"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"io"
+	"github.com/lib/pq"
 	"net/http"
 	"os"
-	"path/filepath"
+	"slices"
 	"strconv"
+	"time"
 )
 
 // ---------- PUBLIC API (JSON) ----------
 
 func GetCollections(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.DB.Query(`
-		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path
-		FROM collections
-		ORDER BY id DESC`)
+	snap, err := listCollections(r.Context())
 	if err != nil {
-		http.Error(w, "Ошибка запроса: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var out []models.CollectionResponse
-	for rows.Next() {
-		var c models.Collection
-		if err := rows.Scan(
-			&c.ID,
-			&c.ReleaseNumber,
-			&c.ReleaseYear,
-			&c.Title,
-			&c.Description,
-			&c.CoverImage,
-			&c.PublicationLink,
-			&c.PDFPath,
-		); err != nil {
-			http.Error(w, "Ошибка чтения строк: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+		serverError(w, r, "Ошибка запроса", err)
+		return
+	}
+	// без Last-Modified: удаление не сдвигает MAX(updated_at), по If-Modified-Since
+	// клиент получил бы 304 со списком, где удалённый сборник ещё есть
+	if notModified(w, r, etag("api/collections", len(snap.List), snap.Modified), time.Time{}) {
+		return
+	}
+
+	// пустой список — [], а не null (см. CollectionList в openapi.json)
+	out := make([]models.CollectionResponse, 0, len(snap.List))
+	for _, c := range snap.List {
 		out = append(out, models.CollectionToResponse(c))
 	}
 
@@ -50,28 +45,77 @@ func GetCollections(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(out)
 }
 
+// collectionsKey — ключ кэша списка сборников
+const collectionsKey = "collections"
+
+// collectionsSnapshot — список сборников и его версия для ETag: кэшируются
+// вместе, поэтому ETag всегда описывает именно то тело, что будет отдано,
+// даже если запись в кэше успела устареть
+type collectionsSnapshot struct {
+	List     []models.Collection `json:"list"`
+	Modified time.Time           `json:"modified"` // MAX(updated_at); удаление меняет len(List)
+}
+
+// listCollections — все сборники, новые первыми (страница /collections и
+// GET /api/collections). Через кэш; сбрасывают его CreateCollection,
+// UpdateCollection и DeleteCollection.
+func listCollections(ctx context.Context) (collectionsSnapshot, error) {
+	return cache.Fetch(ctx, collectionsKey, func(ctx context.Context) (collectionsSnapshot, error) {
+		// список и версия — из одного запроса, то есть из одного снимка БД
+		rows, err := db.DB.QueryContext(ctx, `
+			SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path, cover_variants, updated_at
+			FROM collections
+			ORDER BY id DESC`)
+		if err != nil {
+			return collectionsSnapshot{}, err
+		}
+		defer rows.Close()
+
+		snap := collectionsSnapshot{List: make([]models.Collection, 0, 16)}
+		for rows.Next() {
+			var c models.Collection
+			var updated time.Time
+			if err := rows.Scan(
+				&c.ID, &c.ReleaseNumber, &c.ReleaseYear, &c.Title, &c.Description,
+				&c.CoverImage, &c.PublicationLink, &c.PDFPath, &c.CoverVariants, &updated,
+			); err != nil {
+				return collectionsSnapshot{}, err
+			}
+			if updated.After(snap.Modified) {
+				snap.Modified = updated
+			}
+			snap.List = append(snap.List, c)
+		}
+		return snap, rows.Err()
+	})
+}
+
 func GetCollectionByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
-	row := db.DB.QueryRow(`
-		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path
+	row := db.DB.QueryRowContext(r.Context(), `
+		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path, cover_variants, updated_at
 		FROM collections
 		WHERE id = $1`, id)
 
 	var c models.Collection
+	var modified time.Time
 	if err := row.Scan(
 		&c.ID, &c.ReleaseNumber, &c.ReleaseYear, &c.Title, &c.Description,
-		&c.CoverImage, &c.PublicationLink, &c.PDFPath,
+		&c.CoverImage, &c.PublicationLink, &c.PDFPath, &c.CoverVariants, &modified,
 	); err == sql.ErrNoRows {
-		http.Error(w, "Сборник не найден", http.StatusNotFound)
+		writeError(w, r, errNotFound("Сборник не найден"))
 		return
 	} else if err != nil {
-		http.Error(w, "Ошибка запроса: "+err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Ошибка запроса", err)
+		return
+	}
+	if notModified(w, r, etag("api/collection", id, modified), modified) {
 		return
 	}
 
@@ -79,17 +123,18 @@ func GetCollectionByID(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(models.CollectionToResponse(c))
 }
 
-// ---------- ADMIN (multipart create; JSON update/delete) ----------
+// ---------- ADMIN (multipart create; JSON update/delete; замена файлов — multipart) ----------
+// Контракт — internal/openapi/openapi.json
 
 func CreateCollection(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		http.Error(w, "Ошибка парсинга формы: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, errInvalidForm)
 		return
 	}
 
 	title := r.FormValue("title")
 	if title == "" {
-		http.Error(w, "Поле 'title' обязательно", http.StatusBadRequest)
+		writeError(w, r, errValidation("title", FieldRequired, "Укажите название"))
 		return
 	}
 	description := r.FormValue("description")
@@ -111,44 +156,75 @@ func CreateCollection(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// ensure dirs
-	_ = os.MkdirAll("uploads/covers", 0755)
-	_ = os.MkdirAll("uploads/pdfs", 0755)
+	// Проверяем содержимое файлов до записи на диск
+	cover, e := formUpload(r, "cover", filetype.JPEG, filetype.PNG, filetype.WebP)
+	if e != nil {
+		writeError(w, r, e)
+		return
+	}
+	if cover != nil {
+		defer cover.Close()
+	}
+	pdf, e := formUpload(r, "pdf", filetype.PDF)
+	if e != nil {
+		writeError(w, r, e)
+		return
+	}
+	if pdf != nil {
+		defer pdf.Close()
+	}
 
 	// cover (optional)
 	coverPath := ""
-	if file, hdr, err := r.FormFile("cover"); err == nil {
-		defer file.Close()
-		dstPath := filepath.Join("uploads", "covers", filepath.Base(hdr.Filename))
-		if dst, err := os.Create(dstPath); err == nil {
-			defer dst.Close()
-			_, _ = io.Copy(dst, file)
-			coverPath = "/" + filepath.ToSlash(dstPath) // чтобы открыть через /uploads/...
+	var coverVariants models.CoverVariants
+	if cover != nil {
+		p, variants, err := storeCover(r.Context(), cover)
+		if err != nil {
+			writeError(w, r, newError(http.StatusUnprocessableEntity, CodeUnprocessable, "Не удалось обработать обложку").
+				withField("cover", FieldInvalid, "Не удалось обработать обложку"))
+			return
 		}
+		coverPath, coverVariants = p, variants
 	}
 
 	// pdf (optional)
 	pdfPath := ""
-	if file, hdr, err := r.FormFile("pdf"); err == nil {
-		defer file.Close()
-		dstPath := filepath.Join("uploads", "pdfs", filepath.Base(hdr.Filename))
-		if dst, err := os.Create(dstPath); err == nil {
-			defer dst.Close()
-			_, _ = io.Copy(dst, file)
-			pdfPath = "/" + filepath.ToSlash(dstPath)
+	if pdf != nil {
+		p, err := storeUpload(r.Context(), pdf, "pdfs", "collection")
+		if err != nil {
+			removeCover(r.Context(), coverVariants)
+			serverError(w, r, "Не удалось сохранить PDF", err)
+			return
+		}
+		pdfPath = p
+	}
+
+	// Без обложки — генерируем: из первой страницы PDF или типографскую.
+	// Ошибка генерации не мешает созданию сборника.
+	if cover == nil && covergen.Enabled {
+		p, variants, err := storeGeneratedCover(r.Context(), pdfPath, covergen.Info{
+			Title: title, ReleaseYear: releaseYear, ReleaseNumber: releaseNumber,
+		})
+		if err != nil {
+			logging.From(r).Warn("covergen failed", "title", title, "err", err)
+		} else {
+			coverPath, coverVariants = p, variants
 		}
 	}
 
 	var id int
-	if err := db.DB.QueryRow(`
-		INSERT INTO collections (release_number, release_year, title, description, cover_image, publication_link, pdf_path)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	if err := db.DB.QueryRowContext(r.Context(), `
+		INSERT INTO collections (release_number, release_year, title, description, cover_image, publication_link, pdf_path, cover_variants)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id`,
-		releaseNumber, releaseYear, title, description, coverPath, publicationLink, pdfPath,
+		releaseNumber, releaseYear, title, description, coverPath, publicationLink, pdfPath, coverVariants,
 	).Scan(&id); err != nil {
-		http.Error(w, "Ошибка вставки: "+err.Error(), http.StatusInternalServerError)
+		removeCover(r.Context(), coverVariants)
+		removeUpload(r.Context(), pdfPath)
+		serverError(w, r, "Ошибка вставки", err)
 		return
 	}
+	cache.Invalidate(r.Context(), collectionsKey)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -160,39 +236,47 @@ func CreateCollection(w http.ResponseWriter, r *http.Request) {
 
 func UpdateCollection(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		writeError(w, r, newError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Метод не разрешён"))
 		return
 	}
 
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
+	body, e := readJSONBody(w, r, "CollectionUpdate")
+	if e != nil {
+		writeError(w, r, e)
+		return
+	}
 	var in models.CollectionRequest
-	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		http.Error(w, "Неверный JSON: "+err.Error(), http.StatusBadRequest)
+	if err := json.Unmarshal(body, &in); err != nil {
+		writeError(w, r, errInvalidJSON)
 		return
 	}
 
-	if _, err := db.DB.Exec(`
+	res, err := db.DB.ExecContext(r.Context(), `
 		UPDATE collections SET
 			release_number = $1,
 			release_year = $2,
 			title = $3,
 			description = $4,
-			cover_image = $5,
-			publication_link = $6,
-			pdf_path = $7
-		WHERE id = $8`,
-		in.ReleaseNumber, in.ReleaseYear, in.Title, in.Description,
-		in.CoverImage, in.PublicationLink, in.PDFPath, id,
-	); err != nil {
-		http.Error(w, "Ошибка обновления: "+err.Error(), http.StatusInternalServerError)
+			publication_link = $5
+		WHERE id = $6`,
+		in.ReleaseNumber, in.ReleaseYear, in.Title, in.Description, in.PublicationLink, id,
+	)
+	if err != nil {
+		serverError(w, r, "Ошибка обновления", err)
 		return
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		writeError(w, r, errNotFound("Сборник с ID %d не найден", id))
+		return
+	}
+	cache.Invalidate(r.Context(), collectionsKey)
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{
@@ -200,25 +284,173 @@ func UpdateCollection(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ReplaceCollectionCover — PUT /admin/collection/{id}/cover: новая обложка
+// (поле cover) со всеми вариантами ширин; старые файлы удаляются.
+func ReplaceCollectionCover(w http.ResponseWriter, r *http.Request) {
+	id, cover, ok := replaceUpload(w, r, "cover", filetype.JPEG, filetype.PNG, filetype.WebP)
+	if !ok {
+		return
+	}
+	defer cover.Close()
+
+	coverPath, variants, err := storeCover(r.Context(), cover)
+	if err != nil {
+		writeError(w, r, newError(http.StatusUnprocessableEntity, CodeUnprocessable, "Не удалось обработать обложку").
+			withField("cover", FieldInvalid, "Не удалось обработать обложку"))
+		return
+	}
+
+	// старые значения — из той же строки до UPDATE (подзапрос видит снимок до изменения)
+	var oldVariants models.CoverVariants
+	err = db.DB.QueryRowContext(r.Context(), `
+		UPDATE collections c SET cover_image = $1, cover_variants = $2
+		FROM (SELECT id, cover_variants FROM collections WHERE id = $3 FOR UPDATE) old
+		WHERE c.id = old.id
+		RETURNING old.cover_variants`,
+		coverPath, variants, id,
+	).Scan(&oldVariants)
+	if err != nil {
+		removeCover(r.Context(), variants)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, errNotFound("Сборник с ID %d не найден", id))
+			return
+		}
+		serverError(w, r, "Ошибка обновления", err)
+		return
+	}
+	removeCover(r.Context(), oldVariants)
+	cache.Invalidate(r.Context(), collectionsKey)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("Обложка сборника с ID %d заменена", id),
+	})
+}
+
+// ReplaceCollectionPDF — PUT /admin/collection/{id}/pdf: новый PDF (поле pdf);
+// старый файл удаляется.
+func ReplaceCollectionPDF(w http.ResponseWriter, r *http.Request) {
+	id, pdf, ok := replaceUpload(w, r, "pdf", filetype.PDF)
+	if !ok {
+		return
+	}
+	defer pdf.Close()
+
+	pdfPath, err := storeUpload(r.Context(), pdf, "pdfs", "collection")
+	if err != nil {
+		serverError(w, r, "Не удалось сохранить PDF", err)
+		return
+	}
+
+	var oldPath sql.NullString
+	err = db.DB.QueryRowContext(r.Context(), `
+		UPDATE collections c SET pdf_path = $1
+		FROM (SELECT id, pdf_path FROM collections WHERE id = $2 FOR UPDATE) old
+		WHERE c.id = old.id
+		RETURNING old.pdf_path`,
+		pdfPath, id,
+	).Scan(&oldPath)
+	if err != nil {
+		removeUpload(r.Context(), pdfPath)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, r, errNotFound("Сборник с ID %d не найден", id))
+			return
+		}
+		serverError(w, r, "Ошибка обновления", err)
+		return
+	}
+	removeUpload(r.Context(), oldPath.String)
+	cache.Invalidate(r.Context(), collectionsKey)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": fmt.Sprintf("PDF сборника с ID %d заменён", id),
+	})
+}
+
+// replaceUpload — общая часть замены файла: id из пути и обязательный файл
+// из поля field. При ошибке ответ уже записан и ok == false.
+func replaceUpload(w http.ResponseWriter, r *http.Request, field string, want ...filetype.Kind) (id int, u *upload, ok bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return 0, nil, false
+	}
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, r, errInvalidForm)
+		return 0, nil, false
+	}
+	u, e := formUpload(r, field, want...)
+	if e != nil {
+		writeError(w, r, e)
+		return 0, nil, false
+	}
+	if u == nil {
+		writeError(w, r, errValidation(field, FieldRequired, "Выберите файл"))
+		return 0, nil, false
+	}
+	return id, u, true
+}
+
+// DeleteCollection удаляет сборник вместе с файлами: обложкой со всеми
+// вариантами и PDF. Строка и регистрации файлов в uploaded_files удаляются
+// одной транзакцией, сами файлы — после фиксации.
 func DeleteCollection(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Некорректный ID", http.StatusBadRequest)
+		writeError(w, r, errInvalidID)
 		return
 	}
 
-	res, err := db.DB.Exec(`DELETE FROM collections WHERE id = $1`, id)
+	tx, err := db.DB.BeginTx(r.Context(), nil)
 	if err != nil {
-		http.Error(w, "Ошибка удаления: "+err.Error(), http.StatusInternalServerError)
+		serverError(w, r, "Ошибка удаления", err)
 		return
 	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		http.Error(w, fmt.Sprintf("Сборник с ID %d не найден", id), http.StatusNotFound)
+	defer tx.Rollback()
+
+	var coverPath, pdfPath sql.NullString
+	var variants models.CoverVariants
+	err = tx.QueryRowContext(r.Context(),
+		`DELETE FROM collections WHERE id = $1 RETURNING cover_image, pdf_path, cover_variants`, id,
+	).Scan(&coverPath, &pdfPath, &variants)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, r, errNotFound("Сборник с ID %d не найден", id))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка удаления", err)
 		return
 	}
 
+	paths := []string{coverPath.String, pdfPath.String}
+	for _, v := range variants {
+		paths = append(paths, v.Path)
+	}
+	var files []string
+	for _, p := range paths {
+		if p != "" && !slices.Contains(files, p) {
+			files = append(files, p)
+		}
+	}
+	if _, err := tx.ExecContext(r.Context(),
+		`DELETE FROM uploaded_files WHERE path = ANY($1)`, pq.Array(files)); err != nil {
+		serverError(w, r, "Ошибка удаления", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		serverError(w, r, "Ошибка удаления", err)
+		return
+	}
+	cache.Invalidate(r.Context(), collectionsKey)
+
+	// сборник уже удалён: неудавшееся удаление файла только в лог
+	for _, p := range files {
+		if err := os.Remove(localPath(p)); err != nil && !os.IsNotExist(err) {
+			logging.From(r).Warn("collection file not removed", "path", p, "err", err)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{
 		"message": fmt.Sprintf("Сборник с ID %d удалён", id),
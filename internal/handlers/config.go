@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"BookCollect/internal/config"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// cfg — настройки приложения; задаются один раз при старте (Configure)
+var cfg = config.Default()
+
+// Configure передаёт хендлерам проверенные настройки
+func Configure(c *config.Config) { cfg = c }
+
+// uploadAllowed — расширение рукописи разрешено настройками
+func uploadAllowed(ext string) bool {
+	return slices.Contains(cfg.Uploads.AllowedExt, ext)
+}
+
+// uploadFormats — «PDF, DOCX, ODT» для сообщений и подсказок
+func uploadFormats() string {
+	names := make([]string, len(cfg.Uploads.AllowedExt))
+	for i, ext := range cfg.Uploads.AllowedExt {
+		names[i] = strings.ToUpper(strings.TrimPrefix(ext, "."))
+	}
+	return strings.Join(names, ", ")
+}
+
+// errTooLarge — 413 с лимитом из настроек
+func errTooLarge() *Error {
+	return newError(http.StatusRequestEntityTooLarge, CodeTooLarge, "Слишком большой файл (лимит %d МБ)", cfg.Uploads.MaxSizeMB)
+}
+
+// uploadHints — данные для форм загрузки рукописи
+func uploadHints() map[string]any {
+	return map[string]any{
+		"Formats": uploadFormats(),
+		"Accept":  strings.Join(cfg.Uploads.AllowedExt, ","),
+		"MaxMB":   cfg.Uploads.MaxSizeMB,
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/models"
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+/* ========= АВТОРЫ: ПУБЛИЧНЫЕ СТРАНИЦЫ ========= */
+
+// Опубликованной считается принятая статья, включённая в сборник
+const publishedSQL = `a.status = 'accepted' AND a.collection_id IS NOT NULL`
+
+// PaperAuthor — автор в списке статей; AuthorID nil, если запись не связана с реестром
+type PaperAuthor struct {
+	AuthorID *int
+	Name     string
+}
+
+// Paper — опубликованная статья для публичных страниц
+type Paper struct {
+	ID              int
+	Title           string
+	Authors         []PaperAuthor
+	CollectionID    int
+	CollectionTitle string
+	ReleaseYear     *int
+	ReleaseNumber   *int
+}
+
+// AuthorEntry — строка каталога авторов
+type AuthorEntry struct {
+	ID         int
+	Name       string
+	ORCID      string
+	Papers     int
+	FirstIndex string // первая буква фамилии — для заголовков каталога
+}
+
+// attachPaperAuthors дописывает авторов к статьям одним запросом
+func attachPaperAuthors(ctx context.Context, papers []Paper) error {
+	if len(papers) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(papers))
+	idx := make(map[int]int, len(papers))
+	for i, p := range papers {
+		ids[i] = int64(p.ID)
+		idx[p.ID] = i
+	}
+
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT article_id, author_id, surname, given_names
+		FROM article_authors
+		WHERE article_id = ANY($1)
+		ORDER BY article_id, position`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var articleID int
+		var authorID sql.NullInt64
+		var a models.ArticleAuthor
+		if err := rows.Scan(&articleID, &authorID, &a.Surname, &a.GivenNames); err != nil {
+			return err
+		}
+		pa := PaperAuthor{Name: a.ShortName()}
+		if authorID.Valid {
+			id := int(authorID.Int64)
+			pa.AuthorID = &id
+		}
+		p := &papers[idx[articleID]]
+		p.Authors = append(p.Authors, pa)
+	}
+	return rows.Err()
+}
+
+// queryPapers — опубликованные статьи по условию where (к нему добавляется publishedSQL)
+func queryPapers(ctx context.Context, where string, args ...any) ([]Paper, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT a.id, a.title, c.id, c.title, c.release_year, c.release_number
+		FROM articles a
+		JOIN collections c ON c.id = a.collection_id
+		WHERE `+publishedSQL+` AND `+where+`
+		ORDER BY c.release_year DESC NULLS LAST, c.release_number DESC NULLS LAST, a.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var papers []Paper
+	for rows.Next() {
+		var p Paper
+		if err := rows.Scan(&p.ID, &p.Title, &p.CollectionID, &p.CollectionTitle, &p.ReleaseYear, &p.ReleaseNumber); err != nil {
+			return nil, err
+		}
+		papers = append(papers, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return papers, attachPaperAuthors(ctx, papers)
+}
+
+// loadCollectionPapers — статьи выпуска (для страницы сборника)
+func loadCollectionPapers(ctx context.Context, collectionID int) ([]Paper, error) {
+	return queryPapers(ctx, `a.collection_id = $1`, collectionID)
+}
+
+// ShowAuthorsPage — каталог авторов с опубликованными статьями; ?q= — поиск по фамилии
+// (кириллицей или латиницей: сравнение идёт по ключу реестра)
+func ShowAuthorsPage(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT au.id, au.surname, au.given_names, au.orcid, COUNT(DISTINCT a.id)
+		FROM authors au
+		JOIN article_authors aa ON aa.author_id = au.id
+		JOIN articles a ON a.id = aa.article_id
+		WHERE au.merged_into IS NULL AND `+publishedSQL+`
+		  AND ($1 = '' OR au.name_key LIKE $1 || '%')
+		GROUP BY au.id
+		ORDER BY au.surname, au.given_names`, searchKey(q))
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	var list []AuthorEntry
+	for rows.Next() {
+		var e AuthorEntry
+		var a models.ArticleAuthor
+		if err := rows.Scan(&e.ID, &a.Surname, &a.GivenNames, &e.ORCID, &e.Papers); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		e.Name = a.ShortName()
+		if rs := []rune(strings.ToUpper(a.Surname)); len(rs) > 0 {
+			e.FirstIndex = string(rs[0])
+		}
+		list = append(list, e)
+	}
+
+	render(w, r,
+		"authors.html",
+		map[string]any{
+			"Title":   "Авторы",
+			"Year":    time.Now().Year(),
+			"Authors": list,
+			"Query":   q,
+		},
+	)
+}
+
+// ShowAuthorPage — автор и его статьи по всем сборникам
+func ShowAuthorPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var a models.ArticleAuthor
+	var mergedInto sql.NullInt64
+	err = db.DB.QueryRowContext(r.Context(), `
+		SELECT surname, given_names, orcid, merged_into FROM authors WHERE id = $1`, id).
+		Scan(&a.Surname, &a.GivenNames, &a.ORCID, &mergedInto)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	// Дубликат после объединения — постоянный редирект на основную запись
+	if mergedInto.Valid {
+		http.Redirect(w, r, "/authors/"+strconv.FormatInt(mergedInto.Int64, 10), http.StatusMovedPermanently)
+		return
+	}
+
+	papers, err := queryPapers(r.Context(), `EXISTS (
+		SELECT 1 FROM article_authors aa WHERE aa.article_id = a.id AND aa.author_id = $1)`, id)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if len(papers) == 0 {
+		// Без опубликованных статей страницы автора нет (данные заявок не раскрываем)
+		http.NotFound(w, r)
+		return
+	}
+
+	var affiliations []string
+	if rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT DISTINCT aa.affiliation
+		FROM article_authors aa JOIN articles a ON a.id = aa.article_id
+		WHERE aa.author_id = $1 AND aa.affiliation <> '' AND `+publishedSQL+`
+		ORDER BY 1`, id); err == nil {
+		for rows.Next() {
+			var s string
+			if rows.Scan(&s) == nil {
+				affiliations = append(affiliations, s)
+			}
+		}
+		rows.Close()
+	}
+
+	render(w, r,
+		"author.html",
+		map[string]any{
+			"Title":        a.FullName(),
+			"Year":         time.Now().Year(),
+			"AuthorID":     id,
+			"Name":         a.FullName(),
+			"ORCID":        a.ORCID,
+			"Affiliations": affiliations,
+			"Papers":       papers,
+			"Meta": map[string]string{
+				"description": "Статьи автора " + a.ShortName() + " в сборниках",
+			},
+		},
+	)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"BookCollect/internal/logging"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+/* ========= ОШИБКИ ========= */
+
+// Все ошибки хендлеров — *Error и уходят клиенту через writeError:
+//   - API (fetch, XHR, curl) — application/problem+json по RFC 9457 (бывш. 7807)
+//     с машинным кодом code и ошибками полей errors[];
+//   - переход браузера по ссылке или отправка формы (Accept: text/html) —
+//     текстом, как раньше.
+// Msg — сообщение на русском; оно же ключ перевода (messages.go), язык —
+// из Accept-Language. Причина внутренней ошибки (текст err, SQL, пути)
+// уходит только в лог запроса с request_id.
+
+// Коды ошибок — стабильная часть ответа: клиенты ветвятся по ним, а не по тексту
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidID        = "invalid_id"
+	CodeInvalidJSON      = "invalid_json"
+	CodeValidation       = "validation_failed"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeGone             = "gone"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeTooLarge         = "payload_too_large"
+	CodeUnsupportedType  = "unsupported_media_type"
+	CodeUnprocessable    = "unprocessable"
+	CodeMalware          = "malware_detected"
+	CodeRateLimited      = "rate_limited"
+	CodeUnavailable      = "service_unavailable"
+	CodeInternal         = "internal"
+)
+
+// Коды ошибок полей
+const (
+	FieldRequired = "required"
+	FieldInvalid  = "invalid"
+	FieldTooMany  = "too_many"
+	FieldUnknown  = "unknown" // поля нет в схеме запроса
+)
+
+// problemTypeBase — префикс type: urn:bookcollect:problem:<code>
+const problemTypeBase = "urn:bookcollect:problem:"
+
+// Error — ошибка ответа: HTTP-статус, код, сообщение с аргументами для
+// fmt.Sprintf, ошибки отдельных полей и внутренняя причина (только для лога)
+type Error struct {
+	Status int
+	Code   string
+	Msg    string
+	Args   []any
+	Fields []FieldError
+	Err    error
+}
+
+// FieldError — ошибка одного поля: field — имя поля формы / JSON ("email", "authors[2].orcid")
+type FieldError struct {
+	Field string
+	Code  string
+	Msg   string
+	Args  []any
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf(e.Msg, e.Args...)
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// newError — ошибка с сообщением msg (формат fmt, аргументы args)
+func newError(status int, code, msg string, args ...any) *Error {
+	return &Error{Status: status, Code: code, Msg: msg, Args: args}
+}
+
+// withField — копия ошибки с ещё одной ошибкой поля (общие значения вроде errInvalidID не меняются)
+func (e *Error) withField(field, code, msg string, args ...any) *Error {
+	c := *e
+	c.Fields = append(slices.Clip(e.Fields), FieldError{Field: field, Code: code, Msg: msg, Args: args})
+	return &c
+}
+
+// Частые ошибки
+var (
+	errInvalidID   = newError(http.StatusBadRequest, CodeInvalidID, "Некорректный ID")
+	errInvalidJSON = newError(http.StatusBadRequest, CodeInvalidJSON, "Неверный JSON")
+	errInvalidForm = newError(http.StatusBadRequest, CodeBadRequest, "Ошибка парсинга формы")
+)
+
+func errNotFound(msg string, args ...any) *Error {
+	return newError(http.StatusNotFound, CodeNotFound, msg, args...)
+}
+
+func errConflict(msg string, args ...any) *Error {
+	return newError(http.StatusConflict, CodeConflict, msg, args...)
+}
+
+// errValidation — 400 с ошибками полей; общее сообщение — первая из них,
+// чтобы клиенту, который читает только detail, было что показать
+func errValidation(field, code, msg string, args ...any) *Error {
+	return newError(http.StatusBadRequest, CodeValidation, msg, args...).withField(field, code, msg, args...)
+}
+
+// validation — накопитель ошибок полей: проверки идут все, ответ — один
+type validation struct{ err *Error }
+
+func (v *validation) add(field, code, msg string, args ...any) {
+	if v.err == nil {
+		v.err = errValidation(field, code, msg, args...)
+		return
+	}
+	v.err = v.err.withField(field, code, msg, args...)
+}
+
+// result — nil, если ошибок нет
+func (v *validation) result() *Error { return v.err }
+
+// errInternal — 500; msg уходит клиенту, err — только в лог
+func errInternal(msg string, err error) *Error {
+	e := newError(http.StatusInternalServerError, CodeInternal, msg)
+	e.Err = err
+	return e
+}
+
+// errNoDB — db.DB ещё не открыт (обработчик вызван до InitDB)
+var errNoDB = errors.New("db not initialized")
+
+// serverError — 500: причина в лог, клиенту — общий текст
+func serverError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	writeError(w, r, errInternal(msg, err))
+}
+
+// problem — тело application/problem+json
+type problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      string         `json:"code"`
+	Errors    []fieldProblem `json:"errors,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+type fieldProblem struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// writeError отвечает ошибкой err. Не *Error считается внутренней ошибкой.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = errInternal("Ошибка сервера", err)
+	}
+	if e.Status >= 500 {
+		logging.From(r).Error(fmt.Sprintf(e.Msg, e.Args...), "code", e.Code, "err", e.Err)
+	}
+
+	lang := acceptLang(r)
+	detail := localize(lang, e.Msg, e.Args...)
+	w.Header().Set("Content-Language", lang)
+
+	if wantsHTML(r) {
+		http.Error(w, detail, e.Status)
+		return
+	}
+
+	p := problem{
+		Type:      problemTypeBase + e.Code,
+		Title:     statusTitle(lang, e.Status),
+		Status:    e.Status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      e.Code,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+	for _, f := range e.Fields {
+		p.Errors = append(p.Errors, fieldProblem{Field: f.Field, Code: f.Code, Detail: localize(lang, f.Msg, f.Args...)})
+	}
+	w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// wantsHTML — запрос от браузера без JS (ссылка, форма): fetch и XHR
+// по умолчанию шлют Accept: */*, навигация — text/html
+func wantsHTML(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/html") && !strings.Contains(accept, "json")
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+/* ========= БИБЛИОГРАФИЯ И ВЫГРУЗКА МЕТАДАННЫХ ========= */
+
+// articleMeta — всё, что нужно для ссылки на статью
+type articleMeta struct {
+	ID      int
+	Title   string
+	Year    int
+	Authors []models.ArticleAuthor
+}
+
+func loadArticleMeta(r *http.Request, id int) (*articleMeta, error) {
+	m := articleMeta{ID: id}
+	var created time.Time
+	if err := db.DB.QueryRowContext(r.Context(), `SELECT title, created_at FROM articles WHERE id = $1`, id).
+		Scan(&m.Title, &created); err != nil {
+		return nil, err
+	}
+	m.Year = created.Year()
+
+	authors, err := loadArticleAuthors(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	m.Authors = authors
+	return &m, nil
+}
+
+// gostCitation — библиографическая ссылка по ГОСТ Р 7.0.5:
+// "Иванов И. П., Петров А. А. Название // Издание. — 2025."
+func gostCitation(m *articleMeta) string {
+	var b strings.Builder
+	if len(m.Authors) > 0 {
+		b.WriteString(models.AuthorsLine(m.Authors))
+		b.WriteString(" ")
+	}
+	fmt.Fprintf(&b, "%s // %s. — %d.", m.Title, models.JournalTitle, m.Year)
+	return b.String()
+}
+
+// risRecord — запись RIS (Zotero, EndNote, Mendeley)
+func risRecord(m *articleMeta) string {
+	var b strings.Builder
+	b.WriteString("TY  - JOUR\r\n")
+	for _, a := range m.Authors {
+		fmt.Fprintf(&b, "AU  - %s, %s\r\n", a.Surname, a.GivenNames)
+	}
+	fmt.Fprintf(&b, "TI  - %s\r\n", m.Title)
+	fmt.Fprintf(&b, "T2  - %s\r\n", models.JournalTitle)
+	fmt.Fprintf(&b, "PY  - %d\r\n", m.Year)
+	fmt.Fprintf(&b, "ID  - article%d\r\n", m.ID)
+	b.WriteString("ER  - \r\n")
+	return b.String()
+}
+
+// bibtexEscape экранирует спецсимволы BibTeX
+var bibtexEscape = strings.NewReplacer(`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `&`, `\&`, `%`, `\%`, `$`, `\$`, `#`, `\#`, `_`, `\_`)
+
+func bibtexRecord(m *articleMeta) string {
+	names := make([]string, len(m.Authors))
+	for i, a := range m.Authors {
+		names[i] = bibtexEscape.Replace(a.Surname)
+		if a.GivenNames != "" {
+			names[i] += ", " + bibtexEscape.Replace(a.GivenNames)
+		}
+	}
+	return fmt.Sprintf("@article{article%d,\n  author  = {%s},\n  title   = {%s},\n  journal = {%s},\n  year    = {%d}\n}\n",
+		m.ID, strings.Join(names, " and "), bibtexEscape.Replace(m.Title), models.JournalTitle, m.Year)
+}
+
+// cslName / cslItem — CSL-JSON (citeproc, Zotero)
+type cslName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+	ORCID  string `json:"ORCID,omitempty"`
+}
+
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	ContainerTitle string    `json:"container-title"`
+	Author         []cslName `json:"author"`
+	Issued         struct {
+		DateParts [][]int `json:"date-parts"`
+	} `json:"issued"`
+}
+
+func cslRecord(m *articleMeta) cslItem {
+	it := cslItem{
+		ID:             "article" + strconv.Itoa(m.ID),
+		Type:           "article-journal",
+		Title:          m.Title,
+		ContainerTitle: models.JournalTitle,
+		Author:         make([]cslName, len(m.Authors)),
+	}
+	for i, a := range m.Authors {
+		it.Author[i] = cslName{Family: a.Surname, Given: a.GivenNames}
+		if a.ORCID != "" {
+			it.Author[i].ORCID = "https://orcid.org/" + a.ORCID
+		}
+	}
+	it.Issued.DateParts = [][]int{{m.Year}}
+	return it
+}
+
+// ADMIN: выгрузка метаданных статьи ?format=gost|ris|bibtex|csl (по умолчанию gost)
+func ExportArticleMetadata(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+	m, err := loadArticleMeta(r, id)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Заявка не найдена"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	name := "article" + strconv.Itoa(id)
+	switch r.URL.Query().Get("format") {
+	case "", "gost":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(gostCitation(m) + "\n"))
+	case "ris":
+		w.Header().Set("Content-Type", "application/x-research-info-systems; charset=utf-8")
+		w.Header().Set("Content-Disposition", contentDisposition("attachment", name+".ris"))
+		_, _ = w.Write([]byte(risRecord(m)))
+	case "bibtex":
+		w.Header().Set("Content-Type", "application/x-bibtex; charset=utf-8")
+		w.Header().Set("Content-Disposition", contentDisposition("attachment", name+".bib"))
+		_, _ = w.Write([]byte(bibtexRecord(m)))
+	case "csl":
+		w.Header().Set("Content-Type", "application/vnd.citationstyles.csl+json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode([]cslItem{cslRecord(m)})
+	default:
+		writeError(w, r, errValidation("format", FieldInvalid, "Неизвестный формат: gost, ris, bibtex или csl"))
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+/* ========= ПРОВЕРКИ ЖИВОСТИ И ГОТОВНОСТИ ========= */
+
+// Healthz — процесс жив и отвечает (liveness); зависимости не проверяются,
+// чтобы оркестратор не перезапускал приложение из-за недоступной БД
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+}
+
+// readinessChecks — проверки готовности; пустая строка — ок, иначе причина
+var readinessChecks = []struct {
+	name  string
+	check func(ctx context.Context) string
+}{
+	{"db", checkDB},
+	{"uploads", checkUploads},
+	{"migrations", checkMigrations},
+}
+
+func checkDB(ctx context.Context) string {
+	if db.DB == nil {
+		return "not initialized"
+	}
+	if err := db.DB.PingContext(ctx); err != nil {
+		return "ping failed"
+	}
+	return ""
+}
+
+// checkUploads — в uploads/ можно создать файл (том смонтирован и доступен на запись)
+func checkUploads(context.Context) string {
+	f, err := os.CreateTemp("uploads", ".readyz-*")
+	if err != nil {
+		return "not writable"
+	}
+	name := f.Name()
+	f.Close()
+	_ = os.Remove(name)
+	return ""
+}
+
+func checkMigrations(ctx context.Context) string {
+	if db.DB == nil {
+		return "not initialized"
+	}
+	pending, err := db.PendingMigrations(ctx)
+	if err != nil {
+		return "check failed"
+	}
+	if len(pending) > 0 {
+		return "not applied: " + strings.Join(pending, ", ")
+	}
+	return ""
+}
+
+// Readyz — приложение готово принимать запросы (readiness): БД, uploads, схема.
+// 503, если хоть одна проверка не прошла; детали — в checks.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	status, code := "ok", http.StatusOK
+	checks := make(map[string]string, len(readinessChecks))
+	for _, c := range readinessChecks {
+		if msg := c.check(ctx); msg != "" {
+			checks[c.name] = msg
+			status, code = "fail", http.StatusServiceUnavailable
+		} else {
+			checks[c.name] = "ok"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": status, "checks": checks})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/sessions"
+	"BookCollect/internal/views"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ========= HTTP-КЭШ ========= */
+
+// Сборники меняются редко, а читаются постоянно. Ответы о них несут ETag и
+// Last-Modified из collections.updated_at (его обновляют триггеры, в том числе
+// при изменении статей сборника — 013_collection_updated_at.sql); повторный
+// запрос с If-None-Match / If-Modified-Since получает 304 без выборки и рендера.
+// Списки отдают только ETag: удаление сборника не меняет MAX(updated_at), и
+// по дате клиент не узнал бы о нём, а ETag учитывает ещё и число строк.
+// Cache-Control задаёт CachePolicy по пути; хендлер может переопределить.
+
+// Политики Cache-Control
+const (
+	cacheStatic   = "public, max-age=86400" // адреса с отпечатком views.Static делает immutable
+	cacheUploads  = "public, max-age=86400"
+	cacheDocs     = "public, max-age=3600"
+	cachePublic   = "public, no-cache"    // публичный JSON: всегда сверяться по ETag — его же читает админка
+	cachePartner  = "private, max-age=60" // /api/v1: ответ зависит от ключа (лимиты), общим кэшам нельзя
+	cachePage     = "no-cache"            // HTML: всегда сверяться (шапка зависит от входа)
+	cachePrivate  = "no-store"            // админка, кабинеты, ссылки отслеживания
+	cacheNoChange = ""                    // не трогать (хендлер решает сам)
+)
+
+// cachePolicies — политика по префиксу пути; первый подходящий, иначе cachePage
+var cachePolicies = []struct{ prefix, value string }{
+	{"/static/", cacheStatic},
+	{"/uploads/", cacheUploads},
+	{"/api/openapi.json", cacheDocs},
+	{"/api/docs", cacheDocs},
+	{"/api/v1/", cachePartner},
+	{"/api/", cachePublic},
+	{"/admin", cachePrivate},
+	{"/reviewer", cachePrivate},
+	{"/submission/", cachePrivate},
+	{"/healthz", cacheNoChange},
+	{"/readyz", cacheNoChange},
+	{"/metrics", cacheNoChange},
+}
+
+// CachePolicy — Cache-Control по таблице cachePolicies. Ответы на POST, PUT
+// и DELETE не кэшируются.
+func CachePolicy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch v := cachePolicyFor(r); v {
+		case cacheNoChange:
+		case cachePage:
+			w.Header().Set("Cache-Control", v)
+			w.Header().Set("Vary", "Cookie")
+		default:
+			w.Header().Set("Cache-Control", v)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func cachePolicyFor(r *http.Request) string {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return cachePrivate
+	}
+	for _, p := range cachePolicies {
+		if strings.HasPrefix(r.URL.Path, p.prefix) {
+			return p.value
+		}
+	}
+	return cachePage
+}
+
+// buildID входит в каждый ETag: после выкладки новой версии (другие шаблоны,
+// другой формат JSON) старые ETag не совпадают. Без данных VCS в сборке — время старта.
+var buildID = func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		var rev, modified string
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				rev = s.Value
+			case "vcs.modified":
+				modified = s.Value
+			}
+		}
+		if rev != "" && modified != "true" {
+			return rev
+		}
+	}
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}()
+
+// etag — слабый ETag из частей, от которых зависит тело ответа
+func etag(parts ...any) string {
+	h := sha256.New()
+	fmt.Fprint(h, buildID)
+	for _, p := range parts {
+		fmt.Fprintf(h, "\x00%v", p)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+// pageETag — ETag HTML-страницы: шапка отличается для вошедших администратора и рецензента.
+// Шаблоны с диска (WEB_DIR) могут поменяться в любой момент — тогда ETag каждый раз новый.
+func pageETag(r *http.Request, parts ...any) string {
+	_, isAdmin := sessions.GetAdminID(r)
+	_, isReviewer := sessions.GetReviewerID(r)
+	parts = append(parts, isAdmin, isReviewer)
+	if views.Reload() {
+		parts = append(parts, time.Now().UnixNano())
+	}
+	return etag(parts...)
+}
+
+// notModified ставит ETag и Last-Modified и, если у клиента та же версия,
+// отвечает 304 и возвращает true. Порядок проверок — RFC 9110, 13.2.2:
+// If-None-Match важнее If-Modified-Since. Нулевое modified — без Last-Modified,
+// If-Modified-Since не проверяется.
+func notModified(w http.ResponseWriter, r *http.Request, tag string, modified time.Time) bool {
+	h := w.Header()
+	h.Set("ETag", tag)
+	if !modified.IsZero() {
+		h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	match := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		match = etagMatch(inm, tag)
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		t, err := http.ParseTime(ims)
+		// в заголовке — целые секунды
+		match = err == nil && !modified.Truncate(time.Second).After(t)
+	}
+	if !match {
+		return false
+	}
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatch — слабое сравнение со списком из If-None-Match ("*" — любой)
+func etagMatch(header, tag string) bool {
+	want := strings.TrimPrefix(tag, "W/")
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// collectionsVersion — состояние таблицы сборников для ETag страницы списка
+// /api/v1 (без кэша): удаление меняет число строк, остальные изменения — MAX(updated_at)
+func collectionsVersion(ctx context.Context) (count int, modified time.Time, err error) {
+	err = db.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(MAX(updated_at), 'epoch') FROM collections`).Scan(&count, &modified)
+	return count, modified, err
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"BookCollect/internal/db/dbtest"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Удалили самый новый сборник: MAX(updated_at) стал меньше, чем у клиента в
+// If-Modified-Since, но список изменился — нужен 200, а не 304.
+func TestCollectionListAfterDeleteNotCached(t *testing.T) {
+	fake := withFakeDB(t)
+	older := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	newest := older.Add(time.Hour)
+
+	first, second := collectionRow(1, "Первый"), collectionRow(2, "Второй")
+	first[9], second[9] = older, newest
+	setRows := func(rows ...[]driver.Value) {
+		fake.On("FROM collections", dbtest.Result{Columns: collectionColumns, Rows: rows})
+		fake.On("LIMIT $1 OFFSET $2", dbtest.Result{Columns: collectionColumns[:9]})
+		var maxUpdated time.Time
+		for _, row := range rows {
+			if u := row[9].(time.Time); u.After(maxUpdated) {
+				maxUpdated = u
+			}
+		}
+		fake.On("COUNT(*)", dbtest.Result{
+			Columns: []string{"count", "max"},
+			Rows:    [][]driver.Value{{int64(len(rows)), maxUpdated}},
+		})
+	}
+
+	for _, tc := range []struct {
+		name, pattern string
+		h             http.HandlerFunc
+	}{
+		{"api", "/api/collections", GetCollections},
+		{"v1", "/api/v1/collections", V1Collections},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			get := func(header, value string) *httptest.ResponseRecorder {
+				r := chi.NewRouter()
+				r.Get(tc.pattern, tc.h)
+				req := httptest.NewRequest(http.MethodGet, tc.pattern, nil)
+				if header != "" {
+					req.Header.Set(header, value)
+				}
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				return rec
+			}
+
+			setRows(second, first)
+			rec := get("", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			if lm := rec.Header().Get("Last-Modified"); lm != "" {
+				t.Errorf("list sent Last-Modified %q", lm)
+			}
+			tag := rec.Header().Get("ETag")
+			if rec := get("If-None-Match", tag); rec.Code != http.StatusNotModified {
+				t.Errorf("same ETag: status = %d, want 304", rec.Code)
+			}
+
+			setRows(first) // DELETE самого нового
+			if rec := get("If-Modified-Since", newest.Format(http.TimeFormat)); rec.Code != http.StatusOK {
+				t.Errorf("If-Modified-Since after delete: status = %d, want 200", rec.Code)
+			}
+			if rec := get("If-None-Match", tag); rec.Code != http.StatusOK {
+				t.Errorf("old ETag after delete: status = %d, want 200", rec.Code)
+			}
+		})
+	}
+}
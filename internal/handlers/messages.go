@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"BookCollect/internal/mail"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/* ========= ЛОКАЛИЗАЦИЯ СООБЩЕНИЙ ОБ ОШИБКАХ ========= */
+
+// Сообщения пишутся в коде по-русски (язык по умолчанию, mail.Languages[0])
+// и служат ключом перевода. Нет перевода — отдаётся русский текст.
+// Языки — те же, что у писем.
+
+var messages = map[string]map[string]string{
+	"en": {
+		// общие
+		"Некорректный ID":         "Invalid ID",
+		"Неверный JSON":           "Invalid JSON",
+		"Ошибка парсинга формы":   "Could not parse the form",
+		"Метод не разрешён":       "Method not allowed",
+		"Слишком большой запрос":  "Request too large",
+		"Не найдено":              "Not found",
+		"Недействительная ссылка": "Invalid link",
+		"Срок действия ссылки истёк — откройте назначение заново": "The link has expired — open the assignment again",
+
+		// проверка тела по схеме (openapi.json)
+		"Обязательное поле":                    "This field is required",
+		"Неизвестное поле":                     "Unknown field",
+		"Неверный тип значения (ожидается %s)": "Wrong value type (expected %s)",
+		"Недопустимое значение":                "Value not allowed",
+		"Значение должно быть не меньше %v":    "The value must be at least %v",
+		"Значение должно быть не больше %v":    "The value must be at most %v",
+		"Поле не может быть пустым":            "This field cannot be empty",
+		"Не короче %d символов":                "At least %d characters",
+		"Не длиннее %d символов":               "At most %d characters",
+		"Неверный формат":                      "Invalid format",
+
+		// внутренние
+		"Ошибка сервера":                                       "Internal server error",
+		"Ошибка шаблона":                                       "Template error",
+		"Ошибка БД":                                            "Database error",
+		"Ошибка чтения БД":                                     "Database read error",
+		"Ошибка запроса":                                       "Database query error",
+		"Ошибка чтения строк":                                  "Database read error",
+		"БД не инициализирована":                               "Database is not initialised",
+		"Ошибка БД при сохранении заявки":                      "Database error while saving the submission",
+		"Ошибка БД при сохранении авторов":                     "Database error while saving the authors",
+		"Ошибка БД при сохранении версии":                      "Database error while saving the version",
+		"Ошибка БД при смене статуса":                          "Database error while changing the status",
+		"Ошибка БД при объединении":                            "Database error while merging",
+		"Ошибка вставки":                                       "Could not create the record",
+		"Ошибка обновления":                                    "Could not update the record",
+		"Ошибка удаления":                                      "Could not delete the record",
+		"Ошибка при удалении":                                  "Could not delete the record",
+		"Ошибка выхода":                                        "Could not log out",
+		"Файл не удалён":                                       "The file was not deleted",
+		"Файл недоступен":                                      "The file is unavailable",
+		"Не удалось сохранить файл":                            "Could not save the file",
+		"Не удалось сохранить PDF":                             "Could not save the PDF",
+		"Не удалось сохранить пароль":                          "Could not save the password",
+		"Не удалось создать ссылку для отслеживания":           "Could not create the tracking link",
+		"Не удалось подготовить обезличенную рукопись":         "Could not prepare the anonymised manuscript",
+		"Проверка файла временно недоступна, попробуйте позже": "File scanning is temporarily unavailable, please try again later",
+
+		// заявка и авторы
+		"Укажите название":                                          "Enter the title",
+		"Укажите email":                                             "Enter an email",
+		"Некорректный email":                                        "Invalid email",
+		"Некорректный список авторов":                               "Invalid list of authors",
+		"Укажите хотя бы одного автора":                             "Add at least one author",
+		"Не больше %d авторов":                                      "No more than %d authors",
+		"Автор %d: укажите фамилию":                                 "Author %d: enter the surname",
+		"Автор %d: некорректный ORCID (формат 0000-0000-0000-000X)": "Author %d: invalid ORCID (format 0000-0000-0000-000X)",
+		"Контактным может быть только один автор":                   "Only one author can be the corresponding author",
+		"Заявка не найдена":                                         "Submission not found",
+		"Статья не найдена":                                         "Submission not found",
+		"Неизвестный статус":                                        "Unknown status",
+		"Некорректная версия":                                       "Invalid version",
+		"Версия не найдена":                                         "Version not found",
+		"Заявку в этом статусе отозвать нельзя":                     "A submission in this status cannot be withdrawn",
+		"По заявке уже принято решение — новая версия не принимается": "A decision has been made on this submission — new versions are not accepted",
+		"Неизвестный формат: gost, ris, bibtex или csl":               "Unknown format: gost, ris, bibtex or csl",
+
+		// файлы
+		"Приложите файл рукописи":                                        "Attach the manuscript file",
+		"Выберите файл":                                                  "Choose a file",
+		"Недопустимый тип файла. Разрешено: %s":                          "File type not allowed. Allowed: %s",
+		"Слишком большой файл (лимит %d МБ)":                             "File too large (limit %d MB)",
+		"Не удалось проверить файл":                                      "Could not check the file",
+		"Неизвестный формат файла":                                       "Unknown file format",
+		"Содержимое файла не соответствует его расширению":               "The file content does not match its extension",
+		"PDF защищён паролем или зашифрован — загрузите файл без защиты": "The PDF is password-protected or encrypted — upload an unprotected file",
+		"PDF повреждён или сохранён не полностью":                        "The PDF is damaged or incomplete",
+		"Документ повреждён: не удалось прочитать содержимое DOCX/ODT":   "The document is damaged: could not read the DOCX/ODT content",
+		"Файл отклонён: обнаружено вредоносное содержимое":               "File rejected: malicious content detected",
+
+		// сборники
+		"Сборник не найден":             "Collection not found",
+		"Сборник с ID %d не найден":     "Collection with ID %d not found",
+		"Не удалось обработать обложку": "Could not process the cover",
+
+		// рецензенты
+		"Укажите имя":   "Enter the name",
+		"Укажите логин": "Enter the login",
+		"Пароль должен быть не короче 8 символов":        "The password must be at least 8 characters long",
+		"Логин уже занят":                                "This login is already taken",
+		"Срок — в формате ГГГГ-ММ-ДД":                    "Due date must be in YYYY-MM-DD format",
+		"Рецензент уже назначен, не найден или отключён": "The reviewer is already assigned, not found or disabled",
+		"Назначение не найдено":                          "Assignment not found",
+		"Рецензент не найден":                            "Reviewer not found",
+		"Нечего изменять":                                "Nothing to update",
+
+		// реестр авторов
+		"Выберите основную запись и хотя бы один дубликат": "Choose the main record and at least one duplicate",
+		"Основная запись не найдена":                       "Main record not found",
+		"У записей разные ORCID — это разные авторы":       "The records have different ORCIDs — these are different authors",
+
+		// API
+		"Нужен API-ключ: заголовок Authorization: Bearer <ключ>": "An API key is required: Authorization: Bearer <key> header",
+		"Недействительный API-ключ":                              "Invalid API key",
+		"Превышен лимит: %d запросов в минуту":                   "Rate limit exceeded: %d requests per minute",
+		"%s — целое число от %d до %d":                           "%s must be an integer from %d to %d",
+		"Лимит — от 1 до 100000 запросов в минуту":               "The limit must be from 1 to 100000 requests per minute",
+		"Ключ не найден или уже отозван":                         "The key was not found or has already been revoked",
+
+		// статистика
+		"Период: from и to в формате ГГГГ-ММ-ДД, from <= to, не больше 366 дней": "Period: from and to in YYYY-MM-DD format, from <= to, 366 days at most",
+		"begin — в формате ГГГГ-ММ":                                              "begin must be in YYYY-MM format",
+		"end — в формате ГГГГ-ММ":                                                "end must be in YYYY-MM format",
+		"Некорректный период (не больше 5 лет)":                                  "Invalid period (5 years at most)",
+	},
+}
+
+// statusTitles — title в problem+json; английский — http.StatusText
+var statusTitles = map[string]map[int]string{
+	"ru": {
+		http.StatusBadRequest:            "Некорректный запрос",
+		http.StatusUnauthorized:          "Требуется авторизация",
+		http.StatusForbidden:             "Доступ запрещён",
+		http.StatusNotFound:              "Не найдено",
+		http.StatusMethodNotAllowed:      "Метод не разрешён",
+		http.StatusConflict:              "Конфликт",
+		http.StatusGone:                  "Больше недоступно",
+		http.StatusRequestEntityTooLarge: "Слишком большой запрос",
+		http.StatusUnsupportedMediaType:  "Неподдерживаемый тип данных",
+		http.StatusUnprocessableEntity:   "Невозможно обработать",
+		http.StatusTooManyRequests:       "Слишком много запросов",
+		http.StatusInternalServerError:   "Внутренняя ошибка сервера",
+		http.StatusServiceUnavailable:    "Сервис недоступен",
+	},
+}
+
+// localize — сообщение msg на языке lang, с подстановкой args
+func localize(lang, msg string, args ...any) string {
+	if t, ok := messages[lang][msg]; ok {
+		msg = t
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+func statusTitle(lang string, status int) string {
+	if t, ok := statusTitles[lang][status]; ok {
+		return t
+	}
+	return http.StatusText(status)
+}
+
+// acceptLang — первый поддерживаемый язык из Accept-Language (порядок важнее q:
+// браузеры перечисляют языки по убыванию), иначе язык по умолчанию
+func acceptLang(r *http.Request) string {
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag, _, _ = strings.Cut(tag, "-")
+		for _, l := range mail.Languages {
+			if strings.EqualFold(tag, l) {
+				return l
+			}
+		}
+	}
+	return mail.Languages[0]
+}
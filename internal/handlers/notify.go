@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"BookCollect/internal/logging"
+	"BookCollect/internal/mail"
+	"BookCollect/internal/models"
+	"context"
+	"net/http"
+)
+
+/* ========= ПИСЬМА АВТОРАМ ========= */
+
+// requestLang — язык автора: явное поле формы lang, иначе из Accept-Language
+func requestLang(r *http.Request) string {
+	if l := r.FormValue("lang"); l != "" {
+		return mail.NormalizeLang(l)
+	}
+	return acceptLang(r)
+}
+
+// contactNameSQL — к кому обращаться в письме: контактный автор из article_authors,
+// для старых заявок — articles.author
+const contactNameSQL = `COALESCE((SELECT TRIM(aa.given_names || ' ' || aa.surname) FROM article_authors aa
+		WHERE aa.article_id = articles.id AND aa.corresponding LIMIT 1), articles.author)`
+
+// Данные для шаблонов писем
+type articleMail struct {
+	ID          int
+	Author      string
+	Title       string
+	Status      string
+	StatusLabel string
+	Message     string
+	TrackingURL string
+}
+
+// notifySubmission ставит в очередь квитанцию о приёме заявки.
+// Ошибка не возвращается: заявка уже сохранена, письмо — вторично.
+func notifySubmission(ctx context.Context, id int, author, title, email, lang, trackingURL string) {
+	data := articleMail{ID: id, Author: author, Title: title, TrackingURL: trackingURL}
+	if err := mail.Enqueue(ctx, email, lang, "submission_received", data); err != nil {
+		logging.FromContext(ctx).Error("mail: enqueue receipt", "article_id", id, "err", err)
+	}
+}
+
+// notifyStatus сообщает автору о смене статуса заявки
+func notifyStatus(ctx context.Context, id int, author, title, email, lang, status, message string) {
+	data := articleMail{
+		ID:          id,
+		Author:      author,
+		Title:       title,
+		Status:      status,
+		StatusLabel: models.ArticleStatusLabel(status, lang),
+		Message:     message,
+	}
+	if err := mail.Enqueue(ctx, email, lang, "status_changed", data); err != nil {
+		logging.FromContext(ctx).Error("mail: enqueue status", "article_id", id, "err", err)
+	}
+}
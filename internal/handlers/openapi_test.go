@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/db/dbtest"
+	"BookCollect/internal/openapi"
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Ответы хендлеров сверяются со схемами openapi.json (openapi.Validate) —
+// документ и код не должны расходиться.
+
+var collectionColumns = []string{
+	"id", "release_number", "release_year", "title", "description", "cover_image",
+	"publication_link", "pdf_path", "cover_variants", "updated_at",
+}
+
+func collectionRow(id int64, title string) []driver.Value {
+	return []driver.Value{
+		id, int64(3), int64(2024), title, "Описание", "/uploads/covers/c.jpg",
+		"https://example.org/c", "uploads/pdfs/c.pdf",
+		[]byte(`[{"width":320,"format":"webp","path":"/uploads/covers/c-320.webp"}]`),
+		time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// withFakeDB подменяет db.DB на dbtest.Fake на время теста
+func withFakeDB(t *testing.T) *dbtest.Fake {
+	t.Helper()
+	fake := dbtest.New()
+	old := db.DB
+	db.DB = fake.Open()
+	t.Cleanup(func() { db.DB.Close(); db.DB = old })
+	return fake
+}
+
+// serve — запрос через роутер chi (для {id} в пути)
+func serve(method, pattern, target, body string, h http.HandlerFunc) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.MethodFunc(method, pattern, h)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// assertSchema — тело ответа соответствует схеме из openapi.json
+func assertSchema(t *testing.T, rec *httptest.ResponseRecorder, schema string) {
+	t.Helper()
+	if issues := openapi.Validate(schema, rec.Body.Bytes()); len(issues) > 0 {
+		t.Errorf("response does not match %s: %+v\nbody: %s", schema, issues, rec.Body)
+	}
+}
+
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	fake := withFakeDB(t)
+	fake.On("FROM collections", dbtest.Result{Columns: collectionColumns, Rows: [][]driver.Value{
+		collectionRow(2, "Второй"), collectionRow(1, "Первый"),
+	}})
+	// страница /api/v1 — без updated_at
+	fake.On("LIMIT $1 OFFSET $2", dbtest.Result{Columns: collectionColumns[:9], Rows: [][]driver.Value{
+		collectionRow(2, "Второй")[:9], collectionRow(1, "Первый")[:9],
+	}})
+	fake.On("COUNT(*)", dbtest.Result{
+		Columns: []string{"count", "max"},
+		Rows:    [][]driver.Value{{int64(2), time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)}},
+	})
+
+	for _, tc := range []struct {
+		name, pattern, target string
+		h                     http.HandlerFunc
+		status                int
+		schema                string
+	}{
+		{"list", "/api/collections", "/api/collections", GetCollections, 200, "CollectionList"},
+		{"item", "/api/collections/{id}", "/api/collections/2", GetCollectionByID, 200, "Collection"},
+		{"item bad id", "/api/collections/{id}", "/api/collections/x", GetCollectionByID, 400, "Problem"},
+		{"v1 list", "/api/v1/collections", "/api/v1/collections?limit=10", V1Collections, 200, "V1CollectionList"},
+		{"v1 list bad limit", "/api/v1/collections", "/api/v1/collections?limit=0", V1Collections, 400, "Problem"},
+		{"v1 item", "/api/v1/collections/{id}", "/api/v1/collections/2", V1Collection, 200, "V1Collection"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(http.MethodGet, tc.pattern, tc.target, "", tc.h)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tc.status, rec.Body)
+			}
+			assertSchema(t, rec, tc.schema)
+		})
+	}
+}
+
+func TestNotFoundMatchesOpenAPI(t *testing.T) {
+	fake := withFakeDB(t)
+	fake.On("FROM collections", dbtest.Result{Columns: collectionColumns})
+
+	rec := serve(http.MethodGet, "/api/collections/{id}", "/api/collections/9", "", GetCollectionByID)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+	assertSchema(t, rec, "Problem")
+}
+
+func TestUpdateCollectionRejectsInvalidBody(t *testing.T) {
+	// до БД такие запросы доходить не должны
+	fake := withFakeDB(t)
+
+	long := strings.Repeat("я", 501)
+	for _, tc := range []struct {
+		name, body string
+		status     int
+		fields     []string // поля в errors[]
+	}{
+		{"not json", `{"title":`, 400, nil},
+		{"array", `[]`, 400, []string{""}},
+		{"no title", `{"release_year": 2024}`, 400, []string{"title"}},
+		{"empty title", `{"title": ""}`, 400, []string{"title"}},
+		{"long title", `{"title": "` + long + `"}`, 400, []string{"title"}},
+		{"year as string", `{"title": "T", "release_year": "2024"}`, 400, []string{"release_year"}},
+		{"year fraction", `{"title": "T", "release_year": 2024.5}`, 400, []string{"release_year"}},
+		{"year range", `{"title": "T", "release_year": 1800}`, 400, []string{"release_year"}},
+		{"number range", `{"title": "T", "release_number": 0}`, 400, []string{"release_number"}},
+		{"bad link", `{"title": "T", "publication_link": "ftp://x"}`, 400, []string{"publication_link"}},
+		{"unknown field", `{"title": "T", "status": "x"}`, 400, []string{"status"}},
+		{"all at once", `{"release_year": "x", "extra": 1}`, 400, []string{"extra", "release_year", "title"}},
+		{"too large", `{"title": "` + strings.Repeat("x", maxJSONBody) + `"}`, 413, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(http.MethodPut, "/admin/collection/{id}", "/admin/collection/1", tc.body, UpdateCollection)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tc.status, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			assertSchema(t, rec, "Problem")
+
+			var p struct {
+				Errors []struct{ Field string } `json:"errors"`
+			}
+			_ = json.Unmarshal(rec.Body.Bytes(), &p)
+			var fields []string
+			for _, e := range p.Errors {
+				fields = append(fields, e.Field)
+			}
+			slices.Sort(fields)
+			if !slices.Equal(fields, tc.fields) {
+				t.Errorf("errors[].field = %q, want %q", fields, tc.fields)
+			}
+		})
+	}
+	if q := fake.Queries(); len(q) > 0 {
+		t.Errorf("invalid bodies reached the database: %q", q)
+	}
+}
+
+func TestUpdateCollectionValidBody(t *testing.T) {
+	fake := withFakeDB(t)
+	fake.On("UPDATE collections", dbtest.Result{Affected: 1})
+
+	body := `{"title": "Сборник", "release_year": 2024, "release_number": null, "publication_link": ""}`
+	rec := serve(http.MethodPut, "/admin/collection/{id}", "/admin/collection/1", body, UpdateCollection)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	assertSchema(t, rec, "Message")
+
+	fake.On("UPDATE collections", dbtest.Result{Affected: 0})
+	rec = serve(http.MethodPut, "/admin/collection/{id}", "/admin/collection/1", body, UpdateCollection)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing collection: status = %d, want 404", rec.Code)
+	}
+	assertSchema(t, rec, "Problem")
+}
+
+func TestReplaceCollectionFileRejects(t *testing.T) {
+	fake := withFakeDB(t)
+
+	for _, tc := range []struct {
+		name, pattern, target, field, content string
+		h                                     http.HandlerFunc
+		status                                int
+	}{
+		{"cover bad id", "/admin/collection/{id}/cover", "/admin/collection/x/cover", "cover", "", ReplaceCollectionCover, 400},
+		{"cover missing", "/admin/collection/{id}/cover", "/admin/collection/1/cover", "", "", ReplaceCollectionCover, 400},
+		{"cover not image", "/admin/collection/{id}/cover", "/admin/collection/1/cover", "cover", "%PDF-1.4\n", ReplaceCollectionCover, 415},
+		{"pdf missing", "/admin/collection/{id}/pdf", "/admin/collection/1/pdf", "", "", ReplaceCollectionPDF, 400},
+		{"pdf not pdf", "/admin/collection/{id}/pdf", "/admin/collection/1/pdf", "pdf", "\x89PNG\r\n\x1a\n", ReplaceCollectionPDF, 415},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			mw := multipart.NewWriter(&buf)
+			if tc.field != "" {
+				fw, _ := mw.CreateFormFile(tc.field, "file.bin")
+				_, _ = fw.Write([]byte(tc.content))
+			}
+			mw.Close()
+
+			r := chi.NewRouter()
+			r.Put(tc.pattern, tc.h)
+			req := httptest.NewRequest(http.MethodPut, tc.target, &buf)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tc.status, rec.Body)
+			}
+			assertSchema(t, rec, "Problem")
+		})
+	}
+	if q := fake.Queries(); len(q) > 0 {
+		t.Errorf("rejected uploads reached the database: %q", q)
+	}
+}
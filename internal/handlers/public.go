@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"BookCollect/internal/db"
+	"BookCollect/internal/models"
 	"BookCollect/internal/sessions"
+	"BookCollect/internal/stats"
+	"BookCollect/internal/tracing"
+	"BookCollect/internal/views"
 	"database/sql"
-	"html/template"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -16,20 +19,25 @@ import (
 
 /* ========= ВСПОМОГАТЕЛЬНОЕ ========= */
 
-// Единый рендер: сам прокидывает .IsAdmin во все шаблоны
-func render(w http.ResponseWriter, r *http.Request, files []string, data map[string]any) {
+// Единый рендер: сам прокидывает .IsAdmin во все шаблоны; page — имя
+// страницы в web/templates ("admin/stats.html"), см. views.Page
+func render(w http.ResponseWriter, r *http.Request, page string, data map[string]any) {
 	if data == nil {
 		data = map[string]any{}
 	}
 	_, isAdmin := sessions.GetAdminID(r)
 	data["IsAdmin"] = isAdmin
+	_, isReviewer := sessions.GetReviewerID(r)
+	data["IsReviewer"] = isReviewer
 
-	tmpl, err := template.ParseFiles(files...)
+	_, span := tracing.Start(r.Context(), "render "+page)
+	tmpl, err := views.Page(page)
 	if err != nil {
-		http.Error(w, "Ошибка шаблона: "+err.Error(), http.StatusInternalServerError)
+		tracing.End(span, err)
+		serverError(w, r, "Ошибка шаблона", err)
 		return
 	}
-	_ = tmpl.ExecuteTemplate(w, "base", data)
+	tracing.End(span, tmpl.ExecuteTemplate(w, "base", data))
 }
 
 func deref(p *string) string {
@@ -49,7 +57,7 @@ func firstN(s string, n int) string {
 
 func ShowIndexPage(w http.ResponseWriter, r *http.Request) {
 	render(w, r,
-		[]string{"web/templates/base.html", "web/templates/index.html"},
+		"index.html",
 		map[string]any{
 			"Title": "Главная",
 			"Year":  time.Now().Year(),
@@ -66,43 +74,55 @@ type Collection struct {
 	CoverImage      *string
 	PublicationLink string
 	PDFPath         *string
+	CoverVariants   models.CoverVariants
 }
 
 func ShowCollectionsPage(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.DB.Query(`
-		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path
-		FROM collections
-		ORDER BY id DESC`)
+	snap, err := listCollections(r.Context())
 	if err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		serverError(w, r, "Ошибка БД", err)
 		return
 	}
-	defer rows.Close()
-
-	var list []Collection
-	for rows.Next() {
-		var c Collection
-		if err := rows.Scan(
-			&c.ID, &c.ReleaseNumber, &c.ReleaseYear, &c.Title, &c.Description,
-			&c.CoverImage, &c.PublicationLink, &c.PDFPath,
-		); err != nil {
-			http.Error(w, "Ошибка чтения БД", http.StatusInternalServerError)
-			return
+	// без Last-Modified — см. GetCollections
+	if notModified(w, r, pageETag(r, "collections", len(snap.List), snap.Modified), time.Time{}) {
+		return
+	}
+
+	list := make([]Collection, 0, len(snap.List))
+	for _, m := range snap.List {
+		c := Collection{
+			ID:              m.ID,
+			Title:           m.Title,
+			Description:     m.Description,
+			PublicationLink: m.PublicationLink,
+			CoverVariants:   m.CoverVariants,
+		}
+		if m.ReleaseNumber.Valid {
+			v := int(m.ReleaseNumber.Int32)
+			c.ReleaseNumber = &v
+		}
+		if m.ReleaseYear.Valid {
+			v := int(m.ReleaseYear.Int32)
+			c.ReleaseYear = &v
 		}
 		// Нормализуем относительные пути
-		if p := deref(c.PDFPath); p != "" && !strings.HasPrefix(p, "/") {
-			pp := filepath.ToSlash("/" + p)
-			c.PDFPath = &pp
+		if p := m.PDFPath.String; p != "" {
+			if !strings.HasPrefix(p, "/") {
+				p = filepath.ToSlash("/" + p)
+			}
+			c.PDFPath = &p
 		}
-		if img := deref(c.CoverImage); img != "" && !strings.HasPrefix(img, "/") && !strings.HasPrefix(img, "http") {
-			ii := filepath.ToSlash("/" + img)
-			c.CoverImage = &ii
+		if img := m.CoverImage.String; img != "" {
+			if !strings.HasPrefix(img, "/") && !strings.HasPrefix(img, "http") {
+				img = filepath.ToSlash("/" + img)
+			}
+			c.CoverImage = &img
 		}
 		list = append(list, c)
 	}
 
 	render(w, r,
-		[]string{"web/templates/base.html", "web/templates/collections.html"},
+		"collections.html",
 		map[string]any{
 			"Title":       "Все сборники",
 			"Year":        time.Now().Year(),
@@ -119,19 +139,26 @@ func ShowCollectionPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := db.DB.QueryRow(`
-		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path
+	row := db.DB.QueryRowContext(r.Context(), `
+		SELECT id, release_number, release_year, title, description, cover_image, publication_link, pdf_path, cover_variants, updated_at
 		FROM collections WHERE id=$1`, id)
 
 	var c Collection
+	var modified time.Time
 	if err := row.Scan(
 		&c.ID, &c.ReleaseNumber, &c.ReleaseYear, &c.Title, &c.Description,
-		&c.CoverImage, &c.PublicationLink, &c.PDFPath,
+		&c.CoverImage, &c.PublicationLink, &c.PDFPath, &c.CoverVariants, &modified,
 	); err == sql.ErrNoRows {
 		http.NotFound(w, r)
 		return
 	} else if err != nil {
-		http.Error(w, "Ошибка БД", http.StatusInternalServerError)
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	// просмотр засчитывается и при 304: страницу всё равно открыли
+	stats.Default.Record(stats.View, c.ID, clientIP(r), r.UserAgent())
+	if notModified(w, r, pageETag(r, "collection", c.ID, modified), modified) {
 		return
 	}
 
@@ -155,23 +182,31 @@ func ShowCollectionPage(w http.ResponseWriter, r *http.Request) {
 		meta["twitter:card"] = "summary_large_image"
 	}
 
+	papers, err := loadCollectionPapers(r.Context(), c.ID)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
 	render(w, r,
-		[]string{"web/templates/base.html", "web/templates/collection.html"},
+		"collection.html",
 		map[string]any{
 			"Title":      c.Title,
 			"Year":       time.Now().Year(),
 			"Meta":       meta,
 			"Collection": c,
+			"Papers":     papers,
 		},
 	)
 }
 
 func ShowArticleForm(w http.ResponseWriter, r *http.Request) {
 	render(w, r,
-		[]string{"web/templates/base.html", "web/templates/article_form.html"},
+		"article_form.html",
 		map[string]any{
-			"Title": "Подать статью",
-			"Year":  time.Now().Year(),
+			"Title":  "Подать статью",
+			"Year":   time.Now().Year(),
+			"Upload": uploadHints(),
 		},
 	)
 }
@@ -180,7 +215,7 @@ func ShowArticleForm(w http.ResponseWriter, r *http.Request) {
 
 func AdminCollectionsPage(w http.ResponseWriter, r *http.Request) {
 	render(w, r,
-		[]string{"web/templates/base.html", "web/templates/admin/collections.html"},
+		"admin/collections.html",
 		map[string]any{
 			"Title": "Админ · Сборники",
 			"Year":  time.Now().Year(),
@@ -190,7 +225,7 @@ func AdminCollectionsPage(w http.ResponseWriter, r *http.Request) {
 
 func AdminArticlesPage(w http.ResponseWriter, r *http.Request) {
 	render(w, r,
-		[]string{"web/templates/base.html", "web/templates/admin/articles.html"},
+		"admin/articles.html",
 		map[string]any{
 			"Title": "Админ · Заявки",
 			"Year":  time.Now().Year(),
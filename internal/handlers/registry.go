@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+/* ========= РЕЕСТР АВТОРОВ ========= */
+
+// Один человек подаёт статьи под разными написаниями: «Иванов И. П.», «Ivanov I.P.»,
+// «Иванов Иван Петрович». Каждая строка article_authors ссылается на запись
+// authors; совпадение ищется по ORCID, иначе по ключу имени (authorKey).
+// Что не склеилось автоматически, редактор объединяет вручную (MergeAuthors).
+
+// spellingFold сводит распространённые варианты латинской транслитерации
+// к написанию, которое даёт translitMap (х -> h, ц -> c, кс -> ks, ю -> yu, я -> ya)
+var spellingFold = strings.NewReplacer("kh", "h", "ts", "c", "tc", "c", "x", "ks", "iu", "yu", "ia", "ya", "j", "y")
+
+// translitName — слово в нижнем регистре латиницей, без знаков
+func translitName(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if v, ok := translitMap[r]; ok {
+			b.WriteString(v)
+		} else if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return spellingFold.Replace(b.String())
+}
+
+func splitName(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '.' || r == ',' })
+}
+
+// authorKey — "ivanov i p": фамилия и инициалы латиницей. Если имя не заполнено
+// (старые заявки с одним полем «Автор»), фамилией считается первое слово.
+func authorKey(surname, givenNames string) string {
+	if strings.TrimSpace(givenNames) == "" {
+		if parts := splitName(surname); len(parts) > 1 {
+			surname, givenNames = parts[0], strings.Join(parts[1:], " ")
+		}
+	}
+	key := translitName(surname)
+	for _, name := range splitName(givenNames) {
+		for _, part := range strings.Split(name, "-") {
+			if t := translitName(part); t != "" {
+				key += " " + t[:1]
+			}
+		}
+	}
+	return key
+}
+
+// searchKey — строка поиска в виде ключа реестра: "Иванов И" -> "ivanov i"
+func searchKey(q string) string {
+	var parts []string
+	for _, w := range splitName(q) {
+		if t := translitName(w); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// queryer — *sql.DB или *sql.Tx
+type queryer interface {
+	execer
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// resolveAuthor находит запись реестра для автора заявки или заводит новую
+func resolveAuthor(ctx context.Context, q queryer, a models.ArticleAuthor) (int, error) {
+	var id int
+	if a.ORCID != "" {
+		err := q.QueryRowContext(ctx, `
+			SELECT id FROM authors WHERE orcid = $1 AND merged_into IS NULL ORDER BY id LIMIT 1`, a.ORCID).Scan(&id)
+		if err == nil {
+			return id, nil
+		} else if err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+
+	key := authorKey(a.Surname, a.GivenNames)
+	var orcid string
+	err := q.QueryRowContext(ctx, `
+		SELECT id, orcid FROM authors
+		WHERE name_key = $1 AND merged_into IS NULL AND (orcid = '' OR $2 = '')
+		ORDER BY id LIMIT 1`, key, a.ORCID).Scan(&id, &orcid)
+	switch {
+	case err == nil:
+		if orcid == "" && a.ORCID != "" {
+			_, err = q.ExecContext(ctx, `UPDATE authors SET orcid = $2 WHERE id = $1`, id, a.ORCID)
+		}
+		return id, err
+	case err != sql.ErrNoRows:
+		return 0, err
+	}
+
+	err = q.QueryRowContext(ctx, `
+		INSERT INTO authors (surname, given_names, orcid, name_key)
+		VALUES ($1, $2, $3, $4) RETURNING id`,
+		a.Surname, a.GivenNames, a.ORCID, key).Scan(&id)
+	return id, err
+}
+
+// ADMIN: реестр авторов (JSON) — записи без объединённых, с числом статей
+func GetAuthors(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT au.id, au.surname, au.given_names, au.orcid, au.name_key, COUNT(aa.id)
+		FROM authors au
+		LEFT JOIN article_authors aa ON aa.author_id = au.id
+		WHERE au.merged_into IS NULL
+		GROUP BY au.id
+		ORDER BY au.name_key, au.id`)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	type item struct {
+		ID         int    `json:"id"`
+		Surname    string `json:"surname"`
+		GivenNames string `json:"given_names"`
+		ORCID      string `json:"orcid,omitempty"`
+		NameKey    string `json:"name_key"`
+		Articles   int    `json:"articles"`
+	}
+	list := make([]item, 0, 64)
+	for rows.Next() {
+		var it item
+		if err := rows.Scan(&it.ID, &it.Surname, &it.GivenNames, &it.ORCID, &it.NameKey, &it.Articles); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		list = append(list, it)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// ADMIN: объединить дубликаты (JSON: target_id, source_ids). Статьи источников
+// переходят к target, сами источники остаются с merged_into — старые ссылки
+// /authors/{id} перенаправляются на объединённую запись.
+func MergeAuthors(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		TargetID  int   `json:"target_id"`
+		SourceIDs []int `json:"source_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+	sources := make([]int64, 0, len(in.SourceIDs))
+	for _, id := range in.SourceIDs {
+		if id != in.TargetID {
+			sources = append(sources, int64(id))
+		}
+	}
+	if in.TargetID == 0 || len(sources) == 0 {
+		writeError(w, r, errValidation("source_ids", FieldRequired, "Выберите основную запись и хотя бы один дубликат"))
+		return
+	}
+
+	tx, err := db.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var found int
+	if err := tx.QueryRowContext(r.Context(), `
+		SELECT 1 FROM authors WHERE id = $1 AND merged_into IS NULL FOR UPDATE`, in.TargetID).
+		Scan(&found); err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Основная запись не найдена"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	// Два разных ORCID — это два разных человека
+	var conflicts int
+	if err := tx.QueryRowContext(r.Context(), `
+		SELECT COUNT(DISTINCT orcid) FROM authors
+		WHERE (id = ANY($1) OR id = $2) AND orcid <> ''`, pq.Array(sources), in.TargetID).
+		Scan(&conflicts); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if conflicts > 1 {
+		writeError(w, r, errConflict("У записей разные ORCID — это разные авторы"))
+		return
+	}
+
+	steps := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE article_authors SET author_id = $2 WHERE author_id = ANY($1)`, []any{pq.Array(sources), in.TargetID}},
+		{`UPDATE authors t SET orcid = s.orcid FROM authors s
+			WHERE t.id = $2 AND t.orcid = '' AND s.id = ANY($1) AND s.orcid <> ''`, []any{pq.Array(sources), in.TargetID}},
+		// ранее объединённые в источники — теперь сразу в target
+		{`UPDATE authors SET merged_into = $2 WHERE merged_into = ANY($1)`, []any{pq.Array(sources), in.TargetID}},
+	}
+	for _, s := range steps {
+		if _, err := tx.ExecContext(r.Context(), s.query, s.args...); err != nil {
+			serverError(w, r, "Ошибка БД при объединении", err)
+			return
+		}
+	}
+	// в ответе — сколько записей объединено на самом деле: уже объединённые
+	// и несуществующие ID не считаются
+	res, err := tx.ExecContext(r.Context(), `
+		UPDATE authors SET merged_into = $2 WHERE id = ANY($1) AND merged_into IS NULL`,
+		pq.Array(sources), in.TargetID)
+	if err != nil {
+		serverError(w, r, "Ошибка БД при объединении", err)
+		return
+	}
+	merged, err := res.RowsAffected()
+	if err != nil {
+		serverError(w, r, "Ошибка БД при объединении", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		serverError(w, r, "Ошибка БД при объединении", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "id": in.TargetID, "merged": merged})
+}
+
+// ADMIN: привязать к реестру авторов, у которых ещё нет записи (заявки до реестра).
+// Одной транзакцией: при ошибке не остаётся ни полупривязанных строк, ни
+// заведённых впустую записей реестра.
+func RelinkAuthors(w http.ResponseWriter, r *http.Request) {
+	tx, err := db.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(r.Context(), `
+		SELECT id, surname, given_names, orcid FROM article_authors WHERE author_id IS NULL ORDER BY id FOR UPDATE`)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	type pending struct {
+		id int
+		a  models.ArticleAuthor
+	}
+	var list []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.a.Surname, &p.a.GivenNames, &p.a.ORCID); err != nil {
+			rows.Close()
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		list = append(list, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	linked := 0
+	for _, p := range list {
+		authorID, err := resolveAuthor(r.Context(), tx, p.a)
+		if err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		if _, err := tx.ExecContext(r.Context(), `
+			UPDATE article_authors SET author_id = $2 WHERE id = $1`, p.id, authorID); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		linked++
+	}
+	if err := tx.Commit(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "linked": linked})
+}
+
+// ADMIN UI: реестр авторов и объединение дубликатов
+func AdminAuthorsPage(w http.ResponseWriter, r *http.Request) {
+	render(w, r,
+		"admin/authors.html",
+		map[string]any{
+			"Title": "Админ · Авторы",
+			"Year":  time.Now().Year(),
+		},
+	)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"BookCollect/internal/db/dbtest"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTranslitName(t *testing.T) {
+	for _, tc := range []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"Харитонов", "Kharitonov", "Haritonov", "KHARITONOV"}, "haritonov"},
+		{[]string{"Цветков", "Tsvetkov", "Tcvetkov", "Cvetkov"}, "cvetkov"},
+		{[]string{"Максимов", "Maximov", "Maksimov"}, "maksimov"},
+		{[]string{"Юрьев", "Iurev", "Yurev", "Jurev"}, "yurev"},
+		{[]string{"Ё-ж", "e zh"}, "ezh"},
+		{[]string{"", "123", "—"}, ""},
+	} {
+		for _, in := range tc.in {
+			if got := translitName(in); got != tc.want {
+				t.Errorf("translitName(%q) = %q, want %q", in, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestAuthorKey(t *testing.T) {
+	for _, tc := range []struct {
+		want  string
+		names [][2]string // фамилия, имя
+	}{
+		{"haritonova y a", [][2]string{
+			{"Харитонова", "Юлия Андреевна"},
+			{"Kharitonova", "Iuliia Andreevna"},
+			{"Haritonova", "Yulia Andreevna"},
+			{"Харитонова", "Ю. А."},
+			{"Kharitonova", "Yu.A."},
+		}},
+		{"ivanov i p", [][2]string{
+			{"Иванов", "Иван Петрович"},
+			{"Ivanov", "I.P."},
+			// старые заявки: всё в одном поле
+			{"Иванов И. П.", ""},
+			{"Ivanov I.P.", ""},
+			{"Иванов Иван Петрович", "  "},
+			{"Ivanov, I. P.", ""},
+		}},
+		{"petrova a m", [][2]string{
+			{"Петрова", "Анна-Мария"},
+			{"Petrova", "A.-M."},
+		}},
+		{"sidorov", [][2]string{
+			{"Сидоров", ""},
+			{"Sidorov", "—"},
+		}},
+	} {
+		for _, n := range tc.names {
+			if got := authorKey(n[0], n[1]); got != tc.want {
+				t.Errorf("authorKey(%q, %q) = %q, want %q", n[0], n[1], got, tc.want)
+			}
+		}
+	}
+}
+
+func TestSearchKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"Иванов И":       "ivanov i",
+		"ivanov, i.":     "ivanov i",
+		"Kharitonov":     "haritonov",
+		"Харитонов Юлия": "haritonov yuliya",
+		"  Цветков  ":    "cvetkov",
+		"":               "",
+		"... , ":         "",
+	} {
+		if got := searchKey(in); got != want {
+			t.Errorf("searchKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMergeAuthorsCountsUpdatedRows(t *testing.T) {
+	fake := withFakeDB(t)
+	fake.On("UPDATE", dbtest.Result{Affected: 3})
+	fake.On("FOR UPDATE", dbtest.Result{Columns: []string{"?column?"}, Rows: [][]driver.Value{{int64(1)}}})
+	fake.On("COUNT(DISTINCT orcid)", dbtest.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(0)}}})
+	// из трёх источников один уже был объединён раньше, другого нет
+	fake.On("SET merged_into = $2 WHERE id = ANY($1)", dbtest.Result{Affected: 1})
+
+	rec := serve(http.MethodPost, "/admin/authors/merge", "/admin/authors/merge",
+		`{"target_id": 1, "source_ids": [1, 2, 3, 4]}`, MergeAuthors)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	var out struct{ Merged int }
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil || out.Merged != 1 {
+		t.Errorf("merged = %d (%v), want 1", out.Merged, err)
+	}
+
+	rec = serve(http.MethodPost, "/admin/authors/merge", "/admin/authors/merge",
+		`{"target_id": 1, "source_ids": [1]}`, MergeAuthors)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("only target: status = %d, want 400", rec.Code)
+	}
+}
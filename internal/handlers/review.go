@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/mail"
+	"BookCollect/internal/models"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+/* ========= РЕЦЕНЗИРОВАНИЕ: АДМИН API ========= */
+
+// ADMIN: список рецензентов (JSON)
+func GetReviewers(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT id, name, email, login, active, created_at
+		FROM reviewers
+		ORDER BY name`)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	list := make([]models.Reviewer, 0, 16)
+	for rows.Next() {
+		var rv models.Reviewer
+		if err := rows.Scan(&rv.ID, &rv.Name, &rv.Email, &rv.Login, &rv.Active, &rv.CreatedAt); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		list = append(list, rv)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// ADMIN: создать рецензента (JSON: name, email, login, password)
+func CreateReviewer(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+	in.Name, in.Email, in.Login = strings.TrimSpace(in.Name), strings.TrimSpace(in.Email), strings.TrimSpace(in.Login)
+	var v validation
+	if in.Name == "" {
+		v.add("name", FieldRequired, "Укажите имя")
+	}
+	switch {
+	case in.Email == "":
+		v.add("email", FieldRequired, "Укажите email")
+	case !emailRe.MatchString(in.Email):
+		v.add("email", FieldInvalid, "Некорректный email")
+	}
+	if in.Login == "" {
+		v.add("login", FieldRequired, "Укажите логин")
+	}
+	if len(in.Password) < 8 {
+		v.add("password", FieldInvalid, "Пароль должен быть не короче 8 символов")
+	}
+	if err := v.result(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		serverError(w, r, "Не удалось сохранить пароль", err)
+		return
+	}
+
+	var id int
+	err = db.DB.QueryRowContext(r.Context(), `
+		INSERT INTO reviewers (name, email, login, password_hash)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (login) DO NOTHING
+		RETURNING id`,
+		in.Name, in.Email, in.Login, string(hash)).Scan(&id)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errConflict("Логин уже занят"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "id": id})
+}
+
+// ADMIN: изменить рецензента (JSON, все поля необязательны: name, email, active).
+// Отключение действует сразу: ReviewerOnlyMW проверяет active на каждый запрос.
+func UpdateReviewer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+	var in struct {
+		Name   *string `json:"name"`
+		Email  *string `json:"email"`
+		Active *bool   `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+	var v validation
+	if in.Name != nil {
+		if *in.Name = strings.TrimSpace(*in.Name); *in.Name == "" {
+			v.add("name", FieldRequired, "Укажите имя")
+		}
+	}
+	if in.Email != nil {
+		*in.Email = strings.TrimSpace(*in.Email)
+		switch {
+		case *in.Email == "":
+			v.add("email", FieldRequired, "Укажите email")
+		case !emailRe.MatchString(*in.Email):
+			v.add("email", FieldInvalid, "Некорректный email")
+		}
+	}
+	if in.Name == nil && in.Email == nil && in.Active == nil {
+		writeError(w, r, newError(http.StatusBadRequest, CodeBadRequest, "Нечего изменять"))
+		return
+	}
+	if err := v.result(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var rv models.Reviewer
+	err = db.DB.QueryRowContext(r.Context(), `
+		UPDATE reviewers SET
+			name   = COALESCE($2, name),
+			email  = COALESCE($3, email),
+			active = COALESCE($4, active)
+		WHERE id = $1
+		RETURNING id, name, email, login, active, created_at`,
+		id, in.Name, in.Email, in.Active).
+		Scan(&rv.ID, &rv.Name, &rv.Email, &rv.Login, &rv.Active, &rv.CreatedAt)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Рецензент не найден"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(rv)
+}
+
+// ADMIN: назначить рецензента на статью (JSON: reviewer_id, due_date "YYYY-MM-DD")
+func AssignReviewer(w http.ResponseWriter, r *http.Request) {
+	articleID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	var in struct {
+		ReviewerID int    `json:"reviewer_id"`
+		DueDate    string `json:"due_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, errInvalidJSON)
+		return
+	}
+	var due *time.Time
+	if in.DueDate != "" {
+		d, err := time.Parse("2006-01-02", in.DueDate)
+		if err != nil {
+			writeError(w, r, errValidation("due_date", FieldInvalid, "Срок — в формате ГГГГ-ММ-ДД"))
+			return
+		}
+		due = &d
+	}
+
+	var assignmentID int
+	var reviewerName, reviewerEmail, title string
+	err = db.DB.QueryRowContext(r.Context(), `
+		WITH ins AS (
+			INSERT INTO review_assignments (article_id, reviewer_id, due_date)
+			SELECT a.id, rv.id, $3
+			FROM articles a, reviewers rv
+			WHERE a.id = $1 AND rv.id = $2 AND rv.active
+			ON CONFLICT (article_id, reviewer_id) DO NOTHING
+			RETURNING id, article_id, reviewer_id
+		)
+		SELECT ins.id, rv.name, rv.email, a.title
+		FROM ins
+		JOIN reviewers rv ON rv.id = ins.reviewer_id
+		JOIN articles a ON a.id = ins.article_id`,
+		articleID, in.ReviewerID, due).Scan(&assignmentID, &reviewerName, &reviewerEmail, &title)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errConflict("Рецензент уже назначен, не найден или отключён"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	data := map[string]any{
+		"ReviewerName": reviewerName,
+		"Title":        title,
+		"DueDate":      in.DueDate,
+		"URL":          baseURL(r) + "/reviewer/assignments/" + strconv.Itoa(assignmentID),
+	}
+	if err := mail.Enqueue(r.Context(), reviewerEmail, "ru", "review_assigned", data); err != nil {
+		logging.From(r).Error("mail: enqueue review assignment", "assignment_id", assignmentID, "err", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "id": assignmentID})
+}
+
+// ADMIN: снять назначение (вместе с заключением, если оно было)
+func UnassignReviewer(w http.ResponseWriter, r *http.Request) {
+	articleID, err1 := strconv.Atoi(chi.URLParam(r, "id"))
+	assignmentID, err2 := strconv.Atoi(chi.URLParam(r, "assignmentID"))
+	if err1 != nil || err2 != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	res, err := db.DB.ExecContext(r.Context(), `
+		DELETE FROM review_assignments WHERE id = $1 AND article_id = $2`, assignmentID, articleID)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		writeError(w, r, errNotFound("Назначение не найдено"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+}
+
+// ADMIN: назначения и заключения по статье + сводка рекомендаций
+func GetArticleReviews(w http.ResponseWriter, r *http.Request) {
+	articleID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT ra.id, ra.article_id, ra.reviewer_id, rv.name, ra.due_date, ra.status, ra.created_at,
+		       rr.recommendation, rr.comments_for_author, rr.confidential_comments, rr.submitted_at
+		FROM review_assignments ra
+		JOIN reviewers rv ON rv.id = ra.reviewer_id
+		LEFT JOIN review_reports rr ON rr.assignment_id = ra.id
+		WHERE ra.article_id = $1
+		ORDER BY ra.created_at, ra.id`, articleID)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	sum := models.ReviewSummary{
+		Recommendations: map[string]int{},
+		Assignments:     make([]models.ReviewAssignment, 0, 4),
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	for rows.Next() {
+		var a models.ReviewAssignment
+		var rec, forAuthor, confidential sql.NullString
+		var submittedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.ArticleID, &a.ReviewerID, &a.ReviewerName, &a.DueDate, &a.Status, &a.CreatedAt,
+			&rec, &forAuthor, &confidential, &submittedAt); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		if rec.Valid {
+			a.Report = &models.ReviewReport{
+				Recommendation:       rec.String,
+				CommentsForAuthor:    forAuthor.String,
+				ConfidentialComments: confidential.String,
+				SubmittedAt:          submittedAt.Time,
+			}
+			sum.Submitted++
+			sum.Recommendations[rec.String]++
+		}
+		if a.Status != models.AssignmentDeclined {
+			sum.Assigned++
+			if a.Status == models.AssignmentAssigned && a.DueDate != nil && a.DueDate.Before(today) {
+				sum.Overdue++
+			}
+		}
+		sum.Assignments = append(sum.Assignments, a)
+	}
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(sum)
+}
+
+// ADMIN UI: управление рецензентами
+func AdminReviewersPage(w http.ResponseWriter, r *http.Request) {
+	render(w, r,
+		"admin/reviewers.html",
+		map[string]any{
+			"Title": "Админ · Рецензенты",
+			"Year":  time.Now().Year(),
+		},
+	)
+}
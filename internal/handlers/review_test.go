@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/internal/db/dbtest"
+	mw "BookCollect/internal/middleware"
+	"BookCollect/internal/models"
+	"BookCollect/internal/sessions"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var reviewerColumns = []string{"id", "name", "email", "login", "active", "created_at"}
+
+func TestUpdateReviewerRejects(t *testing.T) {
+	fake := withFakeDB(t)
+
+	for _, tc := range []struct {
+		name, target, body string
+		status             int
+	}{
+		{"bad id", "/admin/reviewers/x", `{"active": false}`, 400},
+		{"not json", "/admin/reviewers/1", `{"active":`, 400},
+		{"nothing to update", "/admin/reviewers/1", `{}`, 400},
+		{"empty name", "/admin/reviewers/1", `{"name": "  "}`, 400},
+		{"bad email", "/admin/reviewers/1", `{"email": "nope"}`, 400},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(http.MethodPatch, "/admin/reviewers/{id}", tc.target, tc.body, UpdateReviewer)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tc.status, rec.Body)
+			}
+			assertSchema(t, rec, "Problem")
+		})
+	}
+	if q := fake.Queries(); len(q) > 0 {
+		t.Errorf("invalid bodies reached the database: %q", q)
+	}
+
+	fake.On("UPDATE reviewers", dbtest.Result{Columns: reviewerColumns})
+	rec := serve(http.MethodPatch, "/admin/reviewers/{id}", "/admin/reviewers/9", `{"active": false}`, UpdateReviewer)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing reviewer: status = %d, want 404", rec.Code)
+	}
+}
+
+// Отключённый через PATCH рецензент теряет доступ с уже выданной сессией
+func TestDeactivatedReviewerRefused(t *testing.T) {
+	sessions.Init(*config.Default())
+	fake := withFakeDB(t)
+
+	fake.On("UPDATE reviewers", dbtest.Result{Columns: reviewerColumns, Rows: [][]driver.Value{
+		{int64(7), "Иванов И. И.", "iv@example.org", "ivanov", false, time.Now()},
+	}})
+	rec := serve(http.MethodPatch, "/admin/reviewers/{id}", "/admin/reviewers/7", `{"active": false}`, UpdateReviewer)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH: status = %d; body: %s", rec.Code, rec.Body)
+	}
+	var rv models.Reviewer
+	if err := json.Unmarshal(rec.Body.Bytes(), &rv); err != nil || rv.ID != 7 || rv.Active {
+		t.Fatalf("PATCH response = %+v, %v", rv, err)
+	}
+
+	login := httptest.NewRecorder()
+	if err := sessions.SetReviewerID(login, httptest.NewRequest(http.MethodGet, "/", nil), 7); err != nil {
+		t.Fatal(err)
+	}
+	fake.On("SELECT active FROM reviewers", dbtest.Result{Columns: []string{"active"}, Rows: [][]driver.Value{{false}}})
+
+	reached := false
+	h := mw.ReviewerOnlyMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true }))
+	req := httptest.NewRequest(http.MethodGet, "/reviewer", nil)
+	req.AddCookie(login.Result().Cookies()[0])
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if reached || rec.Code != http.StatusFound || rec.Header().Get("Location") != "/reviewer/login" {
+		t.Errorf("deactivated reviewer: reached = %v, status = %d, Location = %q",
+			reached, rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestReviewResponsesMatchOpenAPI(t *testing.T) {
+	fake := withFakeDB(t)
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	fake.On("FROM review_assignments ra", dbtest.Result{
+		Columns: []string{"id", "article_id", "reviewer_id", "name", "due_date", "status", "created_at",
+			"recommendation", "comments_for_author", "confidential_comments", "submitted_at"},
+		Rows: [][]driver.Value{
+			{int64(1), int64(5), int64(7), "Иванов И. И.", due, "submitted", created, "minor_revision", "Уточнить выводы", "", created},
+			{int64(2), int64(5), int64(8), "Петров П. П.", nil, "assigned", created, nil, nil, nil, nil},
+		},
+	})
+	fake.On("INSERT INTO review_assignments", dbtest.Result{
+		Columns: []string{"id", "name", "email", "title"},
+		Rows:    [][]driver.Value{{int64(3), "Иванов И. И.", "iv@example.org", "Статья"}},
+	})
+	fake.On("INSERT INTO mail_outbox", dbtest.Result{Affected: 1})
+	fake.On("DELETE FROM review_assignments", dbtest.Result{Affected: 1})
+
+	const list, item = "/admin/articles/{id}/reviews", "/admin/articles/{id}/reviews/{assignmentID}"
+	for _, tc := range []struct {
+		name, method, pattern, target, body string
+		h                                   http.HandlerFunc
+		status                              int
+		schema                              string
+	}{
+		{"list", http.MethodGet, list, "/admin/articles/5/reviews", "", GetArticleReviews, 200, "ReviewSummary"},
+		{"list bad id", http.MethodGet, list, "/admin/articles/x/reviews", "", GetArticleReviews, 400, "Problem"},
+		{"assign", http.MethodPost, list, "/admin/articles/5/reviews", `{"reviewer_id": 7, "due_date": "2025-03-15"}`, AssignReviewer, 201, "ReviewAssigned"},
+		{"assign bad date", http.MethodPost, list, "/admin/articles/5/reviews", `{"reviewer_id": 7, "due_date": "15.03.2025"}`, AssignReviewer, 400, "Problem"},
+		{"assign bad json", http.MethodPost, list, "/admin/articles/5/reviews", `{"reviewer_id":`, AssignReviewer, 400, "Problem"},
+		{"unassign", http.MethodDelete, item, "/admin/articles/5/reviews/3", "", UnassignReviewer, 200, "Ok"},
+		{"unassign bad id", http.MethodDelete, item, "/admin/articles/5/reviews/x", "", UnassignReviewer, 400, "Problem"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(tc.method, tc.pattern, tc.target, tc.body, tc.h)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tc.status, rec.Body)
+			}
+			assertSchema(t, rec, tc.schema)
+		})
+	}
+
+	// уже назначен или рецензент отключён — 409; назначения нет — 404
+	fake.On("INSERT INTO review_assignments", dbtest.Result{Columns: []string{"id", "name", "email", "title"}})
+	rec := serve(http.MethodPost, list, "/admin/articles/5/reviews", `{"reviewer_id": 7}`, AssignReviewer)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("assign twice: status = %d, want 409", rec.Code)
+	}
+	assertSchema(t, rec, "Problem")
+	fake.On("DELETE FROM review_assignments", dbtest.Result{Affected: 0})
+	rec = serve(http.MethodDelete, item, "/admin/articles/5/reviews/3", "", UnassignReviewer)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unassign missing: status = %d, want 404", rec.Code)
+	}
+	assertSchema(t, rec, "Problem")
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"BookCollect/internal/blind"
+	"BookCollect/internal/db"
+	"BookCollect/internal/filetype"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/metrics"
+	"BookCollect/internal/models"
+	"BookCollect/internal/sessions"
+	"BookCollect/internal/tracing"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"golang.org/x/crypto/bcrypt"
+)
+
+/* ========= КАБИНЕТ РЕЦЕНЗЕНТА ========= */
+
+// Ссылка на рукопись подписана и живёт сутки: её можно открыть в новой вкладке
+// или менеджере загрузок, но она привязана к одному назначению.
+const reviewLinkTTL = 24 * time.Hour
+
+func reviewDownloadURL(assignmentID int) string {
+	exp := strconv.FormatInt(time.Now().Add(reviewLinkTTL).Unix(), 10)
+	sig := sessions.Sign(reviewLinkValue(assignmentID, exp))
+	return fmt.Sprintf("/reviewer/assignments/%d/download?exp=%s&sig=%s", assignmentID, exp, sig)
+}
+
+func reviewLinkValue(assignmentID int, exp string) string {
+	return "review-download:" + strconv.Itoa(assignmentID) + ":" + exp
+}
+
+// ShowReviewerLoginPage — страница входа рецензента
+func ShowReviewerLoginPage(w http.ResponseWriter, r *http.Request) {
+	render(w, r,
+		"reviewer/login.html",
+		map[string]any{
+			"Title": "Вход рецензента",
+			"Year":  time.Now().Year(),
+			"Error": r.URL.Query().Get("error"),
+		},
+	)
+}
+
+// HandleReviewerLogin — POST входа рецензента (как HandleLogin, но по таблице reviewers)
+func HandleReviewerLogin(w http.ResponseWriter, r *http.Request) {
+	fail := func(msg string) {
+		http.Redirect(w, r, "/reviewer/login?error="+url.QueryEscape(msg), http.StatusFound)
+	}
+	if err := r.ParseForm(); err != nil {
+		fail("Ошибка формы")
+		return
+	}
+
+	login := strings.TrimSpace(r.FormValue("login"))
+	password := r.FormValue("password")
+	if login == "" || password == "" {
+		fail("Заполните все поля")
+		return
+	}
+
+	var id int
+	var passwordHash string
+	err := db.DB.QueryRowContext(r.Context(), `
+		SELECT id, password_hash FROM reviewers WHERE login = $1 AND active`, login).
+		Scan(&id, &passwordHash)
+	if err == sql.ErrNoRows {
+		metrics.LoginFailures.WithLabelValues("reviewer").Inc()
+		fail("Неверный логин или пароль")
+		return
+	} else if err != nil {
+		fail("Ошибка БД")
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) != nil {
+		metrics.LoginFailures.WithLabelValues("reviewer").Inc()
+		fail("Неверный логин или пароль")
+		return
+	}
+
+	if err := sessions.SetReviewerID(w, r, id); err != nil {
+		logging.From(r).Error("session save", "err", err)
+		fail("Ошибка сессии")
+		return
+	}
+	http.Redirect(w, r, "/reviewer", http.StatusFound)
+}
+
+// HandleReviewerLogout — выход рецензента
+func HandleReviewerLogout(w http.ResponseWriter, r *http.Request) {
+	if err := sessions.ClearReviewerID(w, r); err != nil {
+		serverError(w, r, "Ошибка выхода", err)
+		return
+	}
+	http.Redirect(w, r, "/reviewer/login", http.StatusFound)
+}
+
+// ReviewerAssignment — строка назначения для страниц рецензента.
+// В режиме двойного слепого рецензирования Author пуст.
+type ReviewerAssignment struct {
+	ID                  int
+	ArticleID           int
+	Title               string
+	Author              string
+	DueDate             *time.Time
+	Status              string
+	Overdue             bool
+	CreatedAt           time.Time
+	DownloadURL         string
+	Report              *models.ReviewReport
+	RecommendationLabel string
+}
+
+// ReviewerDashboard — список назначений рецензента
+func ReviewerDashboard(w http.ResponseWriter, r *http.Request) {
+	reviewerID, _ := sessions.GetReviewerID(r)
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT ra.id, a.id, a.title, a.author, ra.due_date, ra.status, ra.created_at
+		FROM review_assignments ra
+		JOIN articles a ON a.id = ra.article_id
+		WHERE ra.reviewer_id = $1
+		ORDER BY (ra.status = 'assigned') DESC, ra.due_date NULLS LAST, ra.id DESC`, reviewerID)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	today := time.Now().Truncate(24 * time.Hour)
+	var list []ReviewerAssignment
+	for rows.Next() {
+		var a ReviewerAssignment
+		if err := rows.Scan(&a.ID, &a.ArticleID, &a.Title, &a.Author, &a.DueDate, &a.Status, &a.CreatedAt); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		a.Overdue = a.Status == models.AssignmentAssigned && a.DueDate != nil && a.DueDate.Before(today)
+		if blind.Enabled {
+			a.Author = ""
+		}
+		list = append(list, a)
+	}
+
+	render(w, r,
+		"reviewer/assignments.html",
+		map[string]any{
+			"Title":       "Кабинет рецензента",
+			"Year":        time.Now().Year(),
+			"Assignments": list,
+		},
+	)
+}
+
+// loadReviewerAssignment — назначение текущего рецензента (чужие не видны)
+func loadReviewerAssignment(r *http.Request) (*ReviewerAssignment, error) {
+	reviewerID, _ := sessions.GetReviewerID(r)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	var a ReviewerAssignment
+	var rec, forAuthor, confidential sql.NullString
+	var submittedAt sql.NullTime
+	err = db.DB.QueryRowContext(r.Context(), `
+		SELECT ra.id, a.id, a.title, a.author, ra.due_date, ra.status, ra.created_at,
+		       rr.recommendation, rr.comments_for_author, rr.confidential_comments, rr.submitted_at
+		FROM review_assignments ra
+		JOIN articles a ON a.id = ra.article_id
+		LEFT JOIN review_reports rr ON rr.assignment_id = ra.id
+		WHERE ra.id = $1 AND ra.reviewer_id = $2`, id, reviewerID).
+		Scan(&a.ID, &a.ArticleID, &a.Title, &a.Author, &a.DueDate, &a.Status, &a.CreatedAt,
+			&rec, &forAuthor, &confidential, &submittedAt)
+	if err != nil {
+		return nil, err
+	}
+	if blind.Enabled {
+		a.Author = ""
+	}
+	if rec.Valid {
+		a.Report = &models.ReviewReport{
+			Recommendation:       rec.String,
+			CommentsForAuthor:    forAuthor.String,
+			ConfidentialComments: confidential.String,
+			SubmittedAt:          submittedAt.Time,
+		}
+		a.RecommendationLabel = models.RecommendationLabel(rec.String)
+	}
+	if a.Status == models.AssignmentAssigned {
+		a.DownloadURL = reviewDownloadURL(a.ID)
+	}
+	return &a, nil
+}
+
+// recommendationOption — пункт выпадающего списка рекомендаций
+type recommendationOption struct {
+	Value, Label string
+}
+
+func recommendationOptions() []recommendationOption {
+	opts := make([]recommendationOption, 0, len(models.Recommendations))
+	for _, v := range models.Recommendations {
+		opts = append(opts, recommendationOption{v, models.RecommendationLabel(v)})
+	}
+	return opts
+}
+
+// ShowReviewAssignment — рукопись и форма заключения
+func ShowReviewAssignment(w http.ResponseWriter, r *http.Request) {
+	a, err := loadReviewerAssignment(r)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	render(w, r,
+		"reviewer/assignment.html",
+		map[string]any{
+			"Title":           "Рецензия: " + a.Title,
+			"Year":            time.Now().Year(),
+			"Assignment":      a,
+			"Recommendations": recommendationOptions(),
+			"Error":           r.URL.Query().Get("error"),
+			"Saved":           r.URL.Query().Get("saved") == "1",
+		},
+	)
+}
+
+// SubmitReviewReport — POST формы заключения (recommendation, comments_for_author, confidential_comments)
+func SubmitReviewReport(w http.ResponseWriter, r *http.Request) {
+	a, err := loadReviewerAssignment(r)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	back := "/reviewer/assignments/" + strconv.Itoa(a.ID)
+	fail := func(msg string) {
+		http.Redirect(w, r, back+"?error="+url.QueryEscape(msg), http.StatusSeeOther)
+	}
+
+	if a.Status != models.AssignmentAssigned {
+		fail("Заключение уже отправлено или назначение отклонено")
+		return
+	}
+	rec := r.FormValue("recommendation")
+	if !models.ValidRecommendation(rec) {
+		fail("Выберите рекомендацию")
+		return
+	}
+	forAuthor := strings.TrimSpace(r.FormValue("comments_for_author"))
+	if forAuthor == "" {
+		fail("Заполните замечания для автора")
+		return
+	}
+	confidential := strings.TrimSpace(r.FormValue("confidential_comments"))
+
+	tx, err := db.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Статус меняется только из assigned — повторная отправка формы не создаст второе заключение
+	res, err := tx.ExecContext(r.Context(), `
+		UPDATE review_assignments SET status = $2 WHERE id = $1 AND status = $3`,
+		a.ID, models.AssignmentSubmitted, models.AssignmentAssigned)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		fail("Заключение уже отправлено")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `
+		INSERT INTO review_reports (assignment_id, recommendation, comments_for_author, confidential_comments)
+		VALUES ($1, $2, $3, $4)`, a.ID, rec, forAuthor, confidential); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	http.Redirect(w, r, back+"?saved=1", http.StatusSeeOther)
+}
+
+// DeclineReviewAssignment — рецензент отказывается от назначения
+func DeclineReviewAssignment(w http.ResponseWriter, r *http.Request) {
+	reviewerID, _ := sessions.GetReviewerID(r)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if _, err := db.DB.ExecContext(r.Context(), `
+		UPDATE review_assignments SET status = $3
+		WHERE id = $1 AND reviewer_id = $2 AND status = $4`,
+		id, reviewerID, models.AssignmentDeclined, models.AssignmentAssigned); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	http.Redirect(w, r, "/reviewer", http.StatusSeeOther)
+}
+
+// DownloadReviewManuscript — текущая версия рукописи по подписанной ссылке
+func DownloadReviewManuscript(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+	exp, err := strconv.ParseInt(q.Get("exp"), 10, 64)
+	if err != nil || !sessions.Verify(reviewLinkValue(id, q.Get("exp")), q.Get("sig")) {
+		writeError(w, r, newError(http.StatusForbidden, CodeForbidden, "Недействительная ссылка"))
+		return
+	}
+	if time.Now().Unix() > exp {
+		writeError(w, r, newError(http.StatusGone, CodeGone, "Срок действия ссылки истёк — откройте назначение заново"))
+		return
+	}
+
+	var filePath string
+	err = db.DB.QueryRowContext(r.Context(), `
+		SELECT a.file_path
+		FROM review_assignments ra
+		JOIN articles a ON a.id = ra.article_id
+		JOIN reviewers rv ON rv.id = ra.reviewer_id AND rv.active
+		WHERE ra.id = $1 AND ra.status = $2`, id, models.AssignmentAssigned).Scan(&filePath)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	f, err := os.Open(localPath(filePath))
+	if err != nil {
+		serverError(w, r, "Файл недоступен", err)
+		return
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		serverError(w, r, "Файл недоступен", err)
+		return
+	}
+
+	// Имя файла нейтральное: в сохранённом имени есть транслитерированное название
+	ext := filepath.Ext(filePath)
+	var content io.ReadSeeker = f
+	if blind.Enabled {
+		var buf bytes.Buffer
+		_, span := tracing.Start(r.Context(), "blind.scrub", semconv.FilePath(filePath))
+		err := blind.Scrub(filetype.KindByExt(ext), f, st.Size(), &buf)
+		tracing.End(span, err)
+		if err != nil {
+			// Лучше не отдать файл, чем раскрыть автора
+			serverError(w, r, "Не удалось подготовить обезличенную рукопись", fmt.Errorf("review %d: anonymise %s: %w", id, filePath, err))
+			return
+		}
+		content = bytes.NewReader(buf.Bytes())
+	}
+
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Content-Disposition", contentDisposition("attachment", "manuscript-"+strconv.Itoa(id)+ext))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", time.Time{}, content)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"BookCollect/internal/openapi"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// maxJSONBody — предел тела JSON-запроса (файлы идут multipart, не JSON)
+const maxJSONBody = 1 << 20
+
+// readJSONBody читает тело и проверяет его по схеме schema из openapi.json.
+// Все нарушения — одной ошибкой 400 с errors[] по полям.
+func readJSONBody(w http.ResponseWriter, r *http.Request, schema string) ([]byte, *Error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJSONBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, newError(http.StatusRequestEntityTooLarge, CodeTooLarge, "Слишком большой запрос")
+		}
+		return nil, errInvalidJSON
+	}
+
+	var v validation
+	for _, is := range openapi.Validate(schema, body) {
+		switch is.Keyword {
+		case openapi.KeywordJSONSyntax:
+			return nil, errInvalidJSON
+		case openapi.KeywordRequired:
+			v.add(is.Field, FieldRequired, "Обязательное поле")
+		case openapi.KeywordUnknown:
+			v.add(is.Field, FieldUnknown, "Неизвестное поле")
+		case openapi.KeywordType:
+			v.add(is.Field, FieldInvalid, "Неверный тип значения (ожидается %s)", is.Limit)
+		case openapi.KeywordEnum:
+			v.add(is.Field, FieldInvalid, "Недопустимое значение")
+		case openapi.KeywordMinimum:
+			v.add(is.Field, FieldInvalid, "Значение должно быть не меньше %v", is.Limit)
+		case openapi.KeywordMaximum:
+			v.add(is.Field, FieldInvalid, "Значение должно быть не больше %v", is.Limit)
+		case openapi.KeywordMinLength:
+			if is.Limit == 1 {
+				v.add(is.Field, FieldRequired, "Поле не может быть пустым")
+			} else {
+				v.add(is.Field, FieldInvalid, "Не короче %d символов", is.Limit)
+			}
+		case openapi.KeywordMaxLength:
+			v.add(is.Field, FieldInvalid, "Не длиннее %d символов", is.Limit)
+		default:
+			v.add(is.Field, FieldInvalid, "Неверный формат")
+		}
+	}
+	if e := v.result(); e != nil {
+		return nil, e
+	}
+	return body, nil
+}
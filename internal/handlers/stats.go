@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/models"
+	"BookCollect/internal/stats"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+/* ========= СТАТИСТИКА ПРОСМОТРОВ И СКАЧИВАНИЙ ========= */
+
+// clientIP — адрес клиента без порта (RemoteAddr уже исправлен middleware.RealIP)
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// firstRange — запрос целого файла или его начала. Просмотрщики PDF качают файл
+// кусками (Range), считать нужно один раз — по первому куску.
+func firstRange(r *http.Request) bool {
+	rg := r.Header.Get("Range")
+	return rg == "" || strings.HasPrefix(rg, "bytes=0-")
+}
+
+// PUBLIC: скачать PDF сборника (со счётчиком)
+func DownloadCollectionPDF(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var title string
+	var pdfPath sql.NullString
+	err = db.DB.QueryRowContext(r.Context(), `SELECT title, pdf_path FROM collections WHERE id = $1`, id).
+		Scan(&title, &pdfPath)
+	if err == sql.ErrNoRows || (err == nil && pdfPath.String == "") {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	publicPath := pdfPath.String
+	if !strings.HasPrefix(publicPath, "/") {
+		publicPath = "/" + filepath.ToSlash(publicPath)
+	}
+	f, err := os.Open(localPath(publicPath))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		serverError(w, r, "Файл недоступен", err)
+		return
+	}
+
+	if firstRange(r) {
+		stats.Default.Record(stats.Download, id, clientIP(r), r.UserAgent())
+	}
+
+	name, ok := originalName(r.Context(), publicPath)
+	if !ok {
+		name = title + ".pdf"
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", contentDisposition("attachment", name))
+	http.ServeContent(w, r, "", st.ModTime(), f)
+}
+
+// maxStatsDays — самый длинный период GetStats: ряд по дням строится на весь период
+const maxStatsDays = 366
+
+// statsRange — период из ?from=&to= (YYYY-MM-DD), по умолчанию последние 30 дней
+func statsRange(r *http.Request) (from, to time.Time, ok bool) {
+	to = time.Now()
+	from = to.AddDate(0, 0, -29)
+	var err error
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return from, to, false
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return from, to, false
+		}
+	}
+	return from, to, !to.Before(from) && to.Sub(from) < maxStatsDays*24*time.Hour
+}
+
+// StatsRow — счётчики за день или по сборнику за период
+type StatsRow struct {
+	Day             string `json:"day,omitempty"`
+	CollectionID    int    `json:"collection_id,omitempty"`
+	Title           string `json:"title,omitempty"`
+	Views           int    `json:"views"`
+	UniqueViews     int    `json:"unique_views"`
+	Downloads       int    `json:"downloads"`
+	UniqueDownloads int    `json:"unique_downloads"`
+}
+
+// ADMIN: статистика за период (JSON): ряд по дням и итоги по сборникам
+func GetStats(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := statsRange(r)
+	if !ok {
+		writeError(w, r, newError(http.StatusBadRequest, CodeValidation, "Период: from и to в формате ГГГГ-ММ-ДД, from <= to, не больше 366 дней"))
+		return
+	}
+
+	daily := make([]StatsRow, 0, 31)
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT d::date, COALESCE(SUM(s.views), 0), COALESCE(SUM(s.unique_views), 0),
+		       COALESCE(SUM(s.downloads), 0), COALESCE(SUM(s.unique_downloads), 0)
+		FROM generate_series($1::date, $2::date, interval '1 day') d
+		LEFT JOIN collection_stats_daily s ON s.day = d::date
+		GROUP BY d
+		ORDER BY d`, from, to)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	for rows.Next() {
+		var s StatsRow
+		var d time.Time
+		if err := rows.Scan(&d, &s.Views, &s.UniqueViews, &s.Downloads, &s.UniqueDownloads); err != nil {
+			rows.Close()
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		s.Day = d.Format("2006-01-02")
+		daily = append(daily, s)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	perCollection := make([]StatsRow, 0, 16)
+	rows, err = db.DB.QueryContext(r.Context(), `
+		SELECT c.id, c.title, SUM(s.views), SUM(s.unique_views), SUM(s.downloads), SUM(s.unique_downloads)
+		FROM collection_stats_daily s
+		JOIN collections c ON c.id = s.collection_id
+		WHERE s.day BETWEEN $1::date AND $2::date
+		GROUP BY c.id
+		ORDER BY SUM(s.downloads) DESC, SUM(s.views) DESC`, from, to)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s StatsRow
+		if err := rows.Scan(&s.CollectionID, &s.Title, &s.Views, &s.UniqueViews, &s.Downloads, &s.UniqueDownloads); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		perCollection = append(perCollection, s)
+	}
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"from":        from.Format("2006-01-02"),
+		"to":          to.Format("2006-01-02"),
+		"daily":       daily,
+		"collections": perCollection,
+	})
+}
+
+// counterMetrics — метрики COUNTER 5 в порядке столбцов views, unique_views,
+// downloads, unique_downloads: просмотр страницы сборника — Investigation,
+// скачивание PDF — Request
+var counterMetrics = []string{
+	"Total_Item_Investigations",
+	"Unique_Item_Investigations",
+	"Total_Item_Requests",
+	"Unique_Item_Requests",
+}
+
+// ADMIN: отчёт в духе COUNTER 5 Title Report (CSV) за ?begin=&end= (YYYY-MM)
+func ExportCounterCSV(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	begin := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -11, 0)
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var err error
+	if v := r.URL.Query().Get("begin"); v != "" {
+		if begin, err = time.Parse("2006-01", v); err != nil {
+			writeError(w, r, errValidation("begin", FieldInvalid, "begin — в формате ГГГГ-ММ"))
+			return
+		}
+	}
+	if v := r.URL.Query().Get("end"); v != "" {
+		if end, err = time.Parse("2006-01", v); err != nil {
+			writeError(w, r, errValidation("end", FieldInvalid, "end — в формате ГГГГ-ММ"))
+			return
+		}
+	}
+	if end.Before(begin) || end.Sub(begin) > 5*366*24*time.Hour {
+		writeError(w, r, newError(http.StatusBadRequest, CodeValidation, "Некорректный период (не больше 5 лет)"))
+		return
+	}
+	var months []time.Time
+	for m := begin; !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+	monthIdx := make(map[string]int, len(months))
+	for i, m := range months {
+		monthIdx[m.Format("2006-01")] = i
+	}
+
+	type title struct {
+		name string
+		yop  string
+		vals map[string][]int // метрика -> по месяцам
+	}
+	var titles []*title
+	byID := map[int]*title{}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT c.id, c.title, COALESCE(c.release_year::text, ''), to_char(s.day, 'YYYY-MM'),
+		       SUM(s.views), SUM(s.unique_views), SUM(s.downloads), SUM(s.unique_downloads)
+		FROM collection_stats_daily s
+		JOIN collections c ON c.id = s.collection_id
+		WHERE s.day >= $1::date AND s.day < ($2::date + interval '1 month')
+		GROUP BY c.id, 4
+		ORDER BY c.title, c.id`, begin, end)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name, yop, month string
+		v := make([]int, 4)
+		if err := rows.Scan(&id, &name, &yop, &month, &v[0], &v[1], &v[2], &v[3]); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		t := byID[id]
+		if t == nil {
+			t = &title{name: name, yop: yop, vals: map[string][]int{}}
+			for _, m := range counterMetrics {
+				t.vals[m] = make([]int, len(months))
+			}
+			byID[id] = t
+			titles = append(titles, t)
+		}
+		for i, m := range counterMetrics {
+			t.vals[m][monthIdx[month]] += v[i]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", contentDisposition("attachment",
+		fmt.Sprintf("TR_%s_%s.csv", begin.Format("2006-01"), end.Format("2006-01"))))
+
+	cw := csv.NewWriter(w)
+	period := fmt.Sprintf("Begin_Date=%s; End_Date=%s",
+		begin.Format("2006-01-02"), end.AddDate(0, 1, -1).Format("2006-01-02"))
+	header := [][]string{
+		{"Report_Name", "Title Report"},
+		{"Report_ID", "TR"},
+		{"Release", "5"},
+		{"Institution_Name", ""},
+		{"Institution_ID", ""},
+		{"Metric_Types", strings.Join(counterMetrics, "; ")},
+		{"Report_Filters", "Data_Type=Book"},
+		{"Report_Attributes", ""},
+		{"Exceptions", ""},
+		{"Reporting_Period", period},
+		{"Created", now.UTC().Format(time.RFC3339)},
+		{"Created_By", models.JournalTitle},
+		{},
+	}
+	for _, rec := range header {
+		_ = cw.Write(rec)
+	}
+
+	cols := []string{"Title", "Publisher", "YOP", "Metric_Type", "Reporting_Period_Total"}
+	for _, m := range months {
+		cols = append(cols, m.Format("Jan-2006"))
+	}
+	_ = cw.Write(cols)
+	for _, t := range titles {
+		for _, m := range counterMetrics {
+			vals := t.vals[m]
+			total := 0
+			for _, v := range vals {
+				total += v
+			}
+			rec := []string{t.name, models.JournalTitle, t.yop, m, strconv.Itoa(total)}
+			for _, v := range vals {
+				rec = append(rec, strconv.Itoa(v))
+			}
+			_ = cw.Write(rec)
+		}
+	}
+	cw.Flush()
+}
+
+// ADMIN UI: графики просмотров и скачиваний
+func AdminStatsPage(w http.ResponseWriter, r *http.Request) {
+	render(w, r,
+		"admin/stats.html",
+		map[string]any{
+			"Title": "Админ · Статистика",
+			"Year":  time.Now().Year(),
+		},
+	)
+}
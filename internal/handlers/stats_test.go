@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatsRange(t *testing.T) {
+	for query, ok := range map[string]bool{
+		"":                               true,
+		"?from=2025-01-01&to=2025-01-01": true,
+		"?from=2024-01-01&to=2024-12-31": true, // високосный год — 366 дней
+		"?from=2024-01-01&to=2025-01-01": false,
+		"?from=2000-01-01&to=2025-01-01": false,
+		"?from=2025-02-01&to=2025-01-01": false,
+		"?from=01.01.2025":               false,
+	} {
+		if _, _, got := statsRange(httptest.NewRequest(http.MethodGet, "/admin/stats"+query, nil)); got != ok {
+			t.Errorf("statsRange(%q) ok = %v, want %v", query, got, ok)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"BookCollect/internal/covergen"
+	"BookCollect/internal/db"
+	"BookCollect/internal/filetype"
+	"BookCollect/internal/imaging"
+	"BookCollect/internal/metrics"
+	"BookCollect/internal/models"
+	"BookCollect/internal/tracing"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+/* ========= ХРАНЕНИЕ ЗАГРУЗОК ========= */
+
+// Все файлы сборников проходят через storeUpload: имя на диске строится
+// из транслитерированного оригинального имени + случайного суффикса,
+// а оригинальное имя записывается в uploaded_files для Content-Disposition.
+
+const storeAttempts = 5
+
+// storeUpload сохраняет загруженный файл в uploads/<dir>/ под уникальным
+// безопасным именем и регистрирует его. Возвращает публичный путь вида /uploads/<dir>/<name>.
+func storeUpload(ctx context.Context, u *upload, dir, fallback string) (string, error) {
+	base := sanitizeFileName(strings.TrimSuffix(u.Header.Filename, filepath.Ext(u.Header.Filename)))
+	if base == "" {
+		base = fallback
+	}
+	// Расширение берём из реального типа содержимого, а не из имени
+	return storeFile(ctx, dir, base, u.Kind.Ext(), u.Kind.ContentType(), filepath.Base(u.Header.Filename),
+		io.NewSectionReader(u.File, 0, u.Header.Size))
+}
+
+// storeFile — общая часть: уникальное имя <base>_<random><ext>, запись, регистрация
+func storeFile(ctx context.Context, dir, base, ext, contentType, origName string, r io.Reader) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "storage.store", semconv.FileDirectory(dir))
+	defer func() { tracing.End(span, err) }()
+
+	dirPath := filepath.Join("uploads", dir)
+	if err := os.MkdirAll(dirPath, 0o755); err != nil {
+		return "", err
+	}
+
+	var dst *os.File
+	var dstPath string
+	for i := 0; i < storeAttempts; i++ {
+		dstPath = filepath.Join(dirPath, base+"_"+randomSuffix()+ext)
+		f, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		dst = f
+		break
+	}
+	if dst == nil {
+		return "", fmt.Errorf("storage: no free name for %q", origName)
+	}
+
+	size, err := io.Copy(dst, r)
+	if err != nil {
+		dst.Close()
+		_ = os.Remove(dstPath)
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dstPath)
+		return "", err
+	}
+
+	span.SetAttributes(semconv.FilePath(dstPath), semconv.FileSize(int(size)))
+	publicPath := "/" + filepath.ToSlash(dstPath)
+	if _, err := db.DB.ExecContext(ctx, `
+		INSERT INTO uploaded_files (path, original_name, content_type, size)
+		VALUES ($1, $2, $3, $4)`,
+		publicPath, origName, contentType, size,
+	); err != nil {
+		_ = os.Remove(dstPath)
+		return "", err
+	}
+	metrics.UploadBytes.WithLabelValues(dir).Observe(float64(size))
+	return publicPath, nil
+}
+
+// storeCover прогоняет обложку через imaging: поворот по EXIF, удаление метаданных,
+// набор ширин (+ WebP, если доступен кодер). Исходник с метаданными не сохраняется.
+// Основной путь (для cover_image) — самый широкий JPEG.
+func storeCover(ctx context.Context, u *upload) (string, models.CoverVariants, error) {
+	pctx, span := tracing.Start(ctx, "cover.process")
+	variants, err := imaging.Process(pctx, io.NewSectionReader(u.File, 0, u.Header.Size))
+	tracing.End(span, err)
+	if err != nil {
+		return "", nil, err
+	}
+
+	base := sanitizeFileName(strings.TrimSuffix(u.Header.Filename, filepath.Ext(u.Header.Filename)))
+	if base == "" {
+		base = "cover"
+	}
+	return storeVariants(ctx, variants, base, filepath.Base(u.Header.Filename))
+}
+
+// storeGeneratedCover создаёт обложку для сборника без неё: первая страница PDF
+// или типографская обложка (см. covergen) и те же варианты ширин, что у загруженных.
+func storeGeneratedCover(ctx context.Context, pdfPath string, info covergen.Info) (string, models.CoverVariants, error) {
+	gctx, span := tracing.Start(ctx, "cover.generate")
+	img := covergen.Generate(gctx, localPath(pdfPath), info)
+	variants, err := imaging.Variants(gctx, img)
+	tracing.End(span, err)
+	if err != nil {
+		return "", nil, err
+	}
+
+	base := sanitizeFileName(info.Title)
+	if base == "" {
+		base = "cover"
+	}
+	return storeVariants(ctx, variants, base+"_auto", base+".jpg")
+}
+
+func storeVariants(ctx context.Context, variants []imaging.Variant, base, origName string) (string, models.CoverVariants, error) {
+	var out models.CoverVariants
+	mainPath, mainWidth := "", 0
+	for _, v := range variants {
+		kind := filetype.JPEG
+		if v.Format == "webp" {
+			kind = filetype.WebP
+		}
+		p, err := storeFile(ctx, "covers", fmt.Sprintf("%s_w%d", base, v.Width), kind.Ext(), kind.ContentType(),
+			origName, bytes.NewReader(v.Data))
+		if err != nil {
+			removeCover(ctx, out)
+			return "", nil, err
+		}
+		out = append(out, models.CoverVariant{Width: v.Width, Format: v.Format, Path: p})
+		if kind == filetype.JPEG && v.Width > mainWidth {
+			mainPath, mainWidth = p, v.Width
+		}
+	}
+	return mainPath, out, nil
+}
+
+func removeCover(ctx context.Context, variants models.CoverVariants) {
+	for _, v := range variants {
+		removeUpload(ctx, v.Path)
+	}
+}
+
+// localPath — путь на диске для публичного пути /uploads/... (относительно рабочего каталога)
+func localPath(publicPath string) string {
+	return filepath.FromSlash(strings.TrimPrefix(publicPath, "/"))
+}
+
+// removeUpload удаляет файл и его регистрацию (например, при откате создания сборника)
+func removeUpload(ctx context.Context, publicPath string) {
+	if publicPath == "" {
+		return
+	}
+	ctx, span := tracing.Start(ctx, "storage.remove", semconv.FilePath(localPath(publicPath)))
+	defer span.End()
+	_ = os.Remove(localPath(publicPath))
+	_, _ = db.DB.ExecContext(ctx, `DELETE FROM uploaded_files WHERE path = $1`, publicPath)
+}
+
+// redirectCollectionPDF — PDF сборника по прямому пути отправляется на
+// /collections/{id}/pdf: иначе скачивание мимо счётчика. Прочие PDF
+// (не привязанные к сборнику) не раздаются.
+func redirectCollectionPDF(w http.ResponseWriter, r *http.Request, publicPath string) {
+	var id int
+	// в старых записях путь без ведущего «/»
+	err := db.DB.QueryRowContext(r.Context(),
+		`SELECT id FROM collections WHERE pdf_path IN ($1, $2) ORDER BY id LIMIT 1`,
+		publicPath, strings.TrimPrefix(publicPath, "/"),
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/collections/%d/pdf", id), http.StatusFound)
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// originalName — оригинальное имя загруженного файла по его публичному пути
+func originalName(ctx context.Context, publicPath string) (string, bool) {
+	var name string
+	err := db.DB.QueryRowContext(ctx, `SELECT original_name FROM uploaded_files WHERE path = $1`, publicPath).Scan(&name)
+	if err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// contentDisposition формирует заголовок с оригинальным именем (кириллица — через filename*)
+func contentDisposition(disposition, name string) string {
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": name}); v != "" {
+		return v
+	}
+	return disposition
+}
+
+// privateUploads — каталоги uploads/, которые не раздаются: рукописи отдаются
+// только редакции и рецензентам (с обезличиванием) через свои хендлеры
+var privateUploads = []string{"/uploads/articles"}
+
+// ServeUploads раздаёт uploads/ и подставляет оригинальное имя файла
+// в Content-Disposition, если файл зарегистрирован в uploaded_files.
+// PDF сборников по прямой ссылке не отдаются — см. redirectCollectionPDF.
+func ServeUploads() http.Handler {
+	fs := http.StripPrefix("/uploads/", http.FileServer(http.Dir("uploads")))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clean := path.Clean(r.URL.Path)
+		for _, dir := range privateUploads {
+			if clean == dir || strings.HasPrefix(clean, dir+"/") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		if strings.HasPrefix(clean, "/uploads/pdfs/") || clean == "/uploads/pdfs" ||
+			strings.EqualFold(path.Ext(clean), ".pdf") {
+			redirectCollectionPDF(w, r, clean)
+			return
+		}
+		if name, ok := originalName(r.Context(), clean); ok {
+			w.Header().Set("Content-Disposition", contentDisposition("inline", name))
+		}
+		fs.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"BookCollect/internal/db/dbtest"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// withUploads — рабочий каталог теста с uploads/<path> из files
+func withUploads(t *testing.T, files ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(dir, "uploads", filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestServeUploadsHidesManuscripts(t *testing.T) {
+	withUploads(t, "articles/paper.docx", "covers/c.jpg")
+	fake := withFakeDB(t)
+	fake.On("FROM uploaded_files", dbtest.Result{Columns: []string{"original_name"}})
+
+	h := ServeUploads()
+	for _, tc := range []struct {
+		target string
+		status int
+	}{
+		{"/uploads/covers/c.jpg", 200},
+		{"/uploads/articles/paper.docx", 404},
+		{"/uploads/articles/", 404},
+		{"/uploads/articles", 404},
+		{"/uploads/covers/../articles/paper.docx", 404},
+		{"/uploads//articles/paper.docx", 404},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d", tc.target, rec.Code, tc.status)
+		}
+	}
+}
+
+func TestServeUploadsRedirectsCollectionPDF(t *testing.T) {
+	withUploads(t, "pdfs/c.pdf", "pdfs/orphan.pdf", "legacy.pdf")
+	fake := withFakeDB(t)
+	fake.On("FROM collections", dbtest.Result{Columns: []string{"id"}})
+	fake.On("FROM uploaded_files", dbtest.Result{Columns: []string{"original_name"}})
+
+	h := ServeUploads()
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+	for _, target := range []string{"/uploads/pdfs/orphan.pdf", "/uploads/pdfs/", "/uploads/legacy.pdf"} {
+		if rec := get(target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", target, rec.Code)
+		}
+	}
+
+	fake.On("FROM collections", dbtest.Result{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(7)}}})
+	rec := get("/uploads/pdfs/c.pdf")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/collections/7/pdf" {
+		t.Errorf("status = %d, Location = %q; want 302 to /collections/7/pdf", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestCollectionsExposeTrackedPDFURL(t *testing.T) {
+	fake := withFakeDB(t)
+	fake.On("FROM collections", dbtest.Result{Columns: collectionColumns, Rows: [][]driver.Value{collectionRow(2, "Второй")}})
+
+	rec := serve(http.MethodGet, "/api/collections/{id}", "/api/collections/2", "", GetCollectionByID)
+	if body := rec.Body.String(); !strings.Contains(body, `"pdf_path":"/collections/2/pdf"`) || strings.Contains(body, "uploads/pdfs") {
+		t.Errorf("body = %s; want pdf_path = /collections/2/pdf and no file path", body)
+	}
+}
+
+func TestDeleteCollectionRemovesFiles(t *testing.T) {
+	withUploads(t, "covers/c_320.jpg", "covers/c_320.webp", "covers/c_640.jpg", "pdfs/c.pdf", "covers/other.jpg")
+	fake := withFakeDB(t)
+	fake.On("DELETE FROM collections", dbtest.Result{
+		Columns: []string{"cover_image", "pdf_path", "cover_variants"},
+		Rows: [][]driver.Value{{"/uploads/covers/c_640.jpg", "/uploads/pdfs/c.pdf", []byte(`[` +
+			`{"width":320,"format":"jpeg","path":"/uploads/covers/c_320.jpg"},` +
+			`{"width":320,"format":"webp","path":"/uploads/covers/c_320.webp"},` +
+			`{"width":640,"format":"jpeg","path":"/uploads/covers/c_640.jpg"}]`)}},
+	})
+	fake.On("DELETE FROM uploaded_files", dbtest.Result{Affected: 4})
+
+	rec := serve(http.MethodDelete, "/admin/collection/{id}", "/admin/collection/3", "", DeleteCollection)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body: %s", rec.Code, rec.Body)
+	}
+	for _, f := range []string{"covers/c_320.jpg", "covers/c_320.webp", "covers/c_640.jpg", "pdfs/c.pdf"} {
+		if _, err := os.Stat(filepath.Join("uploads", filepath.FromSlash(f))); !os.IsNotExist(err) {
+			t.Errorf("%s left on disk", f)
+		}
+	}
+	if _, err := os.Stat(filepath.Join("uploads", "covers", "other.jpg")); err != nil {
+		t.Errorf("unrelated file removed: %v", err)
+	}
+	if !slices.ContainsFunc(fake.Queries(), func(q string) bool { return strings.Contains(q, "DELETE FROM uploaded_files") }) {
+		t.Error("uploaded_files rows not deleted")
+	}
+
+	fake.On("DELETE FROM collections", dbtest.Result{Columns: []string{"cover_image", "pdf_path", "cover_variants"}})
+	rec = serve(http.MethodDelete, "/admin/collection/{id}", "/admin/collection/9", "", DeleteCollection)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing collection: status = %d, want 404", rec.Code)
+	}
+}
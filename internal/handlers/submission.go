@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+/* ========= ОТСЛЕЖИВАНИЕ ЗАЯВКИ АВТОРОМ ========= */
+
+// Автор получает ссылку /submission/{token} при подаче. В БД хранится только
+// SHA-256 токена, поэтому утечка базы не раскрывает рабочие ссылки.
+
+// newTrackingToken — 256 бит случайности в base64url
+func newTrackingToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// baseURL — адрес сайта для абсолютных ссылок. Для писем PUBLIC_URL задан
+// всегда (config.Validate): Host запроса подставляет клиент, и ссылка в письме
+// вела бы на его сайт. Без почты (разработка) — из запроса.
+func baseURL(r *http.Request) string {
+	if cfg.Server.PublicURL != "" {
+		return cfg.Server.PublicURL
+	}
+	scheme := "http"
+	if r.TLS != nil || cfg.Server.HTTPS || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func trackingURL(r *http.Request, token string) string {
+	return baseURL(r) + "/submission/" + token
+}
+
+// execer — *sql.DB или *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// addArticleEvent пишет запись в историю заявки (видна автору на странице отслеживания)
+func addArticleEvent(ctx context.Context, q execer, articleID int, status, message string) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO article_events (article_id, status, message) VALUES ($1, $2, $3)`,
+		articleID, status, message)
+	return err
+}
+
+// ArticleEvent — строка истории для шаблона
+type ArticleEvent struct {
+	Status      string
+	StatusLabel string
+	Message     string
+	CreatedAt   time.Time
+}
+
+// Submission — данные страницы отслеживания
+type Submission struct {
+	ID              int
+	Title           string
+	Author          string
+	Status          string
+	StatusLabel     string
+	CreatedAt       time.Time
+	StatusChangedAt time.Time
+	Events          []ArticleEvent
+	CanWithdraw     bool
+}
+
+func loadSubmission(ctx context.Context, token string) (*Submission, error) {
+	var s Submission
+	err := db.DB.QueryRowContext(ctx, `
+		SELECT id, title, author, status, created_at, status_changed_at
+		FROM articles WHERE tracking_token_hash = $1`, hashToken(token)).
+		Scan(&s.ID, &s.Title, &s.Author, &s.Status, &s.CreatedAt, &s.StatusChangedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.StatusLabel = models.ArticleStatusLabel(s.Status, "ru")
+	s.CanWithdraw = models.ArticleWithdrawable(s.Status)
+
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT status, message, created_at FROM article_events
+		WHERE article_id = $1 ORDER BY created_at, id`, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e ArticleEvent
+		if err := rows.Scan(&e.Status, &e.Message, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.StatusLabel = models.ArticleStatusLabel(e.Status, "ru")
+		s.Events = append(s.Events, e)
+	}
+	return &s, rows.Err()
+}
+
+// ShowSubmissionPage — публичная страница статуса заявки по токену
+func ShowSubmissionPage(w http.ResponseWriter, r *http.Request) {
+	s, err := loadSubmission(r.Context(), chi.URLParam(r, "token"))
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	// Ссылка сама по себе — ключ доступа: не отдаём её в Referer и не индексируем
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	render(w, r,
+		"submission.html",
+		map[string]any{
+			"Title":      "Заявка №" + strconv.Itoa(s.ID),
+			"Year":       time.Now().Year(),
+			"Submission": s,
+			"Token":      chi.URLParam(r, "token"),
+			"Withdrawn":  r.URL.Query().Get("withdrawn") == "1",
+			"Upload":     uploadHints(),
+		},
+	)
+}
+
+// WithdrawSubmission — автор отзывает свою заявку (POST с формы страницы отслеживания)
+func WithdrawSubmission(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	tx, err := db.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer tx.Rollback()
+
+	var id int
+	var status, author, title, email, lang string
+	err = tx.QueryRowContext(r.Context(), `
+		SELECT id, status, `+contactNameSQL+`, title, email, lang FROM articles
+		WHERE tracking_token_hash = $1 FOR UPDATE`, hashToken(token)).
+		Scan(&id, &status, &author, &title, &email, &lang)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if !models.ArticleWithdrawable(status) {
+		writeError(w, r, errConflict("Заявку в этом статусе отозвать нельзя"))
+		return
+	}
+
+	if _, err := tx.ExecContext(r.Context(), `
+		UPDATE articles SET status = $2, status_changed_at = NOW() WHERE id = $1`,
+		id, models.StatusWithdrawn); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if err := addArticleEvent(r.Context(), tx, id, models.StatusWithdrawn, "Заявка отозвана автором"); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	notifyStatus(r.Context(), id, author, title, email, lang, models.StatusWithdrawn, "")
+	http.Redirect(w, r, "/submission/"+token+"?withdrawn=1", http.StatusSeeOther)
+}
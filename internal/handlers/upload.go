@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"BookCollect/internal/filetype"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/scanner"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// checkUpload сверяет реальное содержимое загруженного файла с ожидаемым типом.
+// Возвращает определённый тип и nil, если всё в порядке, иначе — ошибку для клиента.
+func checkUpload(file multipart.File, hdr *multipart.FileHeader, want ...filetype.Kind) (filetype.Kind, *Error) {
+	got, err := filetype.Detect(file, hdr.Size)
+	if err != nil {
+		return filetype.Unknown, uploadError(err)
+	}
+	for _, k := range want {
+		if got == k {
+			return got, nil
+		}
+	}
+	return filetype.Unknown, uploadError(filetype.ErrMismatch)
+}
+
+// upload — проверенный файл из multipart-формы
+type upload struct {
+	multipart.File
+	Header *multipart.FileHeader
+	Kind   filetype.Kind
+}
+
+// formUpload достаёт необязательный файл из формы, проверяет его тип и антивирусом.
+// Если поле пустое — возвращает nil без ошибки. Файл закрывает вызывающий.
+// Ошибка привязана к полю field.
+func formUpload(r *http.Request, field string, want ...filetype.Kind) (*upload, *Error) {
+	file, hdr, err := r.FormFile(field)
+	if err != nil {
+		return nil, nil
+	}
+	kind, e := checkUpload(file, hdr, want...)
+	if e == nil {
+		e = scanUpload(r.Context(), file, hdr)
+	}
+	if e != nil {
+		file.Close()
+		return nil, e.withField(field, FieldInvalid, e.Msg, e.Args...)
+	}
+	return &upload{File: file, Header: hdr, Kind: kind}, nil
+}
+
+// uploadError — 415 с причиной, по которой файл не прошёл проверку типа
+func uploadError(err error) *Error {
+	msg := "Не удалось проверить файл"
+	switch {
+	case errors.Is(err, filetype.ErrEncryptedPDF):
+		msg = "PDF защищён паролем или зашифрован — загрузите файл без защиты"
+	case errors.Is(err, filetype.ErrMalformedPDF):
+		msg = "PDF повреждён или сохранён не полностью"
+	case errors.Is(err, filetype.ErrMalformedZip):
+		msg = "Документ повреждён: не удалось прочитать содержимое DOCX/ODT"
+	case errors.Is(err, filetype.ErrMismatch):
+		msg = "Содержимое файла не соответствует его расширению"
+	case errors.Is(err, filetype.ErrUnknown):
+		msg = "Неизвестный формат файла"
+	}
+	return newError(http.StatusUnsupportedMediaType, CodeUnsupportedType, msg)
+}
+
+// scanUpload прогоняет загруженный файл через антивирус. Заражённый файл
+// переносится в карантин. Возвращает ошибку для клиента (nil, если файл чистый).
+func scanUpload(ctx context.Context, file multipart.File, hdr *multipart.FileHeader) *Error {
+	res, err := scanner.Default.Scan(ctx, io.NewSectionReader(file, 0, hdr.Size))
+	if err != nil {
+		// Без проверки файл не принимаем: редакторы открывают рукописи у себя
+		e := newError(http.StatusServiceUnavailable, CodeUnavailable, "Проверка файла временно недоступна, попробуйте позже")
+		e.Err = fmt.Errorf("scanner: %s: %w", hdr.Filename, err)
+		return e
+	}
+	if !res.Infected {
+		return nil
+	}
+
+	path, err := scanner.Quarantine(io.NewSectionReader(file, 0, hdr.Size), hdr.Filename, res)
+	if err != nil {
+		logging.FromContext(ctx).Error("scanner: infected upload not quarantined",
+			"file", hdr.Filename, "signature", res.Signature, "path", path, "err", err)
+	} else {
+		logging.FromContext(ctx).Warn("scanner: infected upload quarantined", "file", hdr.Filename, "signature", res.Signature, "path", path)
+	}
+	return newError(http.StatusUnprocessableEntity, CodeMalware, "Файл отклонён: обнаружено вредоносное содержимое")
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/logging"
+	"BookCollect/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+/* ========= ВЕРСИИ РУКОПИСИ ========= */
+
+// Каждая загрузка рукописи — отдельная строка article_versions (1, 2, 3...).
+// articles.file_path всегда указывает на последнюю версию.
+
+// addArticleVersion регистрирует версию файла рукописи
+func addArticleVersion(ctx context.Context, q execer, articleID, version int, filePath, originalName, uploadedBy, note string) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO article_versions (article_id, version, file_path, original_name, uploaded_by, note)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		articleID, version, filePath, filepath.Base(originalName), uploadedBy, note)
+	return err
+}
+
+// storeArticleVersion сохраняет новую версию из формы (поле file, необязательное note)
+// и делает её текущей. Возвращает номер версии; при ошибке сам пишет JSON-ответ.
+func storeArticleVersion(w http.ResponseWriter, r *http.Request, articleID int, uploadedBy string) (int, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.Uploads.MaxSize())
+	if err := r.ParseMultipartForm(cfg.Uploads.MaxSize()); err != nil {
+		writeError(w, r, errTooLarge())
+		return 0, false
+	}
+
+	var title string
+	if err := db.DB.QueryRowContext(r.Context(), `SELECT title FROM articles WHERE id = $1`, articleID).
+		Scan(&title); err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Заявка не найдена"))
+		return 0, false
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return 0, false
+	}
+
+	file, handler, ok := acceptManuscript(w, r)
+	if !ok {
+		return 0, false
+	}
+	defer file.Close()
+
+	dstPath, err := saveManuscript(r.Context(), file, handler, title)
+	if err != nil {
+		serverError(w, r, "Не удалось сохранить файл", err)
+		return 0, false
+	}
+	fail := func(msg string, err error) (int, bool) {
+		_ = os.Remove(localPath(dstPath))
+		serverError(w, r, msg, err)
+		return 0, false
+	}
+
+	tx, err := db.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		return fail("Ошибка БД", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем заявку, чтобы две одновременные загрузки не получили один номер
+	if _, err := tx.ExecContext(r.Context(), `SELECT id FROM articles WHERE id = $1 FOR UPDATE`, articleID); err != nil {
+		return fail("Ошибка БД", err)
+	}
+	var version int
+	if err := tx.QueryRowContext(r.Context(), `
+		SELECT COALESCE(MAX(version), 0) + 1 FROM article_versions WHERE article_id = $1`, articleID).
+		Scan(&version); err != nil {
+		return fail("Ошибка БД", err)
+	}
+
+	note := strings.TrimSpace(r.FormValue("note"))
+	if err := addArticleVersion(r.Context(), tx, articleID, version, dstPath, handler.Filename, uploadedBy, note); err != nil {
+		return fail("Ошибка БД при сохранении версии", err)
+	}
+	if _, err := tx.ExecContext(r.Context(), `UPDATE articles SET file_path = $2 WHERE id = $1`, articleID, dstPath); err != nil {
+		return fail("Ошибка БД при сохранении версии", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fail("Ошибка БД при сохранении версии", err)
+	}
+	return version, true
+}
+
+// ADMIN: список версий рукописи (JSON)
+func GetArticleVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	rows, err := db.DB.QueryContext(r.Context(), `
+		SELECT version, original_name, uploaded_by, note, created_at
+		FROM article_versions
+		WHERE article_id = $1
+		ORDER BY version DESC`, id)
+	if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	defer rows.Close()
+
+	list := make([]models.ArticleVersion, 0, 4)
+	for rows.Next() {
+		var v models.ArticleVersion
+		if err := rows.Scan(&v.Version, &v.OriginalName, &v.UploadedBy, &v.Note, &v.CreatedAt); err != nil {
+			serverError(w, r, "Ошибка БД", err)
+			return
+		}
+		list = append(list, v)
+	}
+	if err := rows.Err(); err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// ADMIN: загрузить новую версию рукописи (multipart: file, note)
+func UploadArticleVersion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, errInvalidID)
+		return
+	}
+
+	version, ok := storeArticleVersion(w, r, id, "editor")
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "id": id, "version": version})
+}
+
+// AUTHOR: новая версия по ссылке отслеживания (форма на /submission/{token})
+func UploadSubmissionVersion(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	var id int
+	var status string
+	err := db.DB.QueryRowContext(r.Context(), `
+		SELECT id, status FROM articles WHERE tracking_token_hash = $1`, hashToken(token)).
+		Scan(&id, &status)
+	if err == sql.ErrNoRows {
+		writeError(w, r, errNotFound("Заявка не найдена"))
+		return
+	} else if err != nil {
+		serverError(w, r, "Ошибка БД", err)
+		return
+	}
+	// После итогового решения или отзыва автор файл уже не меняет
+	if !models.ArticleWithdrawable(status) {
+		writeError(w, r, errConflict("По заявке уже принято решение — новая версия не принимается"))
+		return
+	}
+
+	version, ok := storeArticleVersion(w, r, id, "author")
+	if !ok {
+		return
+	}
+	if err := addArticleEvent(r.Context(), db.DB, id, status, fmt.Sprintf("Автор загрузил версию %d", version)); err != nil {
+		logging.From(r).Error("article history", "article_id", id, "err", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "version": version})
+}
@@ -0,0 +1,124 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// tiffWithOrientation — TIFF-заголовок с одним IFD из тега Orientation
+func tiffWithOrientation(bo binary.AppendByteOrder, o uint16) []byte {
+	t := []byte("II*\x00")
+	if bo == binary.BigEndian {
+		t = []byte("MM\x00*")
+	}
+	t = bo.AppendUint32(t, 8) // IFD сразу за заголовком
+	t = bo.AppendUint16(t, 1)
+	t = bo.AppendUint16(t, 0x0112)
+	t = bo.AppendUint16(t, 3) // SHORT
+	t = bo.AppendUint32(t, 1)
+	t = bo.AppendUint16(t, o)
+	t = bo.AppendUint16(t, 0)
+	return bo.AppendUint32(t, 0) // следующего IFD нет
+}
+
+// jpegWith — SOI, сегменты (маркер, содержимое), SOS и немного «данных»
+func jpegWith(segs ...[]byte) []byte {
+	out := []byte{0xFF, 0xD8}
+	for _, s := range segs {
+		out = append(out, 0xFF, s[0])
+		out = binary.BigEndian.AppendUint16(out, uint16(len(s)+1))
+		out = append(out, s[1:]...)
+	}
+	return append(out, 0xFF, 0xDA, 0x00, 0x02, 0x12, 0x34, 0xFF, 0xD9)
+}
+
+func app1(payload []byte) []byte {
+	return append([]byte{0xE1}, append([]byte("Exif\x00\x00"), payload...)...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	le := tiffWithOrientation(binary.LittleEndian, 6)
+	be := tiffWithOrientation(binary.BigEndian, 8)
+	app0 := append([]byte{0xE0}, "JFIF\x00\x01\x02"...)
+
+	hugeIFD := slices.Clone(le)
+	binary.LittleEndian.PutUint32(hugeIFD[4:], 0xFFFFFFFF)
+	manyEntries := slices.Clone(le)
+	binary.LittleEndian.PutUint16(manyEntries[8:], 500)
+	binary.LittleEndian.PutUint16(manyEntries[10:], 0x010F) // Make — Orientation не встретится
+	badValue := tiffWithOrientation(binary.BigEndian, 9)
+	badOrder := slices.Clone(le)
+	copy(badOrder, "XX")
+
+	truncated := jpegWith(app1(le))
+	truncated = truncated[:len(truncated)-20] // длина сегмента больше файла
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little-endian", jpegWith(app1(le)), 6},
+		{"big-endian", jpegWith(app1(be)), 8},
+		{"after APP0", jpegWith(app0, app1(be)), 8},
+		{"no EXIF", jpegWith(app0), 1},
+		{"not EXIF APP1", jpegWith(append([]byte{0xE1}, "http://ns.adobe.com/xap/1.0/\x00"...)), 1},
+		{"empty", nil, 1},
+		{"not JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"truncated segment", truncated, 1},
+		{"short TIFF", jpegWith(app1([]byte("II*\x00"))), 1},
+		{"bad byte order", jpegWith(app1(badOrder)), 1},
+		{"IFD offset past end", jpegWith(app1(hugeIFD)), 1},
+		{"entry count past end", jpegWith(app1(manyEntries)), 1},
+		{"value out of range", jpegWith(app1(badValue)), 1},
+		{"garbage after SOI", []byte{0xFF, 0xD8, 0x00, 0x01, 0x02, 0x03, 0x04}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := jpegOrientation(tc.data); got != tc.want {
+				t.Errorf("jpegOrientation = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	// любой обрывок файла — без паники
+	full := jpegWith(app0, app1(le))
+	for n := range full {
+		jpegOrientation(full[:n])
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 3×2, пиксели — буквы:
+	//   a b c
+	//   d e f
+	src := image.NewGray(image.Rect(0, 0, 3, 2))
+	copy(src.Pix, "abcdef")
+
+	for o, want := range map[int][]string{
+		1: {"abc", "def"},
+		2: {"cba", "fed"},
+		3: {"fed", "cba"},
+		4: {"def", "abc"},
+		5: {"ad", "be", "cf"},
+		6: {"da", "eb", "fc"},
+		7: {"fc", "eb", "da"},
+		8: {"cf", "be", "ad"},
+	} {
+		img := applyOrientation(src, o)
+		b := img.Bounds()
+		var got []string
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			row := make([]byte, 0, b.Dx())
+			for x := b.Min.X; x < b.Max.X; x++ {
+				row = append(row, color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			}
+			got = append(got, string(row))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("orientation %d: %q, want %q", o, got, want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"BookCollect/internal/config"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Ширины адаптивных вариантов обложки (px). Больше исходника не растягиваем.
+var Widths = []int{320, 640, 960, 1280}
+
+const (
+	jpegQuality = 82
+	// Защита от «бомб»: картинки больше этого числа пикселей не декодируем
+	maxPixels = 40_000_000
+)
+
+var ErrTooLarge = errors.New("imaging: image is too large")
+
+// Variant — один вариант обложки после обработки
+type Variant struct {
+	Width  int
+	Height int
+	Format string // "jpeg" или "webp"
+	Data   []byte
+}
+
+// WebPEncoder — кодирование в WebP. В стандартной библиотеке и x/image есть
+// только декодер, поэтому кодер подключаемый (по умолчанию — утилита cwebp).
+type WebPEncoder interface {
+	EncodeWebP(ctx context.Context, img image.Image) ([]byte, error)
+}
+
+// WebP — текущий кодер; nil — WebP-варианты не создаются
+var WebP WebPEncoder
+
+// Init ищет cwebp: путь из настроек или в PATH
+func Init(c config.Covers) {
+	path := c.CwebpPath
+	if path == "" {
+		if p, err := exec.LookPath("cwebp"); err == nil {
+			path = p
+		}
+	}
+	if path == "" {
+		slog.Warn("imaging: cwebp not found, WebP covers disabled")
+		return
+	}
+	WebP = &CWebP{Path: path, Quality: 80}
+	slog.Info("imaging: WebP covers", "cwebp", path)
+}
+
+// Process декодирует обложку, применяет EXIF-поворот и нарезает варианты
+// заданных ширин. Перекодирование само по себе выбрасывает все метаданные (EXIF, GPS, ICC).
+func Process(ctx context.Context, r io.Reader) ([]Variant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if format == "jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+	return Variants(ctx, src)
+}
+
+// Variants нарезает уже декодированное изображение на варианты ширин
+// (используется и для сгенерированных обложек).
+func Variants(ctx context.Context, src image.Image) ([]Variant, error) {
+	base := flatten(src)
+
+	var out []Variant
+	for _, w := range targetWidths(base.Bounds().Dx()) {
+		img := resize(base, w)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		out = append(out, Variant{Width: w, Height: img.Bounds().Dy(), Format: "jpeg", Data: buf.Bytes()})
+
+		if WebP != nil {
+			wb, err := WebP.EncodeWebP(ctx, img)
+			if err != nil {
+				// WebP — только оптимизация, JPEG уже есть
+				slog.WarnContext(ctx, "imaging: webp encode failed", "err", err)
+				continue
+			}
+			out = append(out, Variant{Width: w, Height: img.Bounds().Dy(), Format: "webp", Data: wb})
+		}
+	}
+	return out, nil
+}
+
+// targetWidths — ширины из Widths, не превышающие исходную; если исходник
+// меньше минимальной — один вариант исходной ширины
+func targetWidths(srcW int) []int {
+	var ws []int
+	for _, w := range Widths {
+		if w <= srcW {
+			ws = append(ws, w)
+		}
+	}
+	if len(ws) == 0 || (ws[len(ws)-1] < srcW && srcW < Widths[len(Widths)-1]) {
+		ws = append(ws, srcW)
+	}
+	return ws
+}
+
+func resize(src *image.RGBA, w int) image.Image {
+	b := src.Bounds()
+	if w == b.Dx() {
+		return src
+	}
+	h := b.Dy() * w / b.Dx()
+	if h < 1 {
+		h = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Src, nil)
+	return dst
+}
+
+// CWebP кодирует через внешний cwebp (PNG на stdin, WebP на stdout)
+type CWebP struct {
+	Path    string
+	Quality int
+}
+
+func (c *CWebP) EncodeWebP(ctx context.Context, img image.Image) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var in bytes.Buffer
+	if err := png.Encode(&in, img); err != nil {
+		return nil, err
+	}
+
+	var out, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Path, "-quiet", "-q", strconv.Itoa(c.Quality), "-metadata", "none", "-o", "-", "--", "-")
+	cmd.Stdin = &in
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("cwebp: %w: %s", err, stderr.String())
+	}
+	return out.Bytes(), nil
+}
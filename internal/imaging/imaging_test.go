@@ -0,0 +1,25 @@
+package imaging
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTargetWidths(t *testing.T) {
+	for _, tc := range []struct {
+		src  int
+		want []int
+	}{
+		{100, []int{100}}, // меньше минимальной — как есть
+		{320, []int{320}},
+		{500, []int{320, 500}},
+		{640, []int{320, 640}},
+		{1000, []int{320, 640, 960, 1000}},
+		{1280, []int{320, 640, 960, 1280}},
+		{4000, []int{320, 640, 960, 1280}}, // крупнее максимальной не отдаём
+	} {
+		if got := targetWidths(tc.src); !slices.Equal(got, tc.want) {
+			t.Errorf("targetWidths(%d) = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
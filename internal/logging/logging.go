@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"BookCollect/internal/config"
+	"BookCollect/internal/sessions"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Структурные логи на log/slog. Init делает slog-логгер логгером по умолчанию,
+// так что и стандартный log пишет в тот же поток и формат.
+// У каждого запроса свой логгер (Middleware) с request_id и, если есть сессия,
+// admin_id / reviewer_id, а при включённой трассировке — trace_id;
+// From(r) добавляет шаблон маршрута.
+
+// Init настраивает уровень (debug | info | warn | error) и формат (json | text)
+func Init(c config.Log) {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level)) // проверено в config
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if c.Format == "text" {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	} else {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(h))
+}
+
+type ctxKey struct{}
+
+// FromContext — логгер запроса (или общий, если вызвано вне запроса)
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// From — логгер запроса с шаблоном маршрута chi ("/admin/articles/{id}")
+func From(r *http.Request) *slog.Logger {
+	l := FromContext(r.Context())
+	if rc := chi.RouteContext(r.Context()); rc != nil {
+		if p := rc.RoutePattern(); p != "" {
+			l = l.With("route", p)
+		}
+	}
+	return l
+}
+
+// Middleware заводит логгер запроса и пишет строку журнала доступа
+// по завершении (вместо текстового middleware.Logger). Ставится после RequestID,
+// RealIP и tracing.Middleware.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		l := slog.Default().With("request_id", middleware.GetReqID(r.Context()))
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			l = l.With("trace_id", sc.TraceID().String())
+		}
+		if id, ok := sessions.GetAdminID(r); ok {
+			l = l.With("admin_id", id)
+		}
+		if id, ok := sessions.GetReviewerID(r); ok {
+			l = l.With("reviewer_id", id)
+		}
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, l))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		case strings.HasPrefix(r.URL.Path, "/static/") || r.URL.Path == "/healthz" || r.URL.Path == "/readyz":
+			level = slog.LevelDebug // не засоряем журнал статикой и опросами оркестратора
+		}
+		From(r).LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("ip", r.RemoteAddr),
+		)
+	})
+}
+
+// Recoverer — как middleware.Recoverer, но паника со стеком уходит в лог запроса
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if rvr == http.ErrAbortHandler {
+				panic(rvr) // клиент ушёл — так net/http прерывает ответ
+			}
+			From(r).Error("panic", "panic", rvr, "stack", string(debug.Stack()))
+			if r.Header.Get("Connection") != "Upgrade" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
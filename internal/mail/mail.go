@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"BookCollect/internal/config"
+)
+
+// Message — письмо, готовое к отправке
+type Message struct {
+	To      string
+	Subject string
+	Body    string // text/plain, UTF-8
+}
+
+// Transport — способ доставки писем. SMTP в проде, LogTransport — в разработке.
+type Transport interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// LogTransport только пишет письма в лог (когда SMTP не настроен)
+type LogTransport struct{}
+
+func (LogTransport) Send(_ context.Context, m Message) error {
+	slog.Info("mail: not sent, SMTP not configured", "to", m.To, "subject", m.Subject)
+	return nil
+}
+
+// Default — транспорт, через который воркер отправляет письма из outbox
+var Default Transport = LogTransport{}
+
+// Init настраивает SMTP; без SMTPHost письма только пишутся в лог
+func Init(c config.Mail) {
+	if c.SMTPHost == "" {
+		slog.Warn("mail: SMTP_HOST not set, outgoing mail is only logged")
+		return
+	}
+	Default = &SMTP{
+		Addr:     c.SMTPHost + ":" + c.SMTPPort,
+		Username: c.SMTPUser,
+		Password: c.SMTPPassword,
+		TLS:      c.SMTPTLS,
+		From:     c.From,
+	}
+	slog.Info("mail: SMTP", "host", c.SMTPHost, "port", c.SMTPPort, "from", c.From)
+}
+
+/* ========= ШАБЛОНЫ ========= */
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Languages — поддерживаемые языки писем, первый — по умолчанию
+var Languages = []string{"ru", "en"}
+
+// templates[lang] — набор шаблонов templates/<lang>/*.txt
+var templates = func() map[string]*template.Template {
+	m := make(map[string]*template.Template, len(Languages))
+	for _, l := range Languages {
+		m[l] = template.Must(template.ParseFS(templatesFS, "templates/"+l+"/*.txt"))
+	}
+	return m
+}()
+
+// NormalizeLang приводит язык к поддерживаемому
+func NormalizeLang(lang string) string {
+	for _, l := range Languages {
+		if strings.EqualFold(lang, l) {
+			return l
+		}
+	}
+	return Languages[0]
+}
+
+// Render собирает письмо из шаблона templates/<lang>/<name>.txt.
+// Первая строка шаблона — "Subject: ...", остальное — тело.
+func Render(to, lang, name string, data any) (Message, error) {
+	lang = NormalizeLang(lang)
+	t := templates[lang].Lookup(name + ".txt")
+	if t == nil {
+		return Message{}, fmt.Errorf("mail: template %s/%s not found", lang, name)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return Message{}, err
+	}
+	head, body, _ := strings.Cut(buf.String(), "\n")
+	subject, ok := strings.CutPrefix(head, "Subject: ")
+	if !ok {
+		return Message{}, fmt.Errorf("mail: template %s/%s has no Subject line", lang, name)
+	}
+	return Message{To: to, Subject: strings.TrimSpace(subject), Body: body}, nil
+}
@@ -0,0 +1,158 @@
+package mail
+
+import (
+	"BookCollect/internal/db"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Письма не отправляются из хендлеров напрямую: они пишутся в таблицу mail_outbox,
+// а воркер доставляет их с повторами. Так недоступный SMTP не ломает подачу заявки,
+// а письма не теряются при перезапуске.
+
+const (
+	maxAttempts  = 8
+	pollInterval = 15 * time.Second
+	batchSize    = 20
+)
+
+// Enqueue рендерит шаблон и ставит письмо в очередь
+func Enqueue(ctx context.Context, to, lang, name string, data any) error {
+	m, err := Render(to, lang, name, data)
+	if err != nil {
+		return err
+	}
+	_, err = db.DB.ExecContext(ctx, `
+		INSERT INTO mail_outbox (to_addr, subject, body, template)
+		VALUES ($1, $2, $3, $4)`,
+		m.To, m.Subject, m.Body, name)
+	return err
+}
+
+// RunWorker отправляет письма из outbox до отмены ctx
+func RunWorker(ctx context.Context) {
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+	for {
+		if err := deliverBatch(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("mail: outbox", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+type outboxItem struct {
+	id       int
+	attempts int // с учётом текущей попытки
+	msg      Message
+}
+
+// Доставка в три шага: строки забираются (status = 'sending') отдельным
+// коротким запросом, письма отправляются вне транзакции, статус каждого
+// фиксируется сразу после отправки. Сбой БД после отправки повторяет только
+// одно письмо, а не всю пачку.
+//
+// Если экземпляр упал между отправкой и записью статуса, строка остаётся
+// в 'sending' до next_attempt_at (claimLease) и забирается снова.
+const claimLease = 30 * time.Minute // с запасом на batchSize отправок по SMTP.Timeout
+
+func deliverBatch(ctx context.Context) error {
+	items, err := claimBatch(ctx)
+	if err != nil {
+		return err
+	}
+	for i, it := range items {
+		if ctx.Err() != nil {
+			releaseClaims(items[i:])
+			break
+		}
+		if err := Default.Send(ctx, it.msg); err != nil {
+			if ctx.Err() != nil { // остановка посреди отправки — не считаем попыткой
+				releaseClaims(items[i:])
+				break
+			}
+			markFailed(ctx, it, err)
+			continue
+		}
+		if _, err := db.DB.ExecContext(ctx, `
+			UPDATE mail_outbox SET status = 'sent', sent_at = NOW(), last_error = NULL
+			WHERE id = $1`, it.id); err != nil {
+			// письмо ушло; после claimLease оно может уйти повторно — одно, а не пачка
+			slog.Error("mail: outbox update", "id", it.id, "err", err)
+		}
+	}
+	return nil
+}
+
+// claimBatch забирает до batchSize писем, готовых к отправке (и зависшие в
+// 'sending' после падения). SKIP LOCKED — несколько экземпляров приложения не
+// заберут одно письмо дважды.
+func claimBatch(ctx context.Context) ([]outboxItem, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		UPDATE mail_outbox
+		SET status = 'sending', attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM mail_outbox
+			WHERE status IN ('pending', 'sending') AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, attempts, to_addr, subject, body`, batchSize, int(claimLease.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []outboxItem
+	for rows.Next() {
+		var it outboxItem
+		if err := rows.Scan(&it.id, &it.attempts, &it.msg.To, &it.msg.Subject, &it.msg.Body); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(items, func(a, b outboxItem) int { return a.id - b.id })
+	return items, rows.Err()
+}
+
+// releaseClaims возвращает неотправленные при остановке письма в очередь,
+// не дожидаясь claimLease
+func releaseClaims(items []outboxItem) {
+	ids := make([]int64, len(items))
+	for i, it := range items {
+		ids[i] = int64(it.id)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := db.DB.ExecContext(ctx, `
+		UPDATE mail_outbox SET status = 'pending', attempts = attempts - 1, next_attempt_at = NOW()
+		WHERE id = ANY($1) AND status = 'sending'`, pq.Array(ids)); err != nil {
+		slog.Error("mail: outbox release", "err", err)
+	}
+}
+
+// markFailed откладывает письмо с экспоненциальной задержкой; после maxAttempts — failed
+func markFailed(ctx context.Context, it outboxItem, sendErr error) {
+	status := "pending"
+	if it.attempts >= maxAttempts {
+		status = "failed"
+	}
+	delay := time.Minute << (it.attempts - 1) // 1, 2, 4, 8 ... минут
+	slog.Warn("mail: send failed", "id", it.id, "to", it.msg.To, "attempt", it.attempts, "err", sendErr)
+
+	if _, err := db.DB.ExecContext(ctx, `
+		UPDATE mail_outbox
+		SET status = $2, last_error = $3, next_attempt_at = NOW() + $4 * INTERVAL '1 second'
+		WHERE id = $1`,
+		it.id, status, sendErr.Error(), int(delay.Seconds())); err != nil {
+		slog.Error("mail: outbox update", "id", it.id, "err", err)
+	}
+}
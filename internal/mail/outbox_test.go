@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"BookCollect/internal/db"
+	"BookCollect/internal/db/dbtest"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// transportFunc — транспорт-заглушка
+type transportFunc func(context.Context, Message) error
+
+func (f transportFunc) Send(ctx context.Context, m Message) error { return f(ctx, m) }
+
+func withOutbox(t *testing.T, send transportFunc) *dbtest.Fake {
+	t.Helper()
+	fake := dbtest.New()
+	fake.On("UPDATE mail_outbox", dbtest.Result{Affected: 1})
+	fake.On("RETURNING id, attempts", dbtest.Result{
+		Columns: []string{"id", "attempts", "to_addr", "subject", "body"},
+		// RETURNING — в произвольном порядке
+		Rows: [][]driver.Value{
+			{int64(3), int64(1), "c@example.org", "S", "B"},
+			{int64(1), int64(1), "a@example.org", "S", "B"},
+			{int64(2), int64(8), "b@example.org", "S", "B"},
+		},
+	})
+	oldDB, oldT := db.DB, Default
+	db.DB, Default = fake.Open(), send
+	t.Cleanup(func() { db.DB.Close(); db.DB, Default = oldDB, oldT })
+	return fake
+}
+
+// statements — запросы после забора пачки, по первому значимому фрагменту
+func statements(fake *dbtest.Fake) []string {
+	var out []string
+	for _, q := range fake.Queries()[1:] {
+		switch {
+		case strings.Contains(q, "status = 'sent'"):
+			out = append(out, "sent")
+		case strings.Contains(q, "status = 'pending', attempts = attempts - 1"):
+			out = append(out, "release")
+		case strings.Contains(q, "SET status = $2"):
+			out = append(out, "failed")
+		default:
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+func TestDeliverBatchCommitsEachMessage(t *testing.T) {
+	var sent []string
+	fake := withOutbox(t, func(_ context.Context, m Message) error {
+		sent = append(sent, m.To)
+		if m.To == "b@example.org" {
+			return errors.New("550 mailbox unavailable")
+		}
+		return nil
+	})
+
+	if err := deliverBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a@example.org b@example.org c@example.org"; strings.Join(sent, " ") != want {
+		t.Errorf("sent = %v, want %s (in id order)", sent, want)
+	}
+	q := fake.Queries()
+	if !strings.Contains(q[0], "SET status = 'sending'") || !strings.Contains(q[0], "SKIP LOCKED") {
+		t.Errorf("first query does not claim the batch: %s", q[0])
+	}
+	if got, want := strings.Join(statements(fake), ","), "sent,failed,sent"; got != want {
+		t.Errorf("statements = %s, want %s", got, want)
+	}
+}
+
+func TestDeliverBatchStatusErrorDoesNotResendBatch(t *testing.T) {
+	sends := 0
+	fake := withOutbox(t, func(context.Context, Message) error { sends++; return nil })
+	fake.On("status = 'sent'", dbtest.Result{Err: errors.New("connection reset")})
+
+	if err := deliverBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sends != 3 {
+		t.Errorf("sends = %d, want 3 (each message once)", sends)
+	}
+}
+
+func TestDeliverBatchReleasesOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := withOutbox(t, func(ctx context.Context, m Message) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	if err := deliverBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(statements(fake), ","); got != "release" {
+		t.Errorf("statements = %s, want release", got)
+	}
+}
@@ -0,0 +1,198 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP — SMTP-сервер в процессе: принимает одно письмо за сеанс,
+// отвечает 550 на RCPT из reject, запоминает команды и данные письма.
+type fakeSMTP struct {
+	addr     string
+	starttls bool
+	reject   string
+
+	mu   sync.Mutex
+	cmds []string
+	data []string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeSMTP{addr: ln.Addr().String()}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeSMTP) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	reply := func(s string) { _, _ = c.Write([]byte(s + "\r\n")) }
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		f.mu.Lock()
+		f.cmds = append(f.cmds, line)
+		f.mu.Unlock()
+
+		switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+		case "EHLO":
+			ext := "250-fake\r\n250-AUTH PLAIN"
+			if f.starttls {
+				ext += "\r\n250-STARTTLS"
+			}
+			reply(ext + "\r\n250 8BITMIME")
+		case "AUTH":
+			if strings.HasSuffix(line, base64.StdEncoding.EncodeToString([]byte("\x00user\x00pass"))) {
+				reply("235 ok")
+			} else {
+				reply("535 bad credentials")
+			}
+		case "MAIL":
+			reply("250 ok")
+		case "RCPT":
+			if f.reject != "" && strings.Contains(line, f.reject) {
+				reply("550 no such user")
+			} else {
+				reply("250 ok")
+			}
+		case "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			f.mu.Lock()
+			f.data = append(f.data, b.String())
+			f.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (f *fakeSMTP) commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cmds...)
+}
+
+func (f *fakeSMTP) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.data...)
+}
+
+func TestSMTPSend(t *testing.T) {
+	f := newFakeSMTP(t)
+	s := &SMTP{Addr: f.addr, TLS: "none", From: "journal@example.org", Username: "user", Password: "pass", Timeout: 5 * time.Second}
+	err := s.Send(context.Background(), Message{To: "author@example.org", Subject: "Заявка принята", Body: "Строка 1\nСтрока 2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := strings.Join(f.commands(), "\n")
+	for _, want := range []string{"AUTH PLAIN", "MAIL FROM:<journal@example.org>", "RCPT TO:<author@example.org>", "QUIT"} {
+		if !strings.Contains(cmds, want) {
+			t.Errorf("commands lack %q:\n%s", want, cmds)
+		}
+	}
+	data := f.messages()
+	if len(data) != 1 {
+		t.Fatalf("messages = %d, want 1", len(data))
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(data[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Заявка принята" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if id := msg.Header.Get("Message-ID"); !strings.HasSuffix(id, "@example.org>") {
+		t.Errorf("Message-ID = %q", id)
+	}
+	body, _ := base64.StdEncoding.DecodeString(strings.ReplaceAll(readAll(t, msg), "\r\n", ""))
+	if string(body) != "Строка 1\r\nСтрока 2" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestSMTPErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, tls, user, reject, want string
+	}{
+		{"no starttls", "starttls", "", "", "STARTTLS"},
+		{"bad credentials", "none", "wrong", "", "auth"},
+		{"recipient rejected", "none", "", "author@", "RCPT TO"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFakeSMTP(t)
+			f.reject = tc.reject
+			s := &SMTP{Addr: f.addr, TLS: tc.tls, From: "journal@example.org", Username: tc.user, Password: "pass"}
+			err := s.Send(context.Background(), Message{To: "author@example.org", Subject: "S", Body: "B"})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tc.want)
+			}
+			if len(f.messages()) != 0 {
+				t.Error("message was delivered despite the error")
+			}
+		})
+	}
+}
+
+func TestSMTPDialError(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	s := &SMTP{Addr: addr, TLS: "none", From: "journal@example.org", Timeout: time.Second}
+	if err := s.Send(context.Background(), Message{To: "a@example.org"}); err == nil || !strings.Contains(err.Error(), "dial") {
+		t.Fatalf("err = %v, want dial error", err)
+	}
+}
+
+func readAll(t *testing.T, msg *mail.Message) string {
+	t.Helper()
+	var b strings.Builder
+	sc := bufio.NewScanner(msg.Body)
+	for sc.Scan() {
+		b.WriteString(sc.Text())
+	}
+	return b.String()
+}
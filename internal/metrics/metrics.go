@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/internal/db"
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Метрики Prometheus. Отдаются на /metrics:
+//   - METRICS_ADDR задан — отдельным листенером (например, 127.0.0.1:9090),
+//     без авторизации: доступ ограничивается сетью;
+//   - иначе — на основном сервере, только с заголовком
+//     Authorization: Bearer $METRICS_TOKEN; без токена эндпоинт выключен (404).
+
+const namespace = "bookcollect"
+
+// Registry — реестр метрик приложения (без глобального реестра client_golang)
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests — запросы по шаблону маршрута chi ("/collections/{id}"), а не по
+	// фактическому пути: иначе число рядов растёт с каждым новым ID
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "code"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// UploadBytes — размеры сохранённых файлов по каталогу uploads/<dir>
+	// (_count гистограммы — число загрузок)
+	UploadBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upload_size_bytes",
+		Help:      "Sizes of stored uploads by target directory.",
+		Buckets:   prometheus.ExponentialBuckets(16<<10, 4, 8), // 16 КБ … 256 МБ
+	}, []string{"dir"})
+
+	// LoginFailures — неудачные входы: realm admin | reviewer
+	LoginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Failed login attempts by realm.",
+	}, []string{"realm"})
+
+	// APIRequests — запросы к /api/v1 по результату проверки ключа:
+	// ok | limited | unauthorized (по ключам — api_key_usage_daily)
+	APIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_requests_total",
+		Help:      "Partner API requests by key check result.",
+	}, []string{"result"})
+
+	// CacheRequests — обращения к кэшу (internal/cache) по ключу:
+	// hit | miss | error (кэш недоступен — данные взяты из БД)
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by key and result.",
+	}, []string{"key", "result"})
+)
+
+// submissionsDesc — заявки по статусам; считается запросом к БД при каждом опросе
+var submissionsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "submissions"),
+	"Submissions by status.",
+	[]string{"status"}, nil,
+)
+
+type submissionsCollector struct{}
+
+func (submissionsCollector) Describe(ch chan<- *prometheus.Desc) { ch <- submissionsDesc }
+
+func (submissionsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rows, err := db.DB.QueryContext(ctx, `SELECT status, COUNT(*) FROM articles GROUP BY status`)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(submissionsDesc, err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var n float64
+		if err := rows.Scan(&status, &n); err != nil {
+			ch <- prometheus.NewInvalidMetric(submissionsDesc, err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(submissionsDesc, prometheus.GaugeValue, n, status)
+	}
+}
+
+// Addr — отдельный адрес для /metrics; token — для основного сервера
+var (
+	Addr  string
+	token string
+)
+
+// Init регистрирует метрики (после db.InitDB):
+//
+//	Addr  — отдельный листенер для /metrics
+//	Token — bearer-токен для /metrics на основном сервере
+func Init(c config.Metrics) {
+	Addr = c.Addr
+	token = c.Token
+
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db.DB, "bookcollect"),
+		submissionsCollector{},
+		HTTPRequests, HTTPDuration, UploadBytes, LoginFailures, APIRequests, CacheRequests,
+	)
+}
+
+// Middleware считает запросы и время ответа. Шаблон маршрута известен только
+// после роутинга, поэтому читается из контекста chi уже после next.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := "unmatched"
+		if rc := chi.RouteContext(r.Context()); rc != nil {
+			if p := rc.RoutePattern(); p != "" {
+				route = p
+			}
+		}
+		HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(sw.code)).Inc()
+		HTTPDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.code, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap — для http.ResponseController (Flush и т. п.)
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// Handler — /metrics в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Protected — /metrics на основном сервере: только с METRICS_TOKEN
+func Protected() http.HandlerFunc {
+	h := Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		want := "Bearer " + token
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+// Server — отдельный листенер METRICS_ADDR; запуск и остановка — в main
+func Server() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	return &http.Server{
+		Addr:              Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+}
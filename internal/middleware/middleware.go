@@ -1,8 +1,16 @@
 package middleware
 
 import (
+	"BookCollect/internal/db"
 	"BookCollect/internal/sessions"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"mime"
 	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 // Вариант 1: обёртка для конкретных хендлеров (оставляем как есть)
@@ -28,3 +36,50 @@ func AdminOnlyMW(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// ReviewerOnlyMW — доступ только для вошедшего рецензента. Активность
+// проверяется на каждый запрос: отключённый рецензент теряет доступ сразу,
+// а не когда истечёт сессия.
+func ReviewerOnlyMW(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := sessions.GetReviewerID(r)
+		if !ok {
+			http.Redirect(w, r, "/reviewer/login", http.StatusFound)
+			return
+		}
+		var active bool
+		err := db.DB.QueryRowContext(r.Context(), `SELECT active FROM reviewers WHERE id = $1`, id).Scan(&active)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.ErrorContext(r.Context(), "reviewer check failed", "reviewer_id", id, "err", err)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		if !active { // отключён или удалён
+			_ = sessions.ClearReviewerID(w, r)
+			http.Redirect(w, r, "/reviewer/login", http.StatusFound)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HandlerTimeout — срок контекста обычного запроса (chi middleware.Timeout).
+// Загрузки файлов (multipart/form-data) не ограничиваются: рукопись до 25 МБ
+// по медленному каналу идёт дольше, её ограничивают ReadTimeout/WriteTimeout
+// сервера. d <= 0 — без ограничения.
+func HandlerTimeout(d time.Duration) func(http.Handler) http.Handler {
+	if d <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	timeout := middleware.Timeout(d)
+	return func(next http.Handler) http.Handler {
+		limited := timeout(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
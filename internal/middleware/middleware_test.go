@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/internal/db"
+	"BookCollect/internal/db/dbtest"
+	"BookCollect/internal/sessions"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandlerTimeoutSkipsUploads(t *testing.T) {
+	var deadline bool
+	h := HandlerTimeout(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, deadline = r.Context().Deadline()
+	}))
+
+	for _, tc := range []struct {
+		ct   string
+		want bool
+	}{
+		{"", true},
+		{"application/json", true},
+		{"multipart/form-data; boundary=x", false},
+		{"Multipart/Form-Data; boundary=x", false},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Content-Type", tc.ct)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if deadline != tc.want {
+			t.Errorf("Content-Type %q: deadline = %v, want %v", tc.ct, deadline, tc.want)
+		}
+	}
+}
+
+func TestHandlerTimeoutDisabled(t *testing.T) {
+	h := HandlerTimeout(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("deadline set with HandlerTimeout(0)")
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+// reviewerCookie — кука сессии вошедшего рецензента id
+func reviewerCookie(t *testing.T, id int) *http.Cookie {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	if err := sessions.SetReviewerID(rec, httptest.NewRequest(http.MethodGet, "/", nil), id); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Result().Cookies()[0]
+}
+
+func TestReviewerOnlyChecksActive(t *testing.T) {
+	sessions.Init(*config.Default())
+	fake := dbtest.New()
+	old := db.DB
+	db.DB = fake.Open()
+	t.Cleanup(func() { db.DB.Close(); db.DB = old })
+
+	h := ReviewerOnlyMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	cookie := reviewerCookie(t, 7)
+	cols := []string{"active"}
+	for _, tc := range []struct {
+		name     string
+		res      dbtest.Result
+		status   int
+		loggedIn bool // сессия рецензента сохранилась
+	}{
+		{"active", dbtest.Result{Columns: cols, Rows: [][]driver.Value{{true}}}, http.StatusOK, true},
+		{"deactivated", dbtest.Result{Columns: cols, Rows: [][]driver.Value{{false}}}, http.StatusFound, false},
+		{"deleted", dbtest.Result{Columns: cols}, http.StatusFound, false},
+		{"db down", dbtest.Result{Err: errors.New("connection refused")}, http.StatusServiceUnavailable, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.On("FROM reviewers", tc.res)
+			req := httptest.NewRequest(http.MethodGet, "/reviewer", nil)
+			req.AddCookie(cookie)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.status)
+			}
+			cleared := false
+			for _, c := range rec.Result().Cookies() {
+				next := httptest.NewRequest(http.MethodGet, "/", nil)
+				next.AddCookie(c)
+				_, ok := sessions.GetReviewerID(next)
+				cleared = !ok
+			}
+			if cleared == tc.loggedIn {
+				t.Errorf("session cleared = %v, want %v", cleared, !tc.loggedIn)
+			}
+		})
+	}
+
+	// без сессии до БД не доходит
+	before := len(fake.Queries())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reviewer", nil))
+	if rec.Code != http.StatusFound || len(fake.Queries()) != before {
+		t.Errorf("anonymous: status = %d, queries = %d", rec.Code, len(fake.Queries())-before)
+	}
+}
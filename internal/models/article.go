@@ -10,13 +10,68 @@ type Article struct {
 	Title    string `json:"title"`
 	Email    string `json:"email"`
 	FilePath string `json:"file_path"`
+
+	Authors []ArticleAuthor `json:"authors,omitempty"`
 }
 
 type ArticleRow struct {
-	ID        int        `json:"id"`
-	Author    string     `json:"author"`
-	Title     string     `json:"title"`
-	Email     string     `json:"email"`
-	FilePath  string     `json:"file_path"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ID           int        `json:"id"`
+	Author       string     `json:"author"`
+	Title        string     `json:"title"`
+	Email        string     `json:"email"`
+	FilePath     string     `json:"file_path"`
+	Status       string     `json:"status"`
+	CollectionID *int       `json:"collection_id"` // сборник, в котором опубликована
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// ArticleVersion — одна версия файла рукописи (article_versions)
+type ArticleVersion struct {
+	Version      int       `json:"version"`
+	OriginalName string    `json:"original_name"`
+	UploadedBy   string    `json:"uploaded_by"` // author | editor
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Статусы заявки (articles.status)
+const (
+	StatusSubmitted = "submitted"
+	StatusInReview  = "in_review"
+	StatusRevision  = "revision_requested"
+	StatusAccepted  = "accepted"
+	StatusRejected  = "rejected"
+	StatusWithdrawn = "withdrawn" // выставляет только автор
+)
+
+// статус -> подпись по языкам (для писем и страниц)
+var statusLabels = map[string]map[string]string{
+	StatusSubmitted: {"ru": "получена", "en": "received"},
+	StatusInReview:  {"ru": "на рецензировании", "en": "under review"},
+	StatusRevision:  {"ru": "требуется доработка", "en": "revision requested"},
+	StatusAccepted:  {"ru": "принята к публикации", "en": "accepted"},
+	StatusRejected:  {"ru": "отклонена", "en": "rejected"},
+	StatusWithdrawn: {"ru": "отозвана автором", "en": "withdrawn by the author"},
+}
+
+// ValidArticleStatus — статус, который может выставить редактор
+func ValidArticleStatus(s string) bool {
+	_, ok := statusLabels[s]
+	return ok && s != StatusWithdrawn
+}
+
+// ArticleWithdrawable — автор может отозвать заявку, пока по ней нет итогового решения
+func ArticleWithdrawable(s string) bool {
+	return s != StatusAccepted && s != StatusRejected && s != StatusWithdrawn
+}
+
+// ArticleStatusLabel — человекочитаемый статус на нужном языке
+func ArticleStatusLabel(status, lang string) string {
+	if l, ok := statusLabels[status][lang]; ok {
+		return l
+	}
+	if l, ok := statusLabels[status]["ru"]; ok {
+		return l
+	}
+	return status
 }
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ArticleAuthor — автор статьи (таблица article_authors). Position задаёт порядок
+// в списке авторов, начиная с 1; контактный email заявки принадлежит автору с Corresponding.
+type ArticleAuthor struct {
+	Position      int    `json:"position"`
+	Surname       string `json:"surname"`
+	GivenNames    string `json:"given_names"`
+	Affiliation   string `json:"affiliation,omitempty"`
+	ORCID         string `json:"orcid,omitempty"`
+	Corresponding bool   `json:"corresponding"`
+}
+
+// MaxArticleAuthors — предел числа соавторов в одной заявке
+const MaxArticleAuthors = 30
+
+// Initials — инициалы из имён: "Иван Петрович" -> "И. П.", "Anna-Maria" -> "A.-M."
+func (a ArticleAuthor) Initials() string {
+	var parts []string
+	for _, name := range strings.Fields(a.GivenNames) {
+		var sub []string
+		for _, p := range strings.Split(name, "-") {
+			if r, _ := utf8.DecodeRuneInString(p); r != utf8.RuneError {
+				sub = append(sub, string(unicode.ToUpper(r))+".")
+			}
+		}
+		parts = append(parts, strings.Join(sub, "-"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ShortName — "Иванов И. П." (для библиографических ссылок)
+func (a ArticleAuthor) ShortName() string {
+	if in := a.Initials(); in != "" {
+		return a.Surname + " " + in
+	}
+	return a.Surname
+}
+
+// FullName — "Иван Петрович Иванов"
+func (a ArticleAuthor) FullName() string {
+	return strings.TrimSpace(a.GivenNames + " " + a.Surname)
+}
+
+// AuthorsLine — список авторов одной строкой ("Иванов И. П., Петров А. А.");
+// пишется в articles.author для писем, админки и старых выгрузок.
+func AuthorsLine(list []ArticleAuthor) string {
+	names := make([]string, len(list))
+	for i, a := range list {
+		names[i] = a.ShortName()
+	}
+	return strings.Join(names, ", ")
+}
+
+// NormalizeORCID приводит ORCID iD к виду 0000-0002-1825-0097: принимает
+// ссылку https://orcid.org/..., строку без дефисов и строчную x.
+func NormalizeORCID(s string) string {
+	s = strings.TrimSpace(s)
+	for _, p := range []string{"https://orcid.org/", "http://orcid.org/", "orcid.org/"} {
+		s = strings.TrimPrefix(s, p)
+	}
+	s = strings.ToUpper(strings.ReplaceAll(s, "-", ""))
+	if len(s) != 16 {
+		return s
+	}
+	return s[0:4] + "-" + s[4:8] + "-" + s[8:12] + "-" + s[12:16]
+}
+
+// ValidORCID проверяет формат и контрольную цифру ORCID iD (ISO 7064 MOD 11-2).
+// Ожидается нормализованная строка (см. NormalizeORCID).
+func ValidORCID(s string) bool {
+	if len(s) != 19 || s[4] != '-' || s[9] != '-' || s[14] != '-' {
+		return false
+	}
+	digits := strings.ReplaceAll(s, "-", "")
+	total := 0
+	for i := 0; i < 15; i++ {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		total = (total + int(c-'0')) * 2
+	}
+	check := (12 - total%11) % 11
+	want := byte('0' + check)
+	if check == 10 {
+		want = 'X'
+	}
+	return digits[15] == want
+}
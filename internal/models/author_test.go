@@ -0,0 +1,62 @@
+package models
+
+import "testing"
+
+func TestORCID(t *testing.T) {
+	for _, tc := range []struct {
+		in, norm string
+		valid    bool
+	}{
+		{"0000-0002-1825-0097", "0000-0002-1825-0097", true},
+		{"0000-0002-1694-233X", "0000-0002-1694-233X", true}, // контрольная цифра 10
+		{"0000-0002-1694-233x", "0000-0002-1694-233X", true},
+		{"https://orcid.org/0000-0001-5109-3700", "0000-0001-5109-3700", true},
+		{"http://orcid.org/0000-0001-5109-3700", "0000-0001-5109-3700", true},
+		{"orcid.org/0000-0001-5109-3700", "0000-0001-5109-3700", true},
+		{"  0000000218250097 ", "0000-0002-1825-0097", true},
+		{"0000-0002-1825-0098", "0000-0002-1825-0098", false}, // контрольная цифра
+		{"0000-0002-1694-2330", "0000-0002-1694-2330", false},
+		{"0000-0002-1825-009X", "0000-0002-1825-009X", false},
+		{"0000-000X-1825-0097", "0000-000X-1825-0097", false}, // X не в конце
+		{"0000-0002-1825", "000000021825", false},
+		{"", "", false},
+	} {
+		norm := NormalizeORCID(tc.in)
+		if norm != tc.norm {
+			t.Errorf("NormalizeORCID(%q) = %q, want %q", tc.in, norm, tc.norm)
+		}
+		if got := ValidORCID(norm); got != tc.valid {
+			t.Errorf("ValidORCID(%q) = %v, want %v", norm, got, tc.valid)
+		}
+	}
+
+	// без нормализации — только канонический вид
+	if ValidORCID("0000000218250097") {
+		t.Error("ValidORCID accepted an ID without hyphens")
+	}
+}
+
+func TestInitials(t *testing.T) {
+	for given, want := range map[string]string{
+		"Иван Петрович":       "И. П.",
+		"Anna-Maria":          "A.-M.",
+		"анна-мария ивановна": "А.-М. И.",
+		"John Ronald Reuel":   "J. R. R.",
+		"  Иван   Петрович  ": "И. П.",
+		"Jean-":               "J.",
+		"":                    "",
+	} {
+		a := ArticleAuthor{Surname: "Иванов", GivenNames: given}
+		if got := a.Initials(); got != want {
+			t.Errorf("Initials(%q) = %q, want %q", given, got, want)
+		}
+	}
+
+	a := ArticleAuthor{Surname: "Иванов", GivenNames: "Иван Петрович"}
+	if got := a.ShortName(); got != "Иванов И. П." {
+		t.Errorf("ShortName = %q", got)
+	}
+	if got := (ArticleAuthor{Surname: "Иванов"}).ShortName(); got != "Иванов" {
+		t.Errorf("ShortName without given names = %q", got)
+	}
+}
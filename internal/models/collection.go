@@ -1,6 +1,17 @@
 package models
 
-import "database/sql"
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JournalTitle — название издания: библиографические ссылки, отчёты COUNTER,
+// типографские обложки
+const JournalTitle = "Актуальные вопросы судебной медицины и права"
 
 // Базовая сущность из таблицы collections
 type Collection struct {
@@ -12,18 +23,20 @@ type Collection struct {
 	CoverImage      sql.NullString `json:"cover_image"`      // путь/URL обложки (nullable)
 	PublicationLink string         `json:"publication_link"` // может быть пустой строкой
 	PDFPath         sql.NullString `json:"pdf_path"`         // путь к PDF (nullable)
+	CoverVariants   CoverVariants  `json:"cover_variants"`   // уменьшенные копии обложки (nullable)
 }
 
 // Удобный ответ наружу (JSON API), уже без sql.Null*
 type CollectionResponse struct {
-	ID              int     `json:"id"`
-	ReleaseNumber   *int32  `json:"release_number,omitempty"`
-	ReleaseYear     *int32  `json:"release_year,omitempty"`
-	Title           string  `json:"title,omitempty"`
-	Description     *string `json:"description,omitempty"`
-	CoverImage      *string `json:"cover_image,omitempty"`
-	PublicationLink string  `json:"publication_link,omitempty"`
-	PDFPath         *string `json:"pdf_path,omitempty"`
+	ID              int           `json:"id"`
+	ReleaseNumber   *int32        `json:"release_number,omitempty"`
+	ReleaseYear     *int32        `json:"release_year,omitempty"`
+	Title           string        `json:"title,omitempty"`
+	Description     *string       `json:"description,omitempty"`
+	CoverImage      *string       `json:"cover_image,omitempty"`
+	PublicationLink string        `json:"publication_link,omitempty"`
+	PDFPath         *string       `json:"pdf_path,omitempty"` // ссылка на скачивание со счётчиком, не путь к файлу
+	CoverVariants   CoverVariants `json:"cover_variants,omitempty"`
 }
 
 // Запрос на создание/обновление через JSON API (PUT /admin/collection/{id})
@@ -33,9 +46,7 @@ type CollectionRequest struct {
 	ReleaseYear     *int32  `json:"release_year,omitempty"`
 	Title           string  `json:"title"`
 	Description     *string `json:"description,omitempty"`
-	CoverImage      *string `json:"cover_image,omitempty"`
 	PublicationLink string  `json:"publication_link"`
-	PDFPath         *string `json:"pdf_path"`
 }
 
 // Маппинг из базы (с Null*) в удобный API-ответ
@@ -55,9 +66,11 @@ func CollectionToResponse(c Collection) CollectionResponse {
 		coverImage = &c.CoverImage.String
 	}
 
+	// сам файл наружу не отдаётся: скачивание только через счётчик
 	var pdfPath *string
-	if c.PDFPath.Valid {
-		pdfPath = &c.PDFPath.String
+	if c.PDFPath.Valid && c.PDFPath.String != "" {
+		u := fmt.Sprintf("/collections/%d/pdf", c.ID)
+		pdfPath = &u
 	}
 
 	// Description уже *string — достаточно передать как есть
@@ -70,5 +83,47 @@ func CollectionToResponse(c Collection) CollectionResponse {
 		CoverImage:      coverImage,
 		PublicationLink: c.PublicationLink,
 		PDFPath:         pdfPath,
+		CoverVariants:   c.CoverVariants,
+	}
+}
+
+// CoverVariant — один адаптивный вариант обложки (хранится в collections.cover_variants)
+type CoverVariant struct {
+	Width  int    `json:"width"`
+	Format string `json:"format"` // jpeg | webp
+	Path   string `json:"path"`
+}
+
+// CoverVariants — JSONB-колонка со всеми вариантами обложки
+type CoverVariants []CoverVariant
+
+func (v *CoverVariants) Scan(src any) error {
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(s, v)
+	case string:
+		return json.Unmarshal([]byte(s), v)
+	}
+	return fmt.Errorf("cover_variants: unsupported type %T", src)
+}
+
+func (v CoverVariants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Srcset — значение атрибута srcset для нужного формата ("320w" и т.д.)
+func (v CoverVariants) Srcset(format string) string {
+	var parts []string
+	for _, c := range v {
+		if c.Format == format {
+			parts = append(parts, c.Path+" "+strconv.Itoa(c.Width)+"w")
+		}
 	}
+	return strings.Join(parts, ", ")
 }
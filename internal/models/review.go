@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Reviewer — учётная запись рецензента (таблица reviewers).
+// Пароль, как и у администраторов, хранится bcrypt-хэшем.
+type Reviewer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Login     string    `json:"login"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewAssignment — назначение рецензента на статью
+type ReviewAssignment struct {
+	ID           int           `json:"id"`
+	ArticleID    int           `json:"article_id"`
+	ReviewerID   int           `json:"reviewer_id"`
+	ReviewerName string        `json:"reviewer_name,omitempty"`
+	DueDate      *time.Time    `json:"due_date,omitempty"`
+	Status       string        `json:"status"` // assigned | submitted | declined
+	CreatedAt    time.Time     `json:"created_at"`
+	Report       *ReviewReport `json:"report,omitempty"`
+}
+
+// ReviewReport — заключение рецензента
+type ReviewReport struct {
+	Recommendation       string    `json:"recommendation"`
+	CommentsForAuthor    string    `json:"comments_for_author"`
+	ConfidentialComments string    `json:"confidential_comments"` // видит только редакция
+	SubmittedAt          time.Time `json:"submitted_at"`
+}
+
+// Статусы назначения
+const (
+	AssignmentAssigned  = "assigned"
+	AssignmentSubmitted = "submitted"
+	AssignmentDeclined  = "declined"
+)
+
+// Рекомендации рецензента (в порядке от лучшей к худшей)
+var Recommendations = []string{"accept", "minor_revision", "major_revision", "reject"}
+
+var recommendationLabels = map[string]string{
+	"accept":         "Принять",
+	"minor_revision": "Принять после небольшой доработки",
+	"major_revision": "Существенная доработка",
+	"reject":         "Отклонить",
+}
+
+// ValidRecommendation — рекомендация из списка Recommendations
+func ValidRecommendation(s string) bool {
+	_, ok := recommendationLabels[s]
+	return ok
+}
+
+// RecommendationLabel — подпись рекомендации для страниц
+func RecommendationLabel(s string) string {
+	if l, ok := recommendationLabels[s]; ok {
+		return l
+	}
+	return s
+}
+
+// ReviewSummary — сводка заключений по статье для редактора
+type ReviewSummary struct {
+	Assigned        int                `json:"assigned"`
+	Submitted       int                `json:"submitted"`
+	Overdue         int                `json:"overdue"`
+	Recommendations map[string]int     `json:"recommendations"`
+	Assignments     []ReviewAssignment `json:"assignments"`
+}
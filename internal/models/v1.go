@@ -0,0 +1,94 @@
+package models
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Модели ответа /api/v1. Это внешний контракт для партнёров: поля не
+// переименовываются и не исчезают в пределах v1, новые только добавляются.
+// Отдельно от CollectionResponse (тот повторяет строку БД и может меняться
+// вместе со схемой): пустые значения — явный null, пути к файлам — URL.
+
+// V1Collection — сборник
+type V1Collection struct {
+	ID             int      `json:"id"`
+	Title          string   `json:"title"`
+	ReleaseYear    *int     `json:"release_year"`
+	ReleaseNumber  *int     `json:"release_number"`
+	Description    *string  `json:"description"`
+	URL            string   `json:"url"` // страница сборника на сайте
+	PublicationURL *string  `json:"publication_url"`
+	PDFURL         *string  `json:"pdf_url"`
+	Cover          *V1Cover `json:"cover"`
+}
+
+// V1Cover — обложка и её уменьшенные копии (для srcset)
+type V1Cover struct {
+	URL      string           `json:"url"`
+	Variants []V1CoverVariant `json:"variants"`
+}
+
+type V1CoverVariant struct {
+	Width  int    `json:"width"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+}
+
+// V1Page — положение страницы списка
+type V1Page struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// V1CollectionList — страница списка сборников
+type V1CollectionList struct {
+	Data []V1Collection `json:"data"`
+	Page V1Page         `json:"page"`
+}
+
+// CollectionToV1 — сборник для /api/v1; base — внешний адрес сайта
+// ("https://example.org"), к нему приводятся пути к файлам
+func CollectionToV1(c Collection, base string) V1Collection {
+	out := V1Collection{
+		ID:          c.ID,
+		Title:       c.Title,
+		Description: c.Description,
+		URL:         base + "/collections/" + strconv.Itoa(c.ID),
+	}
+	if c.ReleaseYear.Valid {
+		v := int(c.ReleaseYear.Int32)
+		out.ReleaseYear = &v
+	}
+	if c.ReleaseNumber.Valid {
+		v := int(c.ReleaseNumber.Int32)
+		out.ReleaseNumber = &v
+	}
+	if c.PublicationLink != "" {
+		v := c.PublicationLink
+		out.PublicationURL = &v
+	}
+	if c.PDFPath.Valid && c.PDFPath.String != "" {
+		// PDF отдаётся через страницу со счётчиком скачиваний
+		v := out.URL + "/pdf"
+		out.PDFURL = &v
+	}
+	if c.CoverImage.Valid && c.CoverImage.String != "" {
+		cover := &V1Cover{URL: fileURL(base, c.CoverImage.String), Variants: []V1CoverVariant{}}
+		for _, v := range c.CoverVariants {
+			cover.Variants = append(cover.Variants, V1CoverVariant{Width: v.Width, Format: v.Format, URL: fileURL(base, v.Path)})
+		}
+		out.Cover = cover
+	}
+	return out
+}
+
+// fileURL — абсолютный URL файла: в БД лежат и "uploads/x.jpg", и "/uploads/x.jpg", и внешние ссылки
+func fileURL(base, p string) string {
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		return p
+	}
+	return base + path.Clean("/"+p)
+}
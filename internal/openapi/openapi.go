@@ -0,0 +1,293 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Контракт JSON API — openapi.json (OpenAPI 3.1, пишется руками рядом с
+// хендлерами и меняется вместе с ними). Отдаётся на /api/openapi.json,
+// человекочитаемо — на /api/docs. Тела запросов хендлеры проверяют по
+// схемам отсюда же (Validate), поэтому документ и проверки не расходятся.
+
+//go:embed openapi.json
+var spec []byte
+
+// Handler — GET /api/openapi.json
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	_, _ = w.Write(spec)
+}
+
+// DocsHandler — GET /api/docs: Swagger UI поверх /api/openapi.json
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(docsPage))
+}
+
+// Swagger UI — с CDN: в образ не тянем, страница нужна только разработчикам
+const docsPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+    <meta charset="UTF-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <title>BookCollect API</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+    SwaggerUIBundle({ url: '/api/openapi.json', dom_id: '#swagger-ui', deepLinking: true });
+</script>
+</body>
+</html>
+`
+
+/* ========= ПРОВЕРКА ПО СХЕМЕ ========= */
+
+// Поддерживается то подмножество JSON Schema, что есть в openapi.json:
+// $ref, type (в том числе ["integer", "null"]), required, properties,
+// additionalProperties: false, items, enum, const, minimum, maximum,
+// minLength, maxLength, pattern. Остальные ключевые слова не проверяются.
+
+// Ключевые слова нарушений (Issue.Keyword)
+const (
+	KeywordRequired   = "required"
+	KeywordUnknown    = "additionalProperties"
+	KeywordType       = "type"
+	KeywordEnum       = "enum"
+	KeywordMinimum    = "minimum"
+	KeywordMaximum    = "maximum"
+	KeywordMinLength  = "minLength"
+	KeywordMaxLength  = "maxLength"
+	KeywordPattern    = "pattern"
+	KeywordJSONSyntax = "json"
+)
+
+// Issue — одно нарушение: Field — путь в теле ("title", "authors[2].orcid";
+// пусто — тело целиком), Limit — значение нарушенного ограничения схемы
+type Issue struct {
+	Field   string
+	Keyword string
+	Limit   any
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 typeList           `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Const                any                `json:"const"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+}
+
+// typeList — "type": "string" или "type": ["string", "null"]
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+var (
+	loadOnce sync.Once
+	schemas  map[string]*schema
+	patterns sync.Map // pattern -> *regexp.Regexp
+)
+
+// loadSchemas разбирает components.schemas; документ встроен в бинарник,
+// так что ошибка разбора — ошибка сборки, а не данных
+func loadSchemas() {
+	var doc struct {
+		Components struct {
+			Schemas map[string]*schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		panic("openapi: " + err.Error())
+	}
+	schemas = doc.Components.Schemas
+}
+
+// Validate проверяет JSON body по схеме components.schemas[name]. Возвращает
+// все нарушения; невалидный JSON — одно нарушение KeywordJSONSyntax.
+func Validate(name string, body []byte) []Issue {
+	loadOnce.Do(loadSchemas)
+	s, ok := schemas[name]
+	if !ok {
+		panic("openapi: unknown schema " + name)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return []Issue{{Keyword: KeywordJSONSyntax}}
+	}
+	var out []Issue
+	validate(s, v, "", &out)
+	return out
+}
+
+func resolve(s *schema) *schema {
+	for s.Ref != "" {
+		s = schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func validate(s *schema, v any, path string, out *[]Issue) {
+	s = resolve(s)
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(v, t) }) {
+		*out = append(*out, Issue{Field: path, Keyword: KeywordType, Limit: strings.Join(s.Type, "|")})
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		*out = append(*out, Issue{Field: path, Keyword: KeywordEnum, Limit: s.Enum})
+	}
+	if s.Const != nil && !equal(s.Const, v) {
+		*out = append(*out, Issue{Field: path, Keyword: KeywordEnum, Limit: []any{s.Const}})
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*out = append(*out, Issue{Field: join(path, name), Keyword: KeywordRequired})
+			}
+		}
+		// порядок ключей в map случаен — сортируем, чтобы ответ был стабильным
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				validate(ps, v[k], join(path, k), out)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*out = append(*out, Issue{Field: join(path, k), Keyword: KeywordUnknown})
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				validate(s.Items, item, path+"["+strconv.Itoa(i)+"]", out)
+			}
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			*out = append(*out, Issue{Field: path, Keyword: KeywordMinimum, Limit: *s.Minimum})
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			*out = append(*out, Issue{Field: path, Keyword: KeywordMaximum, Limit: *s.Maximum})
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			*out = append(*out, Issue{Field: path, Keyword: KeywordMinLength, Limit: *s.MinLength})
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			*out = append(*out, Issue{Field: path, Keyword: KeywordMaxLength, Limit: *s.MaxLength})
+		}
+		if s.Pattern != "" && !pattern(s.Pattern).MatchString(v) {
+			*out = append(*out, Issue{Field: path, Keyword: KeywordPattern, Limit: s.Pattern})
+		}
+	}
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		// строже JSON Schema (там 2.0 — целое): encoding/json в int его не примет
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	}
+	return true
+}
+
+// equal — сравнение значения из схемы (числа — float64) со значением из тела (json.Number)
+func equal(want, got any) bool {
+	if n, ok := got.(json.Number); ok {
+		f, err := n.Float64()
+		return err == nil && want == f
+	}
+	return fmt.Sprint(want) == fmt.Sprint(got) && hasType(got, typeOf(want))
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	return ""
+}
+
+func pattern(p string) *regexp.Regexp {
+	if re, ok := patterns.Load(p); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(p)
+	patterns.Store(p, re)
+	return re
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
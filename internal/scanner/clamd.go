@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Clamd — клиент демона ClamAV по протоколу clamd (команда INSTREAM).
+// Работает и через unix-сокет, и через TCP, поэтому в тестах его можно
+// направить на локальный фейковый clamd.
+type Clamd struct {
+	Network string // "unix" или "tcp"
+	Address string
+	Timeout time.Duration
+}
+
+// Размер чанка INSTREAM; clamd сам ограничивает поток через StreamMaxLength
+const clamdChunk = 32 << 10
+
+var ErrClamd = errors.New("clamd: scan error")
+
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return Result{}, fmt.Errorf("clamd: dial: %w", err)
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+
+	// z-префикс: команда и ответ разделяются нулевым байтом
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, fmt.Errorf("clamd: write command: %w", err)
+	}
+
+	buf := make([]byte, clamdChunk)
+	var size [4]byte
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, err := conn.Write(size[:]); err != nil {
+				return replyAfterWriteError(conn, fmt.Errorf("clamd: write chunk: %w", err))
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return replyAfterWriteError(conn, fmt.Errorf("clamd: write chunk: %w", err))
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return Result{}, fmt.Errorf("clamd: read upload: %w", rerr)
+		}
+	}
+	// Нулевой чанк — конец потока
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := conn.Write(size[:]); err != nil {
+		return replyAfterWriteError(conn, fmt.Errorf("clamd: write terminator: %w", err))
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return Result{}, fmt.Errorf("clamd: read reply: %w", err)
+	}
+	return parseReply(reply)
+}
+
+// replyAfterWriteError — clamd обрывает поток сверх StreamMaxLength, успев
+// ответить «INSTREAM size limit exceeded»: этот ответ понятнее ошибки записи
+func replyAfterWriteError(conn net.Conn, werr error) (Result, error) {
+	reply, _ := bufio.NewReader(conn).ReadString(0)
+	if strings.TrimSpace(strings.TrimRight(reply, "\x00")) == "" {
+		return Result{}, werr
+	}
+	return parseReply(reply)
+}
+
+// parseReply разбирает ответ вида:
+//
+//	stream: OK
+//	stream: Eicar-Test-Signature FOUND
+//	INSTREAM size limit exceeded. ERROR
+func parseReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	msg := strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case msg == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(msg, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(msg, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("%w: %s", ErrClamd, reply)
+}
@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd — clamd в процессе: принимает INSTREAM и отвечает reply(данные).
+// При maxLen > 0 обрывает поток, как clamd с StreamMaxLength.
+type fakeClamd struct {
+	reply  func(data []byte) string
+	maxLen int
+}
+
+func (f fakeClamd) start(t *testing.T) *Clamd {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return &Clamd{Network: "tcp", Address: ln.Addr().String(), Timeout: 5 * time.Second}
+}
+
+func (f fakeClamd) serve(c net.Conn) {
+	defer c.Close()
+	cmd := make([]byte, len("zINSTREAM\x00"))
+	if _, err := io.ReadFull(c, cmd); err != nil || string(cmd) != "zINSTREAM\x00" {
+		_, _ = c.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+	var data []byte
+	var size [4]byte
+	for {
+		if _, err := io.ReadFull(c, size[:]); err != nil {
+			return
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		if n == 0 {
+			break
+		}
+		if f.maxLen > 0 && len(data)+int(n) > f.maxLen {
+			_, _ = c.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+			return
+		}
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(c, chunk); err != nil {
+			return
+		}
+		data = append(data, chunk...)
+	}
+	_, _ = c.Write([]byte(f.reply(data) + "\x00"))
+}
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+func signatures(data []byte) string {
+	if bytes.Contains(data, []byte("EICAR-STANDARD")) {
+		return "stream: Eicar-Test-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestClamdScan(t *testing.T) {
+	c := fakeClamd{reply: signatures}.start(t)
+	ctx := context.Background()
+
+	// больше одного чанка: данные доходят целиком
+	clean := bytes.Repeat([]byte("a"), 3*clamdChunk+17)
+	if res, err := c.Scan(ctx, bytes.NewReader(clean)); err != nil || res.Infected {
+		t.Errorf("clean file: %+v, %v", res, err)
+	}
+
+	res, err := c.Scan(ctx, strings.NewReader(eicar))
+	if err != nil || !res.Infected || res.Signature != "Eicar-Test-Signature" {
+		t.Errorf("EICAR: %+v, %v; want Eicar-Test-Signature", res, err)
+	}
+}
+
+func TestClamdErrorReply(t *testing.T) {
+	c := fakeClamd{reply: func([]byte) string { return "stream: Can't allocate memory ERROR" }}.start(t)
+	_, err := c.Scan(context.Background(), strings.NewReader("x"))
+	if !errors.Is(err, ErrClamd) || !strings.Contains(err.Error(), "allocate memory") {
+		t.Fatalf("err = %v, want ErrClamd with the reply", err)
+	}
+}
+
+func TestClamdSizeLimit(t *testing.T) {
+	c := fakeClamd{reply: signatures, maxLen: clamdChunk}.start(t)
+	// с запасом больше буферов сокета: запись упрётся в закрытое соединение
+	big := bytes.NewReader(bytes.Repeat([]byte("a"), 16<<20))
+	_, err := c.Scan(context.Background(), big)
+	if !errors.Is(err, ErrClamd) || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Fatalf("err = %v, want ErrClamd size limit", err)
+	}
+}
+
+func TestClamdUnavailable(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	c := &Clamd{Network: "tcp", Address: addr, Timeout: time.Second}
+	if _, err := c.Scan(context.Background(), strings.NewReader("x")); err == nil || !strings.Contains(err.Error(), "dial") {
+		t.Fatalf("err = %v, want dial error", err)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	for _, tc := range []struct {
+		reply    string
+		infected bool
+		sig      string
+		err      bool
+	}{
+		{"stream: OK\x00", false, "", false},
+		{"stream: Win.Test.EICAR_HDB-1 FOUND\x00", true, "Win.Test.EICAR_HDB-1", false},
+		{"INSTREAM size limit exceeded. ERROR", false, "", true},
+		{"", false, "", true},
+	} {
+		res, err := parseReply(tc.reply)
+		if res.Infected != tc.infected || res.Signature != tc.sig || (err != nil) != tc.err {
+			t.Errorf("parseReply(%q) = %+v, %v", tc.reply, res, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"BookCollect/internal/config"
+)
+
+// Result — итог проверки одного файла
+type Result struct {
+	Infected  bool
+	Signature string // имя сигнатуры, если файл заражён
+}
+
+// Scanner — антивирусная проверка загружаемых файлов.
+// Вызывается на каждую загрузку до того, как файл попадёт в uploads/.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// Nop — заглушка, когда антивирус не настроен: всё считается чистым
+type Nop struct{}
+
+func (Nop) Scan(context.Context, io.Reader) (Result, error) { return Result{}, nil }
+
+var (
+	// Default — сканер, которым пользуются хендлеры
+	Default Scanner = Nop{}
+	// QuarantineDir — куда складываем заражённые файлы (НЕ внутри uploads/, чтобы их не раздавал FileServer)
+	QuarantineDir = "quarantine"
+)
+
+// Init настраивает сканер:
+//
+//	ClamdAddr     — адрес clamd: unix:/run/clamav/clamd.ctl, tcp:127.0.0.1:3310 или host:port
+//	ClamdTimeout  — таймаут проверки одного файла
+//	quarantineDir — каталог карантина
+func Init(c config.Scanner, quarantineDir string) {
+	QuarantineDir = quarantineDir
+
+	if c.ClamdAddr == "" {
+		slog.Warn("scanner: CLAMD_ADDR not set, uploads are not scanned")
+		return
+	}
+
+	network, address := ParseAddr(c.ClamdAddr)
+	Default = &Clamd{Network: network, Address: address, Timeout: c.ClamdTimeout}
+	slog.Info("scanner: clamd", "network", network, "address", address)
+}
+
+// ParseAddr разбирает адрес clamd в пару network/address для net.Dial
+func ParseAddr(addr string) (network, address string) {
+	switch {
+	case strings.HasPrefix(addr, "unix:"):
+		return "unix", strings.TrimPrefix(addr, "unix:")
+	case strings.HasPrefix(addr, "tcp:"):
+		return "tcp", strings.TrimPrefix(addr, "tcp:")
+	case strings.HasPrefix(addr, "/"):
+		return "unix", addr
+	}
+	return "tcp", addr
+}
+
+// Quarantine сохраняет заражённый файл в карантин вместе с описанием.
+// Возвращает путь к сохранённому файлу; если файл сохранить не удалось,
+// недописанный остаток удаляется.
+func Quarantine(r io.Reader, origName string, res Result) (string, error) {
+	if err := os.MkdirAll(QuarantineDir, 0o700); err != nil {
+		return "", err
+	}
+
+	base := fmt.Sprintf("%d_%s", time.Now().UnixNano(), safeName(origName))
+	dstPath := filepath.Join(QuarantineDir, base+".bin")
+
+	// 0600 и расширение .bin — чтобы файл случайно не открыли двойным кликом
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(dst, r)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(dstPath)
+		return "", err
+	}
+
+	meta := fmt.Sprintf("original: %s\nsignature: %s\ntime: %s\n",
+		origName, res.Signature, time.Now().Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(QuarantineDir, base+".txt"), []byte(meta), 0o600); err != nil {
+		return dstPath, err
+	}
+	return dstPath, nil
+}
+
+func safeName(s string) string {
+	s = filepath.Base(s)
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
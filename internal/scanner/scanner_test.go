@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingReader struct{ n int }
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.n == 0 {
+		return 0, errors.New("connection reset")
+	}
+	f.n--
+	return copy(p, "partial"), nil
+}
+
+func TestQuarantine(t *testing.T) {
+	old := QuarantineDir
+	QuarantineDir = t.TempDir()
+	t.Cleanup(func() { QuarantineDir = old })
+	res := Result{Infected: true, Signature: "Eicar-Test-Signature"}
+
+	path, err := Quarantine(strings.NewReader("X5O!P%@AP"), "../../evil file.docx", res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != QuarantineDir || !strings.HasSuffix(path, "_evil_file.docx.bin") {
+		t.Errorf("path = %q", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "X5O!P%@AP" {
+		t.Errorf("quarantined content = %q", data)
+	}
+	meta, _ := os.ReadFile(strings.TrimSuffix(path, ".bin") + ".txt")
+	if !strings.Contains(string(meta), "signature: Eicar-Test-Signature") {
+		t.Errorf("meta = %q", meta)
+	}
+
+	// обрыв чтения — недописанный файл не остаётся
+	if path, err := Quarantine(&failingReader{n: 2}, "broken.pdf", res); err == nil || path != "" {
+		t.Fatalf("failed copy: path = %q, err = %v", path, err)
+	}
+	entries, _ := os.ReadDir(QuarantineDir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), "broken") {
+			t.Errorf("partial file %s left in quarantine", e.Name())
+		}
+	}
+}
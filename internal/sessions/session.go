@@ -1,21 +1,30 @@
 package sessions
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
-	"os"
+
+	"BookCollect/internal/config"
 
 	"github.com/gorilla/sessions"
 )
 
 var store *sessions.CookieStore
 
+// signKey — ключ для подписанных ссылок (см. Sign/Verify)
+var signKey []byte
+
 const sessionName = "admin_session"
 
-func init() {
-	secret := os.Getenv("SESSION_SECRET")
+// Init создаёт хранилище сессий. Пустой секрет допустим только локально
+// (config не пропустит его при APP_HTTPS=1).
+func Init(c config.Config) {
+	secret := c.Session.Secret
 	if secret == "" {
-		// в докере это может быть пусто — но без секрета работать нельзя
+		slog.Warn("sessions: SESSION_SECRET not set, using an insecure development secret")
 		secret = "dev-insecure-secret-change-me-now"
 	}
 
@@ -24,13 +33,16 @@ func init() {
 	h := sha256.Sum256([]byte("auth:" + secret))
 	e := sha256.Sum256([]byte("enc:" + secret))
 
+	k := sha256.Sum256([]byte("sign:" + secret))
+	signKey = k[:]
+
 	store = sessions.NewCookieStore(h[:], e[:])
 	store.Options = &sessions.Options{
 		Path:     "/",
 		MaxAge:   7 * 24 * 60 * 60, // 7 дней
 		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,          // кука по GET тоже отправится
-		Secure:   os.Getenv("APP_HTTPS") == "1", // локально 0, за HTTPS-прокси — 1
+		SameSite: http.SameSiteLaxMode, // кука по GET тоже отправится
+		Secure:   c.Server.HTTPS,       // локально false, за HTTPS-прокси — true
 	}
 }
 
@@ -66,3 +78,49 @@ func ClearAdminID(w http.ResponseWriter, r *http.Request) error {
 	delete(s.Values, "admin_id")
 	return s.Save(r, w)
 }
+
+// ----- Рецензенты: отдельный ключ в той же сессии -----
+
+func SetReviewerID(w http.ResponseWriter, r *http.Request, reviewerID int) error {
+	s, err := GetSession(r)
+	if err != nil {
+		return err
+	}
+	s.Values["reviewer_id"] = reviewerID
+	return s.Save(r, w)
+}
+
+func GetReviewerID(r *http.Request) (int, bool) {
+	s, err := GetSession(r)
+	if err != nil {
+		return 0, false
+	}
+	if v, ok := s.Values["reviewer_id"].(int); ok {
+		return v, true
+	}
+	return 0, false
+}
+
+func ClearReviewerID(w http.ResponseWriter, r *http.Request) error {
+	s, err := GetSession(r)
+	if err != nil {
+		return err
+	}
+	delete(s.Values, "reviewer_id")
+	return s.Save(r, w)
+}
+
+// ----- Подписанные ссылки -----
+
+// Sign возвращает HMAC-SHA256 подпись значения (hex) — для ссылок с ограниченным
+// доступом, например скачивания рукописи рецензентом.
+func Sign(value string) string {
+	m := hmac.New(sha256.New, signKey)
+	m.Write([]byte(value))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify проверяет подпись за постоянное время
+func Verify(value, sig string) bool {
+	return hmac.Equal([]byte(Sign(value)), []byte(sig))
+}
@@ -0,0 +1,29 @@
+package stats
+
+import "strings"
+
+// botMarkers — подстроки User-Agent роботов (в нижнем регистре). Основа — список
+// COUNTER Robots; сюда же HTTP-библиотеки и безголовые браузеры.
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "indexer", "fetcher",
+	"mediapartners", "facebookexternalhit", "embedly", "preview", "validator",
+	"monitor", "uptime", "pingdom", "check_http", "scan",
+	"curl", "wget", "python-requests", "python-urllib", "aiohttp", "httpx",
+	"go-http-client", "java/", "okhttp", "libwww", "lwp::", "php/", "ruby",
+	"headlesschrome", "phantomjs", "puppeteer", "playwright", "selenium",
+	"yandex.com/bots", "zgrab", "masscan", "nmap",
+}
+
+// IsBot — запрос от робота: пустой User-Agent или известная подстрока
+func IsBot(ua string) bool {
+	ua = strings.ToLower(strings.TrimSpace(ua))
+	if ua == "" {
+		return true
+	}
+	for _, m := range botMarkers {
+		if strings.Contains(ua, m) {
+			return true
+		}
+	}
+	return false
+}
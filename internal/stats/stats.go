@@ -0,0 +1,241 @@
+package stats
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/internal/db"
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Счётчики просмотров страниц сборников и скачиваний PDF. Учёт по правилам COUNTER:
+//   - запросы роботов не считаются (IsBot);
+//   - повтор того же события от того же клиента (IP + User-Agent) в пределах
+//     окна Window — «двойной клик», считается один раз;
+//   - уникальные события — не больше одного на клиента, сборник и день.
+// Счётчики копятся в памяти и раз в FlushInterval прибавляются к дневным
+// агрегатам в collection_stats_daily.
+//
+// Клиент различается по r.RemoteAddr, который middleware.RealIP берёт из
+// X-Forwarded-For / X-Real-IP. Приложение должно стоять за прокси, который эти
+// заголовки перезаписывает, иначе клиент подставит любой адрес и обойдёт окно
+// повторов и подсчёт уникальных.
+
+// Kind — вид события
+type Kind int
+
+const (
+	View     Kind = iota // просмотр /collections/{id}
+	Download             // скачивание PDF сборника
+)
+
+var (
+	// Window — окно отсечения повторов (COUNTER: 30 секунд)
+	Window = 30 * time.Second
+	// FlushInterval — как часто сбрасывать накопленное в БД
+	FlushInterval = time.Minute
+	// MaxSeen — предел записей в окне повторов и в уникальных за день (~20 МБ
+	// на каждое). Сверх него новые клиенты считаются без запоминания: поток
+	// запросов с разных адресов завышает счётчики, но не съедает память.
+	MaxSeen = 200_000
+)
+
+type clientKey [sha256.Size]byte
+
+type eventKey struct {
+	client clientKey
+	kind   Kind
+	id     int
+}
+
+type dayKey struct {
+	day time.Time
+	id  int
+}
+
+// Counts — счётчики одного сборника за день
+type Counts struct {
+	Views, UniqueViews, Downloads, UniqueDownloads int
+}
+
+func (c *Counts) add(kind Kind, unique bool) {
+	switch kind {
+	case View:
+		c.Views++
+		if unique {
+			c.UniqueViews++
+		}
+	case Download:
+		c.Downloads++
+		if unique {
+			c.UniqueDownloads++
+		}
+	}
+}
+
+// Recorder — накопитель событий между сбросами в БД
+type Recorder struct {
+	mu      sync.Mutex
+	last    map[eventKey]time.Time // для окна повторов
+	pruned  time.Time              // когда last последний раз чистили от устаревших
+	seen    map[eventKey]bool      // уникальные за текущий день
+	seenDay time.Time
+	seenCap bool // MaxSeen достигнут сегодня (предупреждение — раз в день)
+	pending map[dayKey]*Counts
+	now     func() time.Time
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		last:    map[eventKey]time.Time{},
+		seen:    map[eventKey]bool{},
+		pending: map[dayKey]*Counts{},
+		now:     time.Now,
+	}
+}
+
+// Default — общий накопитель приложения
+var Default = NewRecorder()
+
+// Init настраивает учёт: окно отсечения повторов
+func Init(c config.Stats) {
+	Window = c.DedupWindow
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Record учитывает событие; возвращает false, если оно отброшено (робот или повтор)
+func (rc *Recorder) Record(kind Kind, collectionID int, ip, userAgent string) bool {
+	if IsBot(userAgent) {
+		return false
+	}
+	now := rc.now()
+	k := eventKey{client: sha256.Sum256([]byte(ip + "\x00" + userAgent)), kind: kind, id: collectionID}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	t, ok := rc.last[k]
+	if ok && now.Sub(t) < Window {
+		return false
+	}
+	if !ok && len(rc.last) >= MaxSeen && now.Sub(rc.pruned) >= Window {
+		rc.pruneLast(now)
+	}
+	if ok || len(rc.last) < MaxSeen {
+		rc.last[k] = now
+	}
+
+	today := day(now)
+	if !today.Equal(rc.seenDay) {
+		rc.seen = map[eventKey]bool{}
+		rc.seenDay = today
+		rc.seenCap = false
+	}
+	unique := !rc.seen[k]
+	switch {
+	case !unique:
+	case len(rc.seen) < MaxSeen:
+		rc.seen[k] = true
+	case !rc.seenCap:
+		rc.seenCap = true
+		slog.Warn("stats: too many distinct clients today, unique counts are approximate", "limit", MaxSeen)
+	}
+
+	dk := dayKey{day: today, id: collectionID}
+	c := rc.pending[dk]
+	if c == nil {
+		c = &Counts{}
+		rc.pending[dk] = c
+	}
+	c.add(kind, unique)
+	return true
+}
+
+// take забирает накопленное и чистит устаревшие записи окна повторов
+func (rc *Recorder) take() map[dayKey]*Counts {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	out := rc.pending
+	rc.pending = map[dayKey]*Counts{}
+	rc.pruneLast(rc.now())
+	return out
+}
+
+// pruneLast удаляет из окна повторов записи старше Window; вызывается под mu
+func (rc *Recorder) pruneLast(now time.Time) {
+	cutoff := now.Add(-Window)
+	for k, t := range rc.last {
+		if t.Before(cutoff) {
+			delete(rc.last, k)
+		}
+	}
+	rc.pruned = now
+}
+
+// putBack возвращает несохранённые счётчики (БД недоступна — попробуем в следующий раз)
+func (rc *Recorder) putBack(m map[dayKey]*Counts) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for k, c := range m {
+		p := rc.pending[k]
+		if p == nil {
+			rc.pending[k] = c
+			continue
+		}
+		p.Views += c.Views
+		p.UniqueViews += c.UniqueViews
+		p.Downloads += c.Downloads
+		p.UniqueDownloads += c.UniqueDownloads
+	}
+}
+
+// Flush прибавляет накопленное к дневным агрегатам
+func (rc *Recorder) Flush(ctx context.Context) error {
+	batch := rc.take()
+	for k, c := range batch {
+		_, err := db.DB.ExecContext(ctx, `
+			INSERT INTO collection_stats_daily (day, collection_id, views, unique_views, downloads, unique_downloads)
+			SELECT $1, $2, $3, $4, $5, $6
+			WHERE EXISTS (SELECT 1 FROM collections WHERE id = $2)
+			ON CONFLICT (day, collection_id) DO UPDATE SET
+				views            = collection_stats_daily.views + EXCLUDED.views,
+				unique_views     = collection_stats_daily.unique_views + EXCLUDED.unique_views,
+				downloads        = collection_stats_daily.downloads + EXCLUDED.downloads,
+				unique_downloads = collection_stats_daily.unique_downloads + EXCLUDED.unique_downloads`,
+			k.day, k.id, c.Views, c.UniqueViews, c.Downloads, c.UniqueDownloads)
+		if err != nil {
+			rc.putBack(batch)
+			return err
+		}
+		delete(batch, k)
+	}
+	return nil
+}
+
+// Run сбрасывает счётчики раз в FlushInterval до отмены ctx; при остановке —
+// последний сброс, чтобы не потерять накопленное
+func (rc *Recorder) Run(ctx context.Context) {
+	t := time.NewTicker(FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := rc.Flush(fctx); err != nil {
+				slog.Error("stats: final flush", "err", err)
+			}
+			cancel()
+			return
+		case <-t.C:
+			if err := rc.Flush(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("stats: flush", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package stats
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+)
+
+const browser = "Mozilla/5.0 (X11; Linux x86_64) Firefox/130.0"
+
+func newTestRecorder(now *time.Time) *Recorder {
+	rc := NewRecorder()
+	rc.now = func() time.Time { return *now }
+	return rc
+}
+
+func TestRecordDedupWindowAndUniques(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	rc := newTestRecorder(&now)
+
+	if !rc.Record(View, 1, "10.0.0.1", browser) {
+		t.Fatal("first view dropped")
+	}
+	now = now.Add(Window - time.Second)
+	if rc.Record(View, 1, "10.0.0.1", browser) {
+		t.Error("repeat inside the window counted")
+	}
+	now = now.Add(2 * time.Second)
+	rc.Record(View, 1, "10.0.0.1", browser)
+	rc.Record(View, 1, "10.0.0.2", browser)
+	if rc.Record(View, 1, "10.0.0.3", "curl/8.0") {
+		t.Error("bot counted")
+	}
+
+	got := *rc.take()[dayKey{day: day(now), id: 1}]
+	if want := (Counts{Views: 3, UniqueViews: 2}); got != want {
+		t.Errorf("counts = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordSeenIsCapped(t *testing.T) {
+	old := MaxSeen
+	MaxSeen = 3
+	t.Cleanup(func() { MaxSeen = old })
+
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	rc := newTestRecorder(&now)
+	for i := range 10 {
+		rc.Record(Download, 1, "10.0.0."+string(rune('0'+i)), browser)
+	}
+	if len(rc.seen) != MaxSeen {
+		t.Fatalf("len(seen) = %d, want %d", len(rc.seen), MaxSeen)
+	}
+	got := *rc.take()[dayKey{day: day(now), id: 1}]
+	if want := (Counts{Downloads: 10, UniqueDownloads: 10}); got != want {
+		t.Errorf("counts = %+v, want %+v", got, want)
+	}
+
+	// новый день — новый учёт
+	now = now.Add(24 * time.Hour)
+	rc.Record(Download, 1, "10.0.0.0", browser)
+	if len(rc.seen) != 1 || rc.seenCap {
+		t.Errorf("after midnight: len(seen) = %d, seenCap = %v", len(rc.seen), rc.seenCap)
+	}
+}
+
+func TestRecordLastIsCapped(t *testing.T) {
+	old := MaxSeen
+	MaxSeen = 3
+	t.Cleanup(func() { MaxSeen = old })
+
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	rc := newTestRecorder(&now)
+	for i := range 10 {
+		rc.Record(View, 1, "10.0.0."+string(rune('0'+i)), browser)
+	}
+	if len(rc.last) != MaxSeen {
+		t.Fatalf("len(last) = %d, want %d", len(rc.last), MaxSeen)
+	}
+
+	// после окна старые записи вытесняются без ожидания сброса
+	now = now.Add(Window + time.Second)
+	rc.Record(View, 1, "10.0.1.1", browser)
+	if _, ok := rc.last[eventKey{client: clientOf("10.0.1.1"), kind: View, id: 1}]; !ok || len(rc.last) != 1 {
+		t.Errorf("after window: len(last) = %d, new client stored = %v", len(rc.last), ok)
+	}
+}
+
+// День считается по UTC, какой бы пояс ни был у часов
+func TestRecordDayIsUTC(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*3600)
+	now := time.Date(2025, 3, 2, 1, 0, 0, 0, msk) // 1 марта 22:00 UTC
+	rc := newTestRecorder(&now)
+	rc.Record(View, 1, "10.0.0.1", browser)
+
+	want := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, ok := rc.take()[dayKey{day: want, id: 1}]; !ok {
+		t.Errorf("event not counted on %s", want.Format("2006-01-02"))
+	}
+}
+
+func clientOf(ip string) clientKey {
+	return sha256.Sum256([]byte(ip + "\x00" + browser))
+}
@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"BookCollect/internal/config"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Трассировка OpenTelemetry: спан на каждый запрос (по шаблону маршрута chi),
+// дочерние — на SQL (db), рендер шаблона и операции с файлами (handlers).
+// Экспорт — TRACING_EXPORTER:
+//   - none   — выключено (по умолчанию): спаны не записываются, но trace_id
+//     из входящего traceparent всё равно попадает в журнал;
+//   - stdout — JSON в stdout, для локальной отладки;
+//   - otlp   — OTLP/HTTP на OTEL_EXPORTER_OTLP_ENDPOINT (Jaeger, Tempo, collector).
+
+// tracer — глобальный: работает и до Init (делегирует провайдеру, заданному позже)
+var tracer = otel.Tracer("BookCollect")
+
+// propagator — W3C traceparent / baggage
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Init настраивает экспорт. Возвращённую функцию main вызывает при остановке:
+// она отправляет накопленные спаны.
+func Init(ctx context.Context, c config.Tracing) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagator)
+
+	var exp sdktrace.SpanExporter
+	switch c.Exporter {
+	case "stdout":
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if c.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(c.Endpoint))
+		}
+		exp, err = otlptracehttp.New(ctx, opts...)
+	default:
+		slog.Info("tracing: disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := Setup(c, exp)
+	slog.Info("tracing: enabled", "exporter", c.Exporter, "sample_ratio", c.SampleRatio)
+	return tp.Shutdown, nil
+}
+
+// Setup делает провайдер с экспортёром exp глобальным. Отдельно от Init, чтобы
+// в тестах подставить tracetest.NewInMemoryExporter() и после tp.ForceFlush
+// проверить полученные спаны.
+func Setup(c config.Tracing, exp sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(c.ServiceName),
+		)),
+		// решение о выборке принимает корень: входящий traceparent его переносит
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp
+}
+
+// Start открывает дочерний спан текущего запроса
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End закрывает спан; ошибка записывается в него и помечает спан как неуспешный
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware — серверный спан на запрос. Имя "GET /collections/{id}" известно
+// только после роутинга, поэтому задаётся уже после next (как в metrics).
+// Ставится до logging.Middleware: тот берёт trace_id из контекста.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.ClientAddress(r.RemoteAddr),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rc := chi.RouteContext(r.Context()); rc != nil {
+			if p := rc.RoutePattern(); p != "" {
+				span.SetName(r.Method + " " + p)
+				span.SetAttributes(semconv.HTTPRoute(p))
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package views
+
+import (
+	"BookCollect/internal/config"
+	"BookCollect/web"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// Шаблоны страниц и статика сайта. Шаблоны разбираются один раз в Init:
+// страница — base.html плюс её файл, имя страницы — путь от web/templates
+// ("admin/login.html"). Файлы берутся из встроенного web.FS; если задан
+// web.dir (WEB_DIR) — с диска, и тогда шаблоны и статика перечитываются на
+// каждый запрос (разработка: правки видны без перезапуска).
+//
+// Ссылки на статику в шаблонах — {{ asset "styles.css" }}: в имя файла
+// вставляется отпечаток содержимого (/static/styles.3f2a9c1b0d.css). Такой URL
+// браузер кэширует навсегда, а после изменения файла меняется сам URL.
+
+const (
+	baseTemplate   = "base.html"
+	cacheImmutable = "public, max-age=31536000, immutable"
+)
+
+// set — разобранные шаблоны и отпечатки статики из одного источника
+type set struct {
+	static fs.FS
+	pages  map[string]*template.Template // "admin/login.html" → base + страница
+	assets map[string]string             // "styles.css" → "styles.3f2a9c1b0d.css"
+	files  map[string]string             // "styles.3f2a9c1b0d.css" → "styles.css"
+	etags  map[string]string             // "styles.css" → "\"3f2a9c1b0d\""
+}
+
+var (
+	root    fs.FS = web.FS
+	reload  bool
+	current *set
+)
+
+// Init разбирает шаблоны и считает отпечатки статики; ошибка в шаблоне —
+// ошибка старта, а не первого запроса
+func Init(cfg config.Web) error {
+	if cfg.Dir != "" {
+		root = os.DirFS(cfg.Dir)
+		reload = true
+	}
+	s, err := load(root)
+	if err != nil {
+		return err
+	}
+	current = s
+	return nil
+}
+
+// Reload — шаблоны читаются с диска на каждый запрос
+func Reload() bool { return reload }
+
+func get() (*set, error) {
+	if reload {
+		return load(root)
+	}
+	return current, nil
+}
+
+// Page — шаблон страницы; выполнять — ExecuteTemplate(w, "base", data)
+func Page(name string) (*template.Template, error) {
+	s, err := get()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := s.pages[name]
+	if !ok {
+		return nil, fmt.Errorf("views: no page %q", name)
+	}
+	return t, nil
+}
+
+// Static — содержимое /static/ (подключается через http.StripPrefix). Адреса
+// с отпечатком неизменяемы и кэшируются на год; обычные имена тоже отдаются —
+// для старых ссылок и файлов, на которые шаблоны не ссылаются.
+func Static() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := get()
+		if err != nil {
+			http.Error(w, "static: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if orig, ok := s.files[name]; ok {
+			w.Header().Set("Cache-Control", cacheImmutable)
+			name = orig
+		} else if reload {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		// у встроенных файлов нет времени изменения — сверяемся по ETag
+		if tag, ok := s.etags[name]; ok {
+			w.Header().Set("ETag", tag)
+		}
+		http.ServeFileFS(w, r, s.static, name)
+	})
+}
+
+func load(root fs.FS) (*set, error) {
+	static, err := fs.Sub(root, "static")
+	if err != nil {
+		return nil, err
+	}
+	s := &set{
+		static: static,
+		pages:  map[string]*template.Template{},
+		assets: map[string]string{},
+		files:  map[string]string{},
+		etags:  map[string]string{},
+	}
+
+	err = fs.WalkDir(static, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(static, p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:5])
+		ext := path.Ext(p)
+		fp := strings.TrimSuffix(p, ext) + "." + hash + ext
+		s.assets[p], s.files[fp], s.etags[p] = fp, p, `"`+hash+`"`
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("views: static: %w", err)
+	}
+
+	tmpls, err := fs.Sub(root, "templates")
+	if err != nil {
+		return nil, err
+	}
+	base, err := template.New(baseTemplate).Funcs(template.FuncMap{"asset": s.asset}).ParseFS(tmpls, baseTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("views: %w", err)
+	}
+	err = fs.WalkDir(tmpls, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || p == baseTemplate || path.Ext(p) != ".html" {
+			return err
+		}
+		t, err := template.Must(base.Clone()).ParseFS(tmpls, p)
+		if err != nil {
+			return fmt.Errorf("views: %w", err)
+		}
+		s.pages[p] = t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// asset — функция шаблонов: URL файла из web/static с отпечатком
+func (s *set) asset(name string) (string, error) {
+	fp, ok := s.assets[strings.TrimPrefix(name, "/")]
+	if !ok {
+		return "", fmt.Errorf("asset %q not found in static", name)
+	}
+	return "/static/" + fp, nil
+}
@@ -1,9 +1,17 @@
 function qs(s, r=document){ return r.querySelector(s); }
+// problemText — текст ошибки из application/problem+json: detail и ошибки полей
+function problemText(body, fallback) {
+    if (!body || typeof body !== 'object') return (typeof body === 'string' && body) ? body : fallback;
+    if (Array.isArray(body.errors) && body.errors.length > 1) {
+        return body.errors.map(e => `${e.field}: ${e.detail}`).join('\n');
+    }
+    return body.detail || fallback;
+}
 async function jsonFetch(url, opts={}) {
     const res = await fetch(url, {credentials:'same-origin', ...opts});
     const ct  = res.headers.get('content-type') || '';
-    const body = ct.includes('application/json') ? await res.json() : await res.text();
-    if (!res.ok) throw new Error((body && body.error) ? body.error : (typeof body==='string'? body : 'Ошибка запроса'));
+    const body = ct.includes('json') ? await res.json() : await res.text();
+    if (!res.ok) throw new Error(problemText(body, 'Ошибка запроса'));
     return body;
 }
 
@@ -30,15 +38,27 @@ window.initAdminCollections = function(){
     </tr>`;
     }
 
+    // при редактировании поля уходят в PUT JSON, а выбранные файлы —
+    // отдельными PUT .../cover и .../pdf (multipart)
+    async function replaceFiles(id){
+        for (const [name, url] of [['cover', window.ADMIN_CFG.updateCover(id)], ['pdf', window.ADMIN_CFG.updatePdf(id)]]) {
+            const input = qs(`[name=${name}]`, frm);
+            if (!input || !input.files.length) continue;
+            const fd = new FormData();
+            fd.append(name, input.files[0]);
+            await jsonFetch(url, { method:'PUT', body: fd });
+        }
+    }
+
     async function load(){
         T.innerHTML = '';
-        const list = await jsonFetch(window.ADMIN_CFG.listCollections);
-        const items = list.collections || list || [];
+        const items = await jsonFetch(window.ADMIN_CFG.listCollections);
         items.forEach(c => T.insertAdjacentHTML('beforeend', row(c)));
     }
 
     btnNew.addEventListener('click', ()=>{
-        frm.reset(); qs('[name=id]').value = ''; alert.textContent = ''; dlg.showModal();
+        frm.reset(); qs('[name=id]').value = ''; alert.textContent = '';
+        dlg.showModal();
     });
     qs('#btnClose').addEventListener('click', ()=> dlg.close());
 
@@ -48,10 +68,7 @@ window.initAdminCollections = function(){
         if (!id) return;
 
         if (t.dataset.edit){
-            const list = await jsonFetch(window.ADMIN_CFG.listCollections);
-            const items = list.collections || list || [];
-            const item = items.find(x => String(x.id) === String(id));
-            if (!item) return;
+            const item = await jsonFetch(window.ADMIN_CFG.getCollection(id));
 
             frm.reset();
             qs('[name=id]').value = item.id || '';
@@ -75,15 +92,25 @@ window.initAdminCollections = function(){
         e.preventDefault();
         alert.textContent = '';
         const id = qs('[name=id]').value.trim();
-        const fd = new FormData(frm);
 
         try {
             if (!id){
-                await fetch(window.ADMIN_CFG.createCollection, { method:'POST', body: fd }).then(r=>{ if(!r.ok) throw new Error('Ошибка сохранения'); });
+                await jsonFetch(window.ADMIN_CFG.createCollection, { method:'POST', body: new FormData(frm) });
             } else {
-                // PUT + multipart нестабилен, поэтому используем POST + _method=PUT
-                fd.append("_method", "PUT");
-                await fetch(window.ADMIN_CFG.updateCollection(id), { method:'POST', body: fd }).then(r=>{ if(!r.ok) throw new Error('Ошибка сохранения'); });
+                // тело — схема CollectionUpdate из /api/openapi.json
+                const num = v => v.trim() === '' ? null : Number(v);
+                await jsonFetch(window.ADMIN_CFG.updateCollection(id), {
+                    method: 'PUT',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({
+                        title:            qs('[name=title]').value.trim(),
+                        release_year:     num(qs('[name=release_year]').value),
+                        release_number:   num(qs('[name=release_number]').value),
+                        description:      qs('[name=description]').value,
+                        publication_link: qs('[name=publication_link]').value.trim(),
+                    }),
+                });
+                await replaceFiles(id);
             }
             dlg.close();
             await load();
@@ -96,9 +123,41 @@ window.initAdminCollections = function(){
 };
 
 /* ====== ЗАЯВКИ ====== */
+const ARTICLE_STATUSES = {
+    submitted:          'Получена',
+    in_review:          'На рецензировании',
+    revision_requested: 'Требуется доработка',
+    accepted:           'Принята',
+    rejected:           'Отклонена',
+};
+
+const REVIEW_RECOMMENDATIONS = {
+    accept:         'Принять',
+    minor_revision: 'Небольшая доработка',
+    major_revision: 'Существенная доработка',
+    reject:         'Отклонить',
+};
+const REVIEW_STATUSES = {
+    assigned:  'ожидает заключения',
+    submitted: 'заключение получено',
+    declined:  'отказался',
+};
+
 window.initAdminArticles = function(){
     const T = qs('#tbl tbody');
     function escapeHtml(s){ return (s||'').replace(/[&<>"']/g, m=>({ '&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;' }[m])); }
+    function statusSelect(a){
+        const opts = Object.entries(ARTICLE_STATUSES)
+            .map(([v, l]) => `<option value="${v}" ${v === a.status ? 'selected' : ''}>${l}</option>`).join('');
+        return `<select data-status="${a.id}">${opts}</select>`;
+    }
+    // сборник, в который вошла статья (для страниц авторов и выпуска)
+    let collections = [];
+    function collectionSelect(a){
+        const opts = collections
+            .map(c => `<option value="${c.id}" ${c.id === a.collection_id ? 'selected' : ''}>${escapeHtml(c.title)}${c.release_year ? ' · ' + c.release_year : ''}</option>`).join('');
+        return `<select data-collection="${a.id}"><option value="">—</option>${opts}</select>`;
+    }
     function row(a){
         const created = a.created_at ? new Date(a.created_at).toLocaleString() : '';
         return `<tr>
@@ -107,24 +166,421 @@ window.initAdminArticles = function(){
       <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(a.title)}</td>
       <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(a.email)}</td>
       <td style="padding:8px; border-top:1px solid var(--border)">${created}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${statusSelect(a)}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${collectionSelect(a)}</td>
       <td style="padding:8px; border-top:1px solid var(--border)">
         <a class="btn btn-ghost" href="${window.ADMIN_CFG.downloadFile(a.id)}">Скачать</a>
+        <button class="btn btn-ghost" data-versions="${a.id}">Версии</button>
+        <button class="btn btn-ghost" data-reviews="${a.id}">Рецензии</button>
+        <a class="btn btn-ghost" href="${window.ADMIN_CFG.exportMeta(a.id, 'ris')}" title="Метаданные для библиографии">RIS</a>
+        <a class="btn btn-ghost" href="${window.ADMIN_CFG.exportMeta(a.id, 'bibtex')}">BibTeX</a>
         <button class="btn btn-ghost" data-del="${a.id}">Удалить</button>
       </td>
     </tr>`;
     }
     async function load(){
         T.innerHTML = '';
-        const list = await jsonFetch(window.ADMIN_CFG.listArticles);
-        const items = list.articles || list || [];
+        const cl = await jsonFetch(window.ADMIN_CFG.listCollections);
+        collections = cl;
+        const items = await jsonFetch(window.ADMIN_CFG.listArticles);
         items.forEach(a => T.insertAdjacentHTML('beforeend', row(a)));
     }
+    // история версий: строка под заявкой со списком и формой загрузки
+    async function toggleVersions(tr, id){
+        const open = T.querySelector(`tr[data-versions-of="${id}"]`);
+        if (open) { open.remove(); return; }
+
+        const list = await jsonFetch(window.ADMIN_CFG.versions(id));
+        const items = list.map(v => `<li>
+            <a href="${window.ADMIN_CFG.downloadFile(id)}?version=${v.version}">Версия ${v.version}</a>
+            — ${escapeHtml(v.original_name)} · ${v.uploaded_by === 'editor' ? 'редактор' : 'автор'}
+            · ${new Date(v.created_at).toLocaleString()}${v.note ? ' · ' + escapeHtml(v.note) : ''}
+        </li>`).join('');
+        tr.insertAdjacentHTML('afterend', `<tr data-versions-of="${id}">
+      <td colspan="8" style="padding:8px 8px 14px; border-top:1px dashed var(--border)">
+        <ul style="margin:0 0 10px; padding-left:18px">${items || '<li class="muted">Версий нет</li>'}</ul>
+        <form data-upload-version="${id}" style="display:flex; gap:8px; align-items:center; flex-wrap:wrap">
+          <input name="file" type="file" accept=".pdf,.docx,.odt" required>
+          <input name="note" placeholder="Комментарий к версии" style="height:34px; padding:0 8px; border:1px solid var(--border); border-radius:8px">
+          <button class="btn btn-primary" type="submit">Загрузить версию</button>
+        </form>
+      </td>
+    </tr>`);
+    }
+
+    function ownerRow(id){
+        return T.querySelector(`[data-versions="${id}"]`).closest('tr');
+    }
+
+    // рецензии: назначения, заключения и форма назначения рецензента
+    async function toggleReviews(tr, id){
+        const open = T.querySelector(`tr[data-reviews-of="${id}"]`);
+        if (open) { open.remove(); return; }
+
+        const [sum, reviewers] = await Promise.all([
+            jsonFetch(window.ADMIN_CFG.reviews(id)),
+            jsonFetch(window.ADMIN_CFG.reviewers),
+        ]);
+        const recs = Object.entries(sum.recommendations || {})
+            .map(([k, n]) => `${REVIEW_RECOMMENDATIONS[k] || k}: ${n}`).join(', ');
+        const items = sum.assignments.map(a => {
+            const due = a.due_date ? new Date(a.due_date).toLocaleDateString() : '—';
+            const rep = a.report ? `<div style="margin:4px 0 0 12px">
+                <b>${escapeHtml(REVIEW_RECOMMENDATIONS[a.report.recommendation] || a.report.recommendation)}</b>
+                <div style="white-space:pre-wrap">${escapeHtml(a.report.comments_for_author)}</div>
+                ${a.report.confidential_comments ? `<div class="muted" style="white-space:pre-wrap">Конфиденциально: ${escapeHtml(a.report.confidential_comments)}</div>` : ''}
+            </div>` : '';
+            return `<li>${escapeHtml(a.reviewer_name)} · срок ${due} · ${REVIEW_STATUSES[a.status] || a.status}
+                <button class="btn btn-ghost" data-unassign="${a.id}" data-article="${id}">Снять</button>${rep}</li>`;
+        }).join('');
+        const opts = reviewers.filter(r => r.active)
+            .map(r => `<option value="${r.id}">${escapeHtml(r.name)}</option>`).join('');
+        tr.insertAdjacentHTML('afterend', `<tr data-reviews-of="${id}">
+      <td colspan="8" style="padding:8px 8px 14px; border-top:1px dashed var(--border)">
+        <div class="muted" style="margin-bottom:6px">Назначено: ${sum.assigned} · заключений: ${sum.submitted} · просрочено: ${sum.overdue}${recs ? ' · ' + escapeHtml(recs) : ''}</div>
+        <ul style="margin:0 0 10px; padding-left:18px">${items || '<li class="muted">Рецензенты не назначены</li>'}</ul>
+        <form data-assign-reviewer="${id}" style="display:flex; gap:8px; align-items:center; flex-wrap:wrap">
+          <select name="reviewer_id" required>${opts}</select>
+          <input name="due_date" type="date">
+          <button class="btn btn-primary" type="submit">Назначить</button>
+        </form>
+      </td>
+    </tr>`);
+    }
+    async function reloadReviews(id){
+        T.querySelector(`tr[data-reviews-of="${id}"]`)?.remove();
+        await toggleReviews(ownerRow(id), id);
+    }
+
+    T.addEventListener('submit', async (e)=>{
+        const articleID = e.target.dataset.assignReviewer;
+        if (!articleID) return;
+        e.preventDefault();
+        const fd = new FormData(e.target);
+        try {
+            await jsonFetch(window.ADMIN_CFG.reviews(articleID), {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({ reviewer_id: Number(fd.get('reviewer_id')), due_date: fd.get('due_date') || '' }),
+            });
+            await reloadReviews(articleID);
+        } catch(err){
+            alert(err.message || 'Ошибка');
+        }
+    });
+
+    T.addEventListener('submit', async (e)=>{
+        const id = e.target.dataset.uploadVersion;
+        if (!id) return;
+        e.preventDefault();
+        try {
+            await jsonFetch(window.ADMIN_CFG.versions(id), { method:'POST', body: new FormData(e.target) });
+            e.target.closest('tr').remove();
+            await toggleVersions(ownerRow(id), id);
+        } catch(err){
+            alert(err.message || 'Ошибка');
+        }
+    });
+
     T.addEventListener('click', async (e)=>{
+        if (e.target.dataset.versions) {
+            await toggleVersions(e.target.closest('tr'), e.target.dataset.versions);
+            return;
+        }
+        if (e.target.dataset.reviews) {
+            await toggleReviews(e.target.closest('tr'), e.target.dataset.reviews);
+            return;
+        }
+        if (e.target.dataset.unassign) {
+            if (!confirm('Снять рецензента? Его заключение будет удалено.')) return;
+            const articleID = e.target.dataset.article;
+            await jsonFetch(`${window.ADMIN_CFG.reviews(articleID)}/${e.target.dataset.unassign}`, { method:'DELETE' });
+            await reloadReviews(articleID);
+            return;
+        }
         const id = e.target.dataset.del;
         if (!id) return;
         if (!confirm('Удалить заявку?')) return;
         await fetch(window.ADMIN_CFG.deleteArticle(id), { method:'DELETE' });
         await load();
     });
+    // смена статуса: автор получит письмо, комментарий — необязательный
+    T.addEventListener('change', async (e)=>{
+        const id = e.target.dataset.collection;
+        if (!id) return;
+        try {
+            await jsonFetch(window.ADMIN_CFG.setCollection(id), {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({ collection_id: e.target.value ? Number(e.target.value) : null }),
+            });
+        } catch(err){
+            alert(err.message || 'Ошибка');
+            await load();
+        }
+    });
+    T.addEventListener('change', async (e)=>{
+        const id = e.target.dataset.status;
+        if (!id) return;
+        const message = prompt('Комментарий для автора (необязательно):', '');
+        if (message === null) { await load(); return; }
+        try {
+            await jsonFetch(window.ADMIN_CFG.setStatus(id), {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({ status: e.target.value, message }),
+            });
+        } catch(err){
+            alert(err.message || 'Ошибка');
+            await load();
+        }
+    });
+    load().catch(console.error);
+};
+
+/* ====== РЕЦЕНЗЕНТЫ ====== */
+window.initAdminReviewers = function(){
+    const T = qs('#tbl tbody');
+    const frm = qs('#frm');
+    const box = qs('#alert');
+    function escapeHtml(s){ return (s||'').replace(/[&<>"']/g, m=>({ '&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;' }[m])); }
+    function row(r){
+        return `<tr>
+      <td style="padding:8px; border-top:1px solid var(--border)">${r.id}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(r.name)}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(r.email)}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(r.login)}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${r.active ? 'активен' : 'отключён'}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">
+        <button class="btn btn-ghost" data-toggle="${r.id}" data-active="${r.active ? 1 : 0}">${r.active ? 'Отключить' : 'Включить'}</button>
+      </td>
+    </tr>`;
+    }
+    async function load(){
+        const list = await jsonFetch(window.ADMIN_CFG.reviewers);
+        T.innerHTML = list.map(row).join('');
+    }
+    T.addEventListener('click', async (e)=>{
+        const btn = e.target.closest('[data-toggle]');
+        if (!btn) return;
+        box.textContent = '';
+        const active = btn.dataset.active !== '1';
+        if (!active && !confirm('Отключить рецензента? Он сразу потеряет доступ к рукописям.')) return;
+        try {
+            await jsonFetch(window.ADMIN_CFG.reviewer(btn.dataset.toggle), {
+                method: 'PATCH',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({active}),
+            });
+            await load();
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    });
+    frm.addEventListener('submit', async (e)=>{
+        e.preventDefault();
+        box.textContent = '';
+        try {
+            await jsonFetch(window.ADMIN_CFG.reviewers, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(Object.fromEntries(new FormData(frm))),
+            });
+            frm.reset();
+            await load();
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    });
+    load().catch(console.error);
+};
+
+/* ====== РЕЕСТР АВТОРОВ ====== */
+window.initAdminAuthors = function(){
+    const T = qs('#tbl tbody');
+    const box = qs('#alert');
+    const onlyDup = qs('#onlyDup');
+    let items = [];
+    function escapeHtml(s){ return (s||'').replace(/[&<>"']/g, m=>({ '&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;' }[m])); }
+    // возможные дубликаты — одинаковая фамилия в ключе (первое слово)
+    function surnameKey(a){ return a.name_key.split(' ')[0]; }
+    function row(a){
+        return `<tr>
+      <td style="padding:8px; border-top:1px solid var(--border)"><input type="checkbox" data-src="${a.id}"></td>
+      <td style="padding:8px; border-top:1px solid var(--border)"><input type="radio" name="target" value="${a.id}"></td>
+      <td style="padding:8px; border-top:1px solid var(--border)"><a href="/authors/${a.id}" target="_blank">${a.id}</a></td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(a.surname)} ${escapeHtml(a.given_names)}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${escapeHtml(a.orcid || '')}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)" class="muted">${escapeHtml(a.name_key)}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${a.articles}</td>
+    </tr>`;
+    }
+    function draw(){
+        let list = items;
+        if (onlyDup.checked) {
+            const seen = {};
+            items.forEach(a => seen[surnameKey(a)] = (seen[surnameKey(a)] || 0) + 1);
+            list = items.filter(a => seen[surnameKey(a)] > 1);
+        }
+        T.innerHTML = list.map(row).join('');
+    }
+    async function load(){
+        items = await jsonFetch(window.ADMIN_CFG.authors);
+        draw();
+    }
+    onlyDup.addEventListener('change', draw);
+
+    qs('#btnMerge').addEventListener('click', async ()=>{
+        box.textContent = '';
+        const target = qs('input[name=target]:checked');
+        const sources = [...T.querySelectorAll('[data-src]:checked')].map(x => Number(x.dataset.src));
+        if (!target || !sources.length) { box.textContent = 'Отметьте дубликаты и основную запись'; return; }
+        if (!confirm(`Объединить ${sources.length} запис(и) с #${target.value}?`)) return;
+        try {
+            const res = await jsonFetch(window.ADMIN_CFG.merge, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({ target_id: Number(target.value), source_ids: sources }),
+            });
+            box.textContent = `Объединено: ${res.merged}`;
+            await load();
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    });
+    qs('#btnRelink').addEventListener('click', async ()=>{
+        try {
+            const res = await jsonFetch(window.ADMIN_CFG.relink, { method: 'POST' });
+            box.textContent = `Связано строк: ${res.linked}`;
+            await load();
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    });
+    load().catch(console.error);
+};
+
+/* ====== СТАТИСТИКА ====== */
+window.initAdminStats = function(){
+    const T = qs('#tbl tbody');
+    const box = qs('#alert');
+    const from = qs('#from'), to = qs('#to');
+    function escapeHtml(s){ return (s||'').replace(/[&<>"']/g, m=>({ '&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;' }[m])); }
+    function isoDay(d){ return d.toISOString().slice(0, 10); }
+
+    // Столбчатая диаграмма: всего (светлее) и уникальные поверх
+    function chart(el, days, total, unique){
+        const W = 720, H = 160, pad = 24;
+        const max = Math.max(1, ...days.map(d => d[total]));
+        const bw = (W - pad) / Math.max(1, days.length);
+        const y = v => H - pad - (H - 2*pad) * v / max;
+        const bars = days.map((d, i) => {
+            const x = pad + i*bw;
+            return `<g><title>${d.day}: ${d[total]} (уник. ${d[unique]})</title>
+        <rect x="${x+1}" y="${y(d[total])}" width="${Math.max(1, bw-2)}" height="${H-pad-y(d[total])}" fill="var(--primary, #4a6cf7)" opacity="0.35"></rect>
+        <rect x="${x+1}" y="${y(d[unique])}" width="${Math.max(1, bw-2)}" height="${H-pad-y(d[unique])}" fill="var(--primary, #4a6cf7)"></rect></g>`;
+        }).join('');
+        const first = days.length ? days[0].day : '', last = days.length ? days[days.length-1].day : '';
+        el.innerHTML = `<svg viewBox="0 0 ${W} ${H}" style="width:100%; height:auto; border:1px solid var(--border); border-radius:8px">
+      <text x="2" y="${pad-8}" font-size="11" fill="currentColor">${max}</text>
+      <line x1="${pad}" y1="${H-pad}" x2="${W}" y2="${H-pad}" stroke="var(--border)"></line>
+      ${bars}
+      <text x="${pad}" y="${H-6}" font-size="11" fill="currentColor">${first}</text>
+      <text x="${W-2}" y="${H-6}" font-size="11" fill="currentColor" text-anchor="end">${last}</text>
+    </svg>`;
+    }
+
+    function row(c){
+        return `<tr>
+      <td style="padding:8px; border-top:1px solid var(--border)"><a href="/collections/${c.collection_id}" target="_blank">${escapeHtml(c.title)}</a></td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${c.views}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${c.unique_views}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${c.downloads}</td>
+      <td style="padding:8px; border-top:1px solid var(--border)">${c.unique_downloads}</td>
+    </tr>`;
+    }
+
+    async function load(){
+        box.textContent = '';
+        try {
+            const p = new URLSearchParams({ from: from.value, to: to.value });
+            const data = await jsonFetch(`${window.ADMIN_CFG.stats}?${p}`);
+            chart(qs('#chartViews'), data.daily, 'views', 'unique_views');
+            chart(qs('#chartDownloads'), data.daily, 'downloads', 'unique_downloads');
+            T.innerHTML = data.collections.length
+                ? data.collections.map(row).join('')
+                : `<tr><td colspan="5" class="muted" style="padding:8px">За период нет данных</td></tr>`;
+            // отчёт COUNTER — по месяцам выбранного периода
+            const q = new URLSearchParams({ begin: from.value.slice(0, 7), end: to.value.slice(0, 7) });
+            qs('#btnCounter').href = `${window.ADMIN_CFG.counter}?${q}`;
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    }
+
+    const now = new Date();
+    to.value = isoDay(now);
+    from.value = isoDay(new Date(now.getTime() - 29*24*3600*1000));
+    qs('#btnLoad').addEventListener('click', load);
+    load();
+};
+
+/* ====== КЛЮЧИ API ====== */
+window.initAdminAPIKeys = function(){
+    const T = qs('#tbl tbody');
+    const frm = qs('#frm');
+    const box = qs('#alert');
+    const tokenBox = qs('#token');
+    function escapeHtml(s){ return (s||'').replace(/[&<>"']/g, m=>({ '&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;' }[m])); }
+    function when(t){ return t ? new Date(t).toLocaleString('ru-RU') : '—'; }
+    function row(k){
+        const cell = 'padding:8px; border-top:1px solid var(--border)';
+        return `<tr${k.revoked_at ? ' class="muted"' : ''}>
+      <td style="${cell}">${k.id}</td>
+      <td style="${cell}">${escapeHtml(k.name)}</td>
+      <td style="${cell}"><code>${escapeHtml(k.prefix)}…</code></td>
+      <td style="${cell}">${k.rate_limit}</td>
+      <td style="${cell}">${k.requests_today}</td>
+      <td style="${cell}">${k.requests_30d} (${k.limited_30d})</td>
+      <td style="${cell}">${when(k.last_used_at)}</td>
+      <td style="${cell}">${k.revoked_at ? 'отозван ' + when(k.revoked_at) : `<button class="btn btn-ghost" data-revoke="${k.id}">Отозвать</button>`}</td>
+    </tr>`;
+    }
+    async function load(){
+        const list = await jsonFetch(window.ADMIN_CFG.apiKeys);
+        T.innerHTML = list.map(row).join('');
+    }
+    frm.addEventListener('submit', async (e)=>{
+        e.preventDefault();
+        box.textContent = '';
+        tokenBox.hidden = true;
+        const limit = qs('[name=rate_limit]', frm).value.trim();
+        try {
+            const key = await jsonFetch(window.ADMIN_CFG.apiKeys, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    name: qs('[name=name]', frm).value,
+                    rate_limit: limit === '' ? 0 : Number(limit),
+                }),
+            });
+            qs('#tokenValue').textContent = key.token;
+            tokenBox.hidden = false;
+            frm.reset();
+            await load();
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    });
+    T.addEventListener('click', async (e)=>{
+        const id = e.target.dataset.revoke;
+        if (!id || !confirm('Отозвать ключ? Запросы с ним сразу перестанут проходить.')) return;
+        try {
+            await jsonFetch(window.ADMIN_CFG.revokeKey(id), { method: 'DELETE' });
+            await load();
+        } catch(err){
+            box.textContent = err.message || 'Ошибка';
+        }
+    });
     load().catch(console.error);
 };
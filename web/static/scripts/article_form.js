@@ -7,6 +7,43 @@ const progress = document.getElementById('progress');
 const percent = document.getElementById('percent');
 const alertBox = document.getElementById('alert');
 const submitBtn = document.getElementById('submitBtn');
+const authorsBox = document.getElementById('authors');
+const authorTpl = document.getElementById('authorTpl');
+const authorsJson = document.getElementById('authorsJson');
+
+// ====== соавторы: строки формы -> JSON в скрытом поле authors ======
+function addAuthorRow() {
+    authorsBox.appendChild(authorTpl.content.cloneNode(true));
+    const rows = authorsBox.querySelectorAll('.author-row');
+    if (rows.length === 1) rows[0].querySelector('[data-field=corresponding]').checked = true;
+}
+
+function collectAuthors() {
+    return [...authorsBox.querySelectorAll('.author-row')].map(row => {
+        const v = (f) => row.querySelector(`[data-field=${f}]`);
+        return {
+            surname:       v('surname').value.trim(),
+            given_names:   v('given_names').value.trim(),
+            affiliation:   v('affiliation').value.trim(),
+            orcid:         v('orcid').value.trim(),
+            corresponding: v('corresponding').checked,
+        };
+    });
+}
+
+document.getElementById('addAuthor').addEventListener('click', addAuthorRow);
+authorsBox.addEventListener('click', (e)=>{
+    const row = e.target.closest('.author-row');
+    if (!row) return;
+    if (e.target.dataset.remove !== undefined) {
+        if (authorsBox.querySelectorAll('.author-row').length > 1) row.remove();
+    } else if (e.target.dataset.move === '-1' && row.previousElementSibling) {
+        authorsBox.insertBefore(row, row.previousElementSibling);
+    } else if (e.target.dataset.move === '1' && row.nextElementSibling) {
+        authorsBox.insertBefore(row.nextElementSibling, row);
+    }
+});
+addAuthorRow();
 
 function showAlert(text, type) {
     alertBox.style.display = 'block';
@@ -45,7 +82,9 @@ form.addEventListener('submit', (e)=>{
 e.preventDefault();
 clearAlert();
 
+authorsJson.value = JSON.stringify(collectAuthors());
 const fd = new FormData(form);
+fd.delete('corresponding');
 const xhr = new XMLHttpRequest();
 xhr.open('POST', form.action, true);
 xhr.responseType = 'json';
@@ -83,11 +122,25 @@ const body = xhr.response || (function(){ try{ return JSON.parse(xhr.responseTex
 if (status >= 200 && status < 300) {
     const id = body && body.id ? ` ID заявки: ${body.id}.` : '';
     showAlert('Заявка отправлена.' + id, 'success');
+    if (body && body.tracking_url) {
+        // ссылка на страницу отслеживания (дублируется в письме)
+        const a = document.createElement('a');
+        a.href = body.tracking_url;
+        a.textContent = 'Отслеживать статус заявки';
+        a.style.display = 'block';
+        a.style.marginTop = '6px';
+        alertBox.appendChild(a);
+    }
     form.reset();
+    authorsBox.innerHTML = '';
+    addAuthorRow();
     fileName.style.display = 'none';
     progress.value = 0;
 } else {
-    const msg = (body && body.error) ? body.error : 'Ошибка отправки.';
+    // problem+json: при нескольких ошибках полей показываем все
+    const msg = (body && Array.isArray(body.errors) && body.errors.length > 1)
+        ? body.errors.map(e => e.detail).join('\n')
+        : (body && body.detail) || 'Ошибка отправки.';
     showAlert(msg, 'error');
 }
 };
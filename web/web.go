@@ -0,0 +1,8 @@
+// Package web — шаблоны и статика сайта, встроенные в бинарник: для запуска
+// достаточно одного файла, рабочий каталог не важен.
+package web
+
+import "embed"
+
+//go:embed templates static
+var FS embed.FS